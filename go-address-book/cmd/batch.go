@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/editor"
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/script"
+	"github.com/rushi/address-book-cli/internal/storage"
+)
+
+// notInScripts are the commands a script cannot run, and why
+var notInScripts = map[string]string{
+	"run":  "scripts cannot run other scripts",
+	"tui":  "it needs a terminal",
+	"edit": "it needs a terminal",
+	"help": "it only prints help",
+}
+
+// bulkCommands are the commands that take --query and, without --yes, ask
+// before changing the matching contacts
+var bulkCommands = map[string]bool{"update": true, "delete": true, "tag": true}
+
+// runBatch runs the commands of a script against the address book and
+// saves the result once, so a failed script leaves the file untouched. It
+// returns the process exit code.
+func runBatch(store *storage.CSVStorage, addressBook *models.AddressBook, args []string) int {
+	cmd := &command{}
+	for _, c := range commands {
+		if c.name == "run" {
+			cmd = c
+		}
+	}
+	flags := newFlagSet("run")
+	dryRun := flags.Bool("dry-run", false, "run the script and print the changes it would make, without saving")
+	keepGoing := flags.Bool("continue-on-error", false, "skip failed commands and save the changes of the others")
+	positional, err := parseArgs(flags, args)
+	if errors.Is(err, flag.ErrHelp) {
+		printCommandUsage(os.Stdout, cmd, flags)
+		return exitOK
+	}
+	if err == nil && len(positional) > 1 {
+		err = fmt.Errorf("expected one script file, got %d", len(positional))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "address-book run: %v\n", err)
+		printCommandUsage(os.Stderr, cmd, flags)
+		return exitUsage
+	}
+
+	var input io.Reader = os.Stdin
+	if len(positional) == 1 && positional[0] != "-" {
+		file, err := os.Open(positional[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "address-book run: %v\n", err)
+			return exitError
+		}
+		defer file.Close()
+		input = file
+	}
+	// Check the whole script before running any of it
+	lines, err := script.Parse(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "address-book run: %v\n", err)
+		return exitError
+	}
+	for _, line := range lines {
+		if err := checkLine(line.Args); err != nil {
+			fmt.Fprintf(os.Stderr, "address-book run: line %d: %s: %v\n", line.Number, line.Args[0], err)
+			return exitError
+		}
+	}
+
+	before := make(map[string]*models.Contact)
+	for _, c := range addressBook.GetAllContacts() {
+		before[c.ID] = c
+	}
+	ran, failed := 0, 0
+	for _, line := range lines {
+		ran++
+		if err := runLine(addressBook, line.Args); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "address-book run: line %d: %s: %s\n", line.Number, line.Args[0], describeError(err))
+			if !*keepGoing {
+				break
+			}
+		}
+	}
+
+	changes := compareBooks(before, addressBook)
+	if *dryRun {
+		printChanges(os.Stdout, changes, addressBook.Schema())
+	}
+	fmt.Fprintf(os.Stderr, "Ran %d of %d commands: %d succeeded, %d failed. %s.\n",
+		ran, len(lines), ran-failed, failed, changes.summary())
+
+	switch {
+	case failed > 0 && !*keepGoing:
+		fmt.Fprintln(os.Stderr, "Nothing was saved.")
+		return exitError
+	case *dryRun:
+		fmt.Fprintln(os.Stderr, "Dry run: nothing was saved.")
+	case len(changes) > 0:
+		if err := store.Save(addressBook); err != nil {
+			fmt.Fprintf(os.Stderr, "address-book run: saving address book: %v\n", err)
+			return exitError
+		}
+	}
+	if failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// runLine runs one command of a script
+func runLine(addressBook *models.AddressBook, args []string) error {
+	name := args[0]
+	if reason, ok := notInScripts[name]; ok {
+		return fmt.Errorf("%s cannot be used in a script: %s", name, reason)
+	}
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	err := cmd.run(addressBook, newFlagSet(name), args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return errors.New("-h cannot be used in a script")
+	}
+	return err
+}
+
+// checkLine rejects a script line that would stop to ask for confirmation,
+// which a script cannot give: a bulk change with --query needs --yes
+func checkLine(args []string) error {
+	if !bulkCommands[args[0]] {
+		return nil
+	}
+	query, yes := false, false
+	for _, arg := range args[1:] {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch name {
+		case "query":
+			query = true
+		case "yes":
+			yes = !hasValue
+			if hasValue {
+				yes, _ = strconv.ParseBool(value)
+			}
+		}
+	}
+	if query && !yes {
+		return errors.New("--query needs --yes in a script, since a script cannot confirm the change")
+	}
+	return nil
+}
+
+// contactChange is a contact a script added, updated or deleted
+type contactChange struct {
+	before, after *models.Contact // before is nil for additions, after for deletions
+}
+
+type contactChanges []contactChange
+
+// compareBooks lists how the address book differs from an earlier copy of
+// its contacts, in creation order
+func compareBooks(before map[string]*models.Contact, addressBook *models.AddressBook) contactChanges {
+	var changes contactChanges
+	after := addressBook.GetAllContacts()
+	seen := make(map[string]bool, len(after))
+	for _, c := range after {
+		seen[c.ID] = true
+		old, existed := before[c.ID]
+		if !existed {
+			changes = append(changes, contactChange{after: c})
+		} else if old.Version != c.Version {
+			changes = append(changes, contactChange{before: old, after: c})
+		}
+	}
+	var deleted []string
+	for id := range before {
+		if !seen[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	slices.Sort(deleted)
+	for _, id := range deleted {
+		changes = append(changes, contactChange{before: before[id]})
+	}
+	return changes
+}
+
+func (changes contactChanges) summary() string {
+	var added, updated, deleted int
+	for _, change := range changes {
+		switch {
+		case change.before == nil:
+			added++
+		case change.after == nil:
+			deleted++
+		default:
+			updated++
+		}
+	}
+	return fmt.Sprintf("%d added, %d updated, %d deleted", added, updated, deleted)
+}
+
+// printChanges describes each change, with the fields an update changed
+func printChanges(w io.Writer, changes contactChanges, schema models.Schema) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	for _, change := range changes {
+		switch {
+		case change.before == nil:
+			fmt.Fprintf(w, "add %s %s\n", change.after.ID, fullName(change.after))
+		case change.after == nil:
+			fmt.Fprintf(w, "delete %s %s\n", change.before.ID, fullName(change.before))
+		default:
+			fmt.Fprintf(w, "update %s %s\n", change.after.ID, fullName(change.after))
+			for _, line := range editor.Diff(change.before, change.after, schema) {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+}
+
+func fullName(c *models.Contact) string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/format"
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/query"
+)
+
+// previewRows is how many contacts a bulk preview lists
+const previewRows = 10
+
+// bulkFlags let a command act on every contact matching a query instead of
+// on contacts named by ID
+type bulkFlags struct {
+	query *string
+	yes   *bool
+}
+
+func newBulkFlags(flags *flag.FlagSet) *bulkFlags {
+	return &bulkFlags{
+		query: flags.String("query", "", "act on every contact matching the search `QUERY` instead of on IDs"),
+		yes:   flags.Bool("yes", false, "skip the confirmation of --query"),
+	}
+}
+
+// targets returns the IDs to act on. With --query, the matching contacts
+// are previewed on stderr and, unless --yes was given, the user must
+// confirm; nil means there is nothing to do. Otherwise the positional
+// arguments are resolved as IDs.
+func (bf *bulkFlags) targets(ab *models.AddressBook, positional []string, verb string) ([]string, error) {
+	if *bf.query == "" {
+		if len(positional) == 0 {
+			return nil, usagef("missing contact ID or --query")
+		}
+		ids := make([]string, len(positional))
+		for i, input := range positional {
+			id, err := resolveID(ab, input)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = id
+		}
+		return ids, nil
+	}
+	if len(positional) > 0 {
+		return nil, usagef("give either contact IDs or --query, not both")
+	}
+
+	contacts, err := query.Search(ab, *bf.query)
+	if err != nil {
+		return nil, err
+	}
+	if len(contacts) == 0 {
+		fmt.Fprintln(os.Stderr, "No contacts match; nothing to do.")
+		return nil, nil
+	}
+	printPreview(os.Stderr, fmt.Sprintf("%s %s:", verb, plural(len(contacts), "contact")), contacts)
+	if !*bf.yes {
+		if !isTerminal(os.Stdin) {
+			return nil, fmt.Errorf("not confirmed: pass --yes to %s %d contacts without asking", strings.ToLower(verb), len(contacts))
+		}
+		fmt.Fprintf(os.Stderr, "%s %s? [y/N]: ", verb, plural(len(contacts), "contact"))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !confirmed(answer) {
+			fmt.Fprintln(os.Stderr, "Cancelled; nothing was changed.")
+			return nil, nil
+		}
+	}
+
+	ids := make([]string, len(contacts))
+	for i, c := range contacts {
+		ids[i] = c.ID
+	}
+	return ids, nil
+}
+
+// printPreview lists the first contacts a bulk change will affect under a
+// title
+func printPreview(w io.Writer, title string, contacts []*models.Contact) {
+	fmt.Fprintln(w, title)
+	shown := contacts[:min(len(contacts), previewRows)]
+	columns, _ := format.ParseColumns("id,name,email", nil)
+	format.WriteAll(w, format.Options{Format: "table", Columns: columns}, shown)
+	if more := len(contacts) - len(shown); more > 0 {
+		fmt.Fprintln(w, notices.N("preview.more", more))
+	}
+}
+
+// confirmed reports whether an answer to a yes/no question is yes
+func confirmed(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// plural formats a count with a noun, adding an s unless there is one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func runTag(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	add := flags.String("add", "", "comma-separated tags to add")
+	remove := flags.String("remove", "", "comma-separated tags to remove")
+	bulk := newBulkFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	addTags, removeTags := models.ParseTags(*add), models.ParseTags(*remove)
+	if len(addTags) == 0 && len(removeTags) == 0 {
+		return usagef("nothing to do; give --add or --remove")
+	}
+	ids, err := bulk.targets(ab, positional, "Tag")
+	if err != nil || ids == nil {
+		return err
+	}
+
+	changed, err := ab.UpdateAll(ids, func(c *models.Contact) error {
+		c.RemoveTags(removeTags...)
+		c.AddTags(addTags...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Retagged %s (%d already up to date).\n", plural(len(changed), "contact"), len(ids)-len(changed))
+	return nil
+}
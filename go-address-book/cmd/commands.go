@@ -0,0 +1,683 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/editor"
+	"github.com/rushi/address-book-cli/internal/format"
+	"github.com/rushi/address-book-cli/internal/generator"
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/query"
+	"github.com/rushi/address-book-cli/internal/storage"
+	"github.com/rushi/address-book-cli/internal/tui"
+)
+
+// Exit codes of the subcommands
+const (
+	exitOK    = 0
+	exitError = 1 // the command failed
+	exitUsage = 2 // the command line was wrong
+)
+
+// usageError is a mistake on the command line, as opposed to a failure
+// while carrying out the command
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// command is a non-interactive subcommand, so scripts, cron jobs and CI can
+// drive the address book without the menu
+type command struct {
+	name    string
+	args    string // argument synopsis, for help
+	summary string
+	saves   bool // the address book is saved when the command succeeds
+	run     func(ab *models.AddressBook, flags *flag.FlagSet, args []string) error
+}
+
+var commands = []*command{
+	{"add", "--first NAME --last NAME [fields]", "add a contact and print its ID", true, runAdd},
+	{"list", "[--sort FIELDS] [--limit N] [--offset N | --after CURSOR] [--output FORMAT] [--columns LIST]", "list contacts", false, runList},
+	{"search", "[--sort FIELDS] [--limit N] [--output FORMAT] [--columns LIST] QUERY", "search contacts with words, ~fuzzy words or a query", false, runSearch},
+	{"get", "[--output FORMAT] [--columns LIST] ID", "show one contact", false, runGet},
+	{"update", "ID | --query QUERY [--yes] [fields]", "change the given fields; an empty value clears a field", true, runUpdate},
+	{"edit", "ID", "edit a contact in $EDITOR and show the changes", true, runEdit},
+	{"delete", "ID... | --query QUERY [--yes]", "delete contacts", true, runDelete},
+	{"tag", "[--add TAGS] [--remove TAGS] ID... | --query QUERY [--yes]", "add or remove tags", true, runTag},
+	{"import", "[--dry-run] [--dialect google|outlook] FILE...", "add the contacts in vCard, Google or Outlook CSV files and print their IDs", true, runImport},
+	{"export", "[--format FORMAT] [--vcard-version V] [--query QUERY] [FILE]", "write contacts as vCards, or another format, to FILE or stdout", false, runExport},
+	{"generate", "[--count N]", "add random test contacts and print their IDs", true, runGenerate},
+	{"upcoming", "[--days N]", "list birthdays and other dates coming up", false, runUpcoming},
+	{"duplicates", "[--min-score S]", "list likely duplicate contacts", false, runDuplicates},
+	{"tui", "", "browse and edit contacts full-screen", true, runTUI},
+	{"run", "[--dry-run] [--continue-on-error] [FILE]", "run the commands in FILE, or stdin, as one transaction", true, nil},
+	{"help", "", "show this help", false, nil},
+}
+
+// runCommand runs the subcommand named by args[0] and returns the process
+// exit code. Results go to stdout and errors to stderr.
+func runCommand(store *storage.CSVStorage, addressBook *models.AddressBook, args []string) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	if name == "run" || name == "--batch" {
+		return runBatch(store, addressBook, args[1:])
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "address-book: unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	flags := newFlagSet(name)
+	err := cmd.run(addressBook, flags, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printCommandUsage(os.Stdout, cmd, flags)
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "address-book %s: %s\n", name, describeError(err))
+		var usage *usageError
+		if errors.As(err, &usage) {
+			printCommandUsage(os.Stderr, cmd, flags)
+			return exitUsage
+		}
+		var queryErr *query.Error
+		if errors.As(err, &queryErr) {
+			return exitUsage
+		}
+		return exitError
+	}
+
+	if cmd.saves {
+		if err := store.Save(addressBook); err != nil {
+			fmt.Fprintf(os.Stderr, "address-book %s: saving address book: %v\n", name, err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// lookupCommand returns the subcommand with the given name, or nil
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name && c.run != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// newFlagSet returns an empty flag set that reports errors instead of
+// printing them
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+	return flags
+}
+
+// describeError formats an error for stderr, pointing at the bad part of a query
+func describeError(err error) string {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		return fmt.Sprintf("invalid query at %v\n  %s", err, strings.ReplaceAll(queryErr.Context(), "\n", "\n  "))
+	}
+	return err.Error()
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: address-book [command] [flags] [arguments]")
+	fmt.Fprintln(w, "\nWithout a command, an interactive menu is shown.")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\n\"address-book --batch\" is short for \"address-book run\", reading the script from stdin.")
+	fmt.Fprintln(w, "Run \"address-book COMMAND -h\" for the flags of a command.")
+	fmt.Fprintln(w, "Exit status is 0 on success, 1 when a command fails and 2 for usage errors.")
+}
+
+func printCommandUsage(w io.Writer, cmd *command, flags *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: address-book %s %s\n", cmd.name, cmd.args)
+	fmt.Fprintf(w, "\n%s\n", cmd.summary)
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		flags.SetOutput(w)
+		flags.PrintDefaults()
+	}
+}
+
+// parseArgs parses flags that may come before, between or after the
+// positional arguments, which it returns
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// listFlag collects the values of a flag that may be repeated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// contactFlags are the flags add and update use to set contact fields
+type contactFlags struct {
+	flags  *flag.FlagSet
+	fields map[string]*string
+	dates  listFlag
+	custom listFlag
+}
+
+// contactFields are the flags for plain contact fields, in help order
+var contactFields = []struct{ name, usage string }{
+	{"first", "first name"},
+	{"last", "last name"},
+	{"email", "email address"},
+	{"phone", "phone number"},
+	{"address", "postal address"},
+	{"org", "organization"},
+	{"title", "job title"},
+	{"dept", "department"},
+	{"tags", "comma-separated tags, replacing the current ones"},
+	{"birthday", "birthday as YYYY-MM-DD or MM-DD"},
+	{"anniversary", "anniversary as YYYY-MM-DD or MM-DD"},
+}
+
+func newContactFlags(flags *flag.FlagSet) *contactFlags {
+	cf := &contactFlags{flags: flags, fields: make(map[string]*string)}
+	for _, f := range contactFields {
+		cf.fields[f.name] = flags.String(f.name, "", f.usage)
+	}
+	flags.Var(&cf.dates, "date", "other date as `label=YYYY-MM-DD`; repeatable, and an empty date removes the label")
+	flags.Var(&cf.custom, "field", "custom field as `name=value`; repeatable, and an empty value clears the field")
+	return cf
+}
+
+// apply copies the flags that were given onto contact, so a flag set to ""
+// clears its field and a missing flag leaves it alone
+func (cf *contactFlags) apply(contact *models.Contact, schema models.Schema) error {
+	var err error
+	cf.flags.Visit(func(f *flag.Flag) {
+		value, ok := cf.fields[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		switch f.Name {
+		case "first":
+			contact.FirstName = *value
+		case "last":
+			contact.LastName = *value
+		case "email":
+			contact.Email = *value
+		case "phone":
+			contact.Phone = *value
+		case "address":
+			contact.Address = *value
+		case "org":
+			contact.Organization = *value
+		case "title":
+			contact.Title = *value
+		case "dept":
+			contact.Department = *value
+		case "tags":
+			contact.Tags = models.ParseTags(*value)
+		case "birthday":
+			err = setDate(contact, models.LabelBirthday, *value)
+		case "anniversary":
+			err = setDate(contact, models.LabelAnniversary, *value)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range cf.dates {
+		label, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(label) == "" {
+			return usagef("--date %q must be written as label=YYYY-MM-DD", entry)
+		}
+		if err := setDate(contact, strings.TrimSpace(label), value); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range cf.custom {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return usagef("--field %q must be written as name=value", entry)
+		}
+		field, found := lookupCustom(schema, name)
+		if !found {
+			return usagef("unknown custom field %q", name)
+		}
+		contact.SetCustom(field.Name, value)
+	}
+	return nil
+}
+
+// lookupCustom finds a custom field by name, ignoring case
+func lookupCustom(schema models.Schema, name string) (models.FieldDef, bool) {
+	for _, field := range schema {
+		if strings.EqualFold(field.Name, strings.TrimSpace(name)) {
+			return field, true
+		}
+	}
+	return models.FieldDef{}, false
+}
+
+// setDate sets a labeled date, or removes it when value is empty
+func setDate(contact *models.Contact, label, value string) error {
+	if strings.TrimSpace(value) == "" {
+		contact.RemoveDate(label)
+		return nil
+	}
+	date, err := models.ParseImportantDate(label, value)
+	if err != nil {
+		return err
+	}
+	contact.SetDate(date)
+	return nil
+}
+
+// resolveID expands an ID prefix, listing the candidates when it is ambiguous
+func resolveID(ab *models.AddressBook, input string) (string, error) {
+	id, err := ab.ResolveID(input)
+	var ambiguous *models.AmbiguousIDError
+	if errors.As(err, &ambiguous) {
+		var b strings.Builder
+		b.WriteString(err.Error())
+		for _, match := range ambiguous.Matches {
+			fmt.Fprintf(&b, "\n  %s  %s", match, contactName(ab, match))
+		}
+		return "", errors.New(b.String())
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", input, err)
+	}
+	return id, nil
+}
+
+func runAdd(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	cf := newContactFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	contact := models.NewContact("", "", "", "", "")
+	if err := cf.apply(contact, ab.Schema()); err != nil {
+		return err
+	}
+	if contact.FirstName == "" && contact.LastName == "" {
+		return usagef("a contact needs --first or --last")
+	}
+	if err := ab.AddContact(contact); err != nil {
+		return err
+	}
+	fmt.Println(contact.ID)
+	return nil
+}
+
+// pageFlags are the sorting and paging flags of list and search
+type pageFlags struct {
+	sort   *string
+	limit  *int
+	offset *int
+	after  *string
+}
+
+func newPageFlags(flags *flag.FlagSet, defaultSort string) *pageFlags {
+	return &pageFlags{
+		sort:   flags.String("sort", defaultSort, "comma-separated sort `fields`, each prefixed with - for descending order"),
+		limit:  flags.Int("limit", 0, "show at most `N` contacts (0 for all)"),
+		offset: flags.Int("offset", 0, "skip the first `N` contacts"),
+		after:  flags.String("after", "", "continue after the page that printed this `cursor`"),
+	}
+}
+
+// options turns the flags into list options
+func (pf *pageFlags) options(ab *models.AddressBook) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		Locale: collationLocale(),
+		Offset: *pf.offset,
+		Limit:  *pf.limit,
+		After:  *pf.after,
+	}
+	if *pf.sort != "" {
+		keys, err := models.ParseSort(*pf.sort, ab.Schema())
+		if err != nil {
+			return opts, &usageError{msg: err.Error()}
+		}
+		opts.Sort = keys
+	}
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return opts, usagef("--offset and --limit must not be negative")
+	}
+	return opts, nil
+}
+
+// outputFlags choose how list, search and get print contacts
+type outputFlags struct {
+	output   string
+	columns  *string
+	template *string
+}
+
+func newOutputFlags(flags *flag.FlagSet) *outputFlags {
+	of := &outputFlags{}
+	usage := "output `format`: " + strings.Join(format.Formats, ", ")
+	flags.StringVar(&of.output, "output", "text", usage)
+	flags.StringVar(&of.output, "o", "text", "shorthand for --output")
+	of.columns = flags.String("columns", "", "comma-separated `columns` to show: "+strings.Join(format.ColumnNames(), ", ")+" and custom fields")
+	of.template = flags.String("template", "", "Go `template` for --output template, such as '{{.first}} <{{.email}}>'")
+	return of
+}
+
+// print writes contacts in the chosen format. Plain text without columns is
+// the detailed layout the menu uses.
+func (of *outputFlags) print(ab *models.AddressBook, contacts []*models.Contact) error {
+	if *of.template != "" && of.output == "text" {
+		of.output = "template"
+	}
+	opts := format.Options{Format: of.output, Template: *of.template, Schema: ab.Schema()}
+	if *of.columns != "" {
+		columns, err := format.ParseColumns(*of.columns, opts.Schema)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		opts.Columns = columns
+	}
+
+	if opts.Format == "text" && opts.Columns == nil {
+		for _, contact := range contacts {
+			printContact(ab, contact)
+		}
+		return nil
+	}
+	if err := format.WriteAll(os.Stdout, opts, contacts); err != nil {
+		if !slices.Contains(format.Formats, opts.Format) {
+			return &usageError{msg: err.Error()}
+		}
+		return err
+	}
+	return nil
+}
+
+// printPage prints a page of contacts, and on stderr how to get the next one
+func printPage(ab *models.AddressBook, page *models.Page, of *outputFlags) error {
+	if err := of.print(ab, page.Contacts); err != nil {
+		return err
+	}
+	if page.Next != "" {
+		fmt.Fprintf(os.Stderr, "Showing %d-%d of %d. For more, add --after %s\n",
+			page.Offset+1, page.Offset+len(page.Contacts), page.Total, page.Next)
+	}
+	return nil
+}
+
+func runList(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	pf := newPageFlags(flags, "last,first")
+	of := newOutputFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	opts, err := pf.options(ab)
+	if err != nil {
+		return err
+	}
+	page, err := ab.List(opts)
+	if err != nil {
+		return err
+	}
+	return printPage(ab, page, of)
+}
+
+func runSearch(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	pf := newPageFlags(flags, "")
+	of := newOutputFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("search needs a query")
+	}
+	opts, err := pf.options(ab)
+	if err != nil {
+		return err
+	}
+
+	contacts, err := query.Search(ab, strings.Join(positional, " "))
+	if err != nil {
+		return err
+	}
+	page, err := models.Paginate(contacts, opts, ab.Schema())
+	if err != nil {
+		return err
+	}
+	if page.Total == 0 {
+		fmt.Fprintln(os.Stderr, "No contacts found matching your search.")
+	}
+	return printPage(ab, page, of)
+}
+
+// oneID returns the single ID argument of a command
+func oneID(ab *models.AddressBook, positional []string) (string, error) {
+	switch len(positional) {
+	case 0:
+		return "", usagef("missing contact ID")
+	case 1:
+		return resolveID(ab, positional[0])
+	}
+	return "", usagef("expected one contact ID, got %d", len(positional))
+}
+
+func runGet(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	of := newOutputFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := oneID(ab, positional)
+	if err != nil {
+		return err
+	}
+	contact, err := ab.GetContact(id)
+	if err != nil {
+		return err
+	}
+	return of.print(ab, []*models.Contact{contact})
+}
+
+func runUpdate(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	cf := newContactFlags(flags)
+	bulk := newBulkFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	fields := 0
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "query" && f.Name != "yes" {
+			fields++
+		}
+	})
+	if fields == 0 {
+		return usagef("nothing to update; give the fields to change as flags")
+	}
+	if *bulk.query == "" && len(positional) > 1 {
+		return usagef("expected one contact ID, got %d; use --query to update several", len(positional))
+	}
+	ids, err := bulk.targets(ab, positional, "Update")
+	if err != nil || ids == nil {
+		return err
+	}
+
+	schema := ab.Schema()
+	changed, err := ab.UpdateAll(ids, func(c *models.Contact) error {
+		return cf.apply(c, schema)
+	})
+	if err != nil {
+		return err
+	}
+	if *bulk.query != "" {
+		fmt.Fprintf(os.Stderr, "Updated %s (%d already up to date).\n", plural(len(changed), "contact"), len(ids)-len(changed))
+	}
+	return nil
+}
+
+func runEdit(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := oneID(ab, positional)
+	if err != nil {
+		return err
+	}
+	changed, err := editor.Edit(ab, id, editor.Launch, os.Stdout)
+	if errors.Is(err, editor.ErrCancelled) {
+		fmt.Fprintln(os.Stderr, "Edit cancelled.")
+		return nil
+	}
+	if err == nil && !changed {
+		fmt.Fprintln(os.Stderr, "No changes.")
+	}
+	return err
+}
+
+func runDelete(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	bulk := newBulkFlags(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	// Resolve every ID first and delete in one batch, so a typo in the
+	// last ID doesn't leave the others half deleted
+	ids, err := bulk.targets(ab, positional, "Delete")
+	if err != nil || ids == nil {
+		return err
+	}
+	if err := ab.DeleteAll(ids); err != nil {
+		return err
+	}
+	if *bulk.query != "" {
+		fmt.Fprintf(os.Stderr, "Deleted %s.\n", plural(len(ids), "contact"))
+	}
+	return nil
+}
+
+func runGenerate(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	count := flags.Int("count", 10, "number of contacts to generate")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	if *count < 1 {
+		return usagef("--count must be at least 1")
+	}
+
+	contacts := generator.NewGenerator().GenerateContacts(*count)
+	ops := make([]models.Op, len(contacts))
+	for i, contact := range contacts {
+		ops[i] = models.AddOp(contact)
+	}
+	if err := ab.Apply(ops); err != nil {
+		return err
+	}
+	for _, contact := range contacts {
+		fmt.Println(contact.ID)
+	}
+	return nil
+}
+
+func runUpcoming(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	days := flags.Int("days", 30, "number of days to look ahead")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	if *days < 0 {
+		return usagef("--days must not be negative")
+	}
+	printUpcoming(ab, *days)
+	return nil
+}
+
+func runDuplicates(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	minScore := flags.Float64("min-score", models.DefaultDuplicateScore, "minimum confidence (0-1) to report")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	printDuplicates(ab, *minScore)
+	return nil
+}
+
+func runTUI(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	return tui.Run(ab, collationLocale(), os.Stdin, os.Stdout)
+}
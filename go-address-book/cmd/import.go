@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/csvimport"
+	"github.com/rushi/address-book-cli/internal/format"
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/query"
+	"github.com/rushi/address-book-cli/internal/vcard"
+)
+
+func runImport(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	dryRun := flags.Bool("dry-run", false, "show how columns are read and what would be imported without changing anything")
+	dialectName := flags.String("dialect", "", "CSV `layout`: "+strings.Join(csvimport.Names(), ", ")+"; detected from the header by default")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("missing file to import")
+	}
+	var dialect *csvimport.Dialect
+	if *dialectName != "" {
+		if dialect, err = csvimport.Lookup(*dialectName); err != nil {
+			return &usageError{msg: err.Error()}
+		}
+	}
+
+	var ops []models.Op
+	var added []*models.Contact
+	seen := make(map[string]bool)
+	skipped := 0
+	for _, file := range positional {
+		contacts, err := readImport(file, dialect, *dryRun)
+		if err != nil {
+			return err
+		}
+		for _, contact := range contacts {
+			if seen[contact.ID] {
+				fmt.Fprintf(os.Stderr, "%s: %s appears twice; skipped\n", file, contact.ID)
+				skipped++
+				continue
+			}
+			if _, err := ab.GetContact(contact.ID); err == nil {
+				fmt.Fprintf(os.Stderr, "%s: %s (%s) is already in the address book; skipped\n", file, fullName(contact), contact.ID)
+				skipped++
+				continue
+			}
+			seen[contact.ID] = true
+			checkCustom(file, contact, ab.Schema())
+			ops = append(ops, models.AddOp(contact))
+			added = append(added, contact)
+		}
+	}
+
+	if *dryRun {
+		printPreview(os.Stderr, fmt.Sprintf("Would import %s:", plural(len(added), "contact")), added)
+		return nil
+	}
+	if err := ab.Apply(ops); err != nil {
+		return err
+	}
+	for _, contact := range added {
+		fmt.Println(contact.ID)
+	}
+	fmt.Fprintf(os.Stderr, "Imported %s", plural(len(added), "contact"))
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, " (%d skipped)", skipped)
+	}
+	fmt.Fprintln(os.Stderr, ".")
+	return nil
+}
+
+// readImport reads the contacts in a vCard or CSV file, reporting on stderr
+// what could not be read. A preview also shows how CSV columns are read.
+func readImport(file string, dialect *csvimport.Dialect, preview bool) ([]*models.Contact, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if !isVCard(data) {
+		return readCSVImport(file, data, dialect, preview)
+	}
+	result, err := vcard.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, warning)
+	}
+	if len(result.Ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: ignored properties with no matching field: %s\n", file, strings.Join(result.Ignored, ", "))
+	}
+	return result.Contacts, nil
+}
+
+// readCSVImport reads the contacts in a CSV file exported by another
+// address book
+func readCSVImport(file string, data []byte, dialect *csvimport.Dialect, preview bool) ([]*models.Contact, error) {
+	result, err := csvimport.Read(bytes.NewReader(data), dialect)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if preview {
+		printMapping(os.Stderr, file, result)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, warning)
+	}
+	if used, _ := result.Unmapped(); len(used) > 0 {
+		names := make([]string, len(used))
+		for i, col := range used {
+			names[i] = fmt.Sprintf("%s (%s)", col.Header, plural(col.Values, "row"))
+		}
+		fmt.Fprintf(os.Stderr, "%s: unmapped columns with values: %s\n", file, strings.Join(names, ", "))
+	}
+	return result.Contacts, nil
+}
+
+// printMapping lists the field each column of a CSV file is read into and
+// how many rows have a value in it. Columns that are empty and unread are
+// only counted.
+func printMapping(w io.Writer, file string, result *csvimport.Result) {
+	fmt.Fprintf(w, "%s: %s CSV, %s\n", file, result.Dialect.Title, plural(len(result.Contacts), "contact"))
+	width := len("COLUMN")
+	for _, col := range result.Columns {
+		width = max(width, len(col.Header))
+	}
+	fmt.Fprintf(w, "  %-*s  %-14s  %s\n", width, "COLUMN", "FIELD", "ROWS")
+	for _, col := range result.Columns {
+		if col.Field == "" && col.Values == 0 {
+			continue
+		}
+		field := col.Field
+		if field == "" {
+			field = "(unmapped)"
+		}
+		fmt.Fprintf(w, "  %-*s  %-14s  %d\n", width, col.Header, field, col.Values)
+	}
+	if _, empty := result.Unmapped(); len(empty) > 0 {
+		fmt.Fprintf(w, "  and %s that nothing reads\n", plural(len(empty), "empty column"))
+	}
+}
+
+// isVCard reports whether a file starts like a vCard, allowing for a
+// byte-order mark, UTF-16 and blank lines
+func isVCard(data []byte) bool {
+	head := data[:min(len(data), 512)]
+	head = bytes.ReplaceAll(head, []byte{0}, nil) // UTF-16 ASCII
+	head = bytes.TrimLeft(head, "\xef\xbb\xbf\xff\xfe \t\r\n")
+	return len(head) >= 11 && strings.EqualFold(string(head[:11]), "BEGIN:VCARD")
+}
+
+// checkCustom drops the custom values of an imported contact that the
+// schema has no field for or that the field rejects, and gives the rest the
+// field's spelling
+func checkCustom(file string, contact *models.Contact, schema models.Schema) {
+	custom := contact.Custom
+	contact.Custom = nil
+	for name, value := range custom {
+		field, ok := lookupCustom(schema, name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s: %s: no custom field %q; value %q dropped\n", file, fullName(contact), name, value)
+			continue
+		}
+		if err := field.Check(value); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s: %v; value dropped\n", file, fullName(contact), err)
+			continue
+		}
+		contact.SetCustom(field.Name, value)
+	}
+}
+
+func runExport(ab *models.AddressBook, flags *flag.FlagSet, args []string) error {
+	output := flags.String("format", "vcf", "output `format`: "+strings.Join(format.Formats, ", "))
+	version := flags.String("vcard-version", vcard.DefaultVersion, "vCard `version` for --format vcf: "+strings.Join(vcard.Versions, ", "))
+	search := flags.String("query", "", "export only the contacts matching the search `QUERY`")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return usagef("unexpected argument %q", positional[1])
+	}
+
+	contacts := ab.GetAllContacts()
+	if *search != "" {
+		if contacts, err = query.Search(ab, *search); err != nil {
+			return err
+		}
+	}
+	opts := format.Options{Format: *output, Schema: ab.Schema(), VCard: *version}
+	if _, err := format.New(io.Discard, opts); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+
+	var w io.Writer = os.Stdout
+	if len(positional) == 1 && positional[0] != "-" {
+		f, err := os.Create(positional[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := format.WriteAll(w, opts, contacts); err != nil {
+		return err
+	}
+	if f, ok := w.(*os.File); ok && f != os.Stdout {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %s to %s.\n", plural(len(contacts), "contact"), f.Name())
+	}
+	return nil
+}
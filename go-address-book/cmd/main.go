@@ -1,55 +1,92 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 
 	"github.com/rushi/address-book-cli/internal/config"
+	"github.com/rushi/address-book-cli/internal/csvimport"
+	"github.com/rushi/address-book-cli/internal/editor"
 	"github.com/rushi/address-book-cli/internal/generator"
+	"github.com/rushi/address-book-cli/internal/i18n"
 	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/query"
+	"github.com/rushi/address-book-cli/internal/readline"
 	"github.com/rushi/address-book-cli/internal/storage"
 )
 
+// tr translates the interactive menu. Commands keep English, so their
+// output reads the same everywhere.
+var tr = i18n.New("en")
+
+// notices translates what commands tell the user on stderr alongside their
+// output, such as the rest of a bulk preview, into the configured locale
+var notices = i18n.New("en")
+
 func main() {
 	cfg, err := config.LoadConfig("config.json")
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
 		os.Exit(1)
 	}
 
 	store := storage.NewCSVStorage(cfg.CSVPath)
+	store.SetSchema(cfg.CustomFields)
 
 	addressBook, err := store.Load()
 	if err != nil {
-		fmt.Printf("Error loading address book: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading address book: %v\n", err)
+		var missingID *storage.MissingIDError
+		if errors.As(err, &missingID) {
+			if dialect := csvimport.Detect(missingID.Header); dialect != nil {
+				fmt.Fprintf(os.Stderr, "%s looks like a %s export; set csvPath to a new file and read this one with the import command\n", cfg.CSVPath, dialect.Title)
+			}
+		}
 		os.Exit(1)
 	}
+	for _, name := range store.UnknownColumns() {
+		fmt.Fprintf(os.Stderr, "Warning: column %q of %s is not a custom field in the config; its values are kept in the file but not shown, searched or edited\n", name, cfg.CSVPath)
+	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	notices = i18n.New(cfg.Locale)
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(store, addressBook, os.Args[1:]))
+	}
+
+	tr = notices
+	scanner := readline.New(os.Stdin, os.Stdout)
+	if err := scanner.LoadHistory(cfg.HistoryFile()); err != nil {
+		fmt.Fprintln(os.Stderr, tr.T("history.error", err))
+	}
+	scanner.Complete = completions(addressBook)
 
 	for {
-		fmt.Println("\nAddress Book CLI")
-		fmt.Println("1. Add Contact")
-		fmt.Println("2. List Contacts")
-		fmt.Println("3. Search Contacts")
-		fmt.Println("4. Update Contact")
-		fmt.Println("5. Delete Contact")
-		fmt.Println("6. Generate Test Data")
-		fmt.Println("7. Exit")
-		fmt.Print("Enter your choice (1-7): ")
-
-		if !scanner.Scan() {
+		fmt.Println("\n" + tr.T("menu.title"))
+		for i, item := range menu {
+			fmt.Printf("%d. %s\n", i+1, tr.T(item))
+		}
+		if !scanner.Prompt(tr.T("menu.prompt", len(menu))) {
 			break
 		}
-		choice := scanner.Text()
+		choice := menuChoice(scanner.Text())
 
 		switch choice {
 		case "1":
 			addContact(scanner, addressBook)
 		case "2":
-			listContacts(addressBook)
+			listContacts(scanner, addressBook)
 		case "3":
 			searchContacts(scanner, addressBook)
 		case "4":
@@ -58,162 +95,999 @@ func main() {
 			deleteContact(scanner, addressBook)
 		case "6":
 			generateTestData(addressBook)
-		case "7":
+		case "8":
+			listCompanies(addressBook)
+		case "9":
+			listCompanyContacts(scanner, addressBook)
+		case "10":
+			upcomingEvents(scanner, addressBook)
+		case "11":
+			linkContacts(scanner, addressBook)
+		case "12":
+			unlinkContacts(scanner, addressBook)
+		case "13":
+			showReports(scanner, addressBook)
+		case "14":
+			addNote(scanner, addressBook)
+		case "15":
+			listNotes(scanner, addressBook)
+		case "16":
+			findStaleContacts(scanner, addressBook)
+		case "17":
+			printDuplicates(addressBook, models.DefaultDuplicateScore)
+		case "18":
+			mergeContacts(scanner, addressBook)
+		case "19":
+			editContact(scanner, addressBook)
+		case "20":
+			bulkChange(scanner, addressBook)
+		case exitChoice, "q", "Q":
 			if err := store.Save(addressBook); err != nil {
-				fmt.Printf("Error saving address book: %v\n", err)
+				fmt.Println(tr.T("save.error", err))
 				os.Exit(1)
 			}
-			fmt.Println("Goodbye!")
+			fmt.Println(tr.T("menu.goodbye"))
 			return
 		default:
-			fmt.Println("Invalid choice. Please try again.")
+			fmt.Println(tr.T("menu.invalid"))
+		}
+	}
+}
+
+// menu lists the message keys of the interactive menu's items; a choice is
+// the item's number or its name. Exit keeps the number it had in the first
+// menu and new items go at the end, so the numbers scripts pipe in keep
+// their meaning.
+var menu = []string{
+	"menu.add",
+	"menu.list",
+	"menu.search",
+	"menu.update",
+	"menu.delete",
+	"menu.generate",
+	"menu.exit",
+	"menu.companies",
+	"menu.company",
+	"menu.upcoming",
+	"menu.link",
+	"menu.unlink",
+	"menu.reports",
+	"menu.note",
+	"menu.notes",
+	"menu.stale",
+	"menu.duplicates",
+	"menu.merge",
+	"menu.edit",
+	"menu.bulk",
+}
+
+// exitChoice is the number of the Exit item, which saves and leaves the
+// menu; "q" works too
+const exitChoice = "7"
+
+// menuChoice turns a menu item's name, as completed with Tab, into its
+// number. Anything else is returned as typed.
+func menuChoice(input string) string {
+	input = strings.TrimSpace(input)
+	for i, item := range menu {
+		if strings.EqualFold(input, tr.T(item)) {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	return input
+}
+
+// completions offers menu items, contact IDs and names, companies and
+// tags for Tab completion
+func completions(addressBook *models.AddressBook) readline.Completer {
+	return func() []string {
+		var words []string
+		for _, item := range menu {
+			words = append(words, tr.T(item))
+		}
+		for _, c := range addressBook.GetAllContacts() {
+			words = append(words, c.ID)
+			for _, word := range []string{tr.Name(c.FirstName, c.LastName), c.LastName, c.Organization} {
+				if word != "" {
+					words = append(words, word)
+				}
+			}
+			words = append(words, c.Tags...)
 		}
+		return words
 	}
 }
 
-func addContact(scanner *bufio.Scanner, addressBook *models.AddressBook) {
-	fmt.Print("Enter first name: ")
-	scanner.Scan()
+func addContact(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("add.first"))
 	firstName := scanner.Text()
 
-	fmt.Print("Enter last name: ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("add.last"))
 	lastName := scanner.Text()
 
-	fmt.Print("Enter email: ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("add.email"))
 	email := scanner.Text()
 
-	fmt.Print("Enter phone: ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("add.phone"))
 	phone := scanner.Text()
 
-	fmt.Print("Enter address: ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("add.address"))
 	address := scanner.Text()
 
+	scanner.Prompt(tr.T("add.org"))
+	organization := scanner.Text()
+
+	scanner.Prompt(tr.T("add.title"))
+	title := scanner.Text()
+
+	scanner.Prompt(tr.T("add.dept"))
+	department := scanner.Text()
+
+	scanner.Prompt(tr.T("add.tags"))
+	tags := models.ParseTags(scanner.Text())
+
 	contact := models.NewContact(firstName, lastName, email, phone, address)
+	contact.Organization = organization
+	contact.Title = title
+	contact.Department = department
+	contact.Tags = tags
+	if !readDates(scanner, contact, false) {
+		return
+	}
+
+	for _, field := range addressBook.Schema() {
+		scanner.Prompt(tr.T("add.custom", field.Name, field.Hint()))
+		contact.SetCustom(field.Name, scanner.Text())
+	}
+
 	if err := addressBook.AddContact(contact); err != nil {
-		fmt.Printf("Error adding contact: %v\n", err)
+		fmt.Println(tr.T("add.error", err))
 		return
 	}
 
-	fmt.Println("Contact added successfully!")
+	fmt.Println(tr.T("add.done"))
 }
 
-func listContacts(addressBook *models.AddressBook) {
+func listContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
 	contacts := addressBook.GetAllContacts()
 	if len(contacts) == 0 {
-		fmt.Println("No contacts found.")
+		fmt.Println(tr.T("list.none"))
 		return
 	}
 
-	fmt.Println("\nContacts:")
-	for _, contact := range contacts {
-		printContact(contact)
+	keys, ok := readSort(scanner, addressBook, tr.T("sort.name"))
+	if !ok {
+		return
+	}
+	if keys == nil {
+		keys = models.DefaultSort
 	}
+	if err := models.SortContacts(contacts, keys, collationLocale(), addressBook.Schema()); err != nil {
+		fmt.Println(tr.T("sort.error", err))
+		return
+	}
+
+	fmt.Println("\n" + tr.T("list.title"))
+	pageContacts(scanner, addressBook, contacts)
 }
 
-func searchContacts(scanner *bufio.Scanner, addressBook *models.AddressBook) {
-	fmt.Print("Enter search query: ")
-	scanner.Scan()
-	query := scanner.Text()
+func searchContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	fmt.Println(tr.T("search.help"))
+	scanner.Prompt(tr.T("search.prompt"))
 
-	contacts := addressBook.SearchContacts(query)
+	contacts, err := query.Search(addressBook, scanner.Text())
+	if err != nil {
+		printQueryError(err)
+		return
+	}
 	if len(contacts) == 0 {
-		fmt.Println("No contacts found matching your search.")
+		fmt.Println(tr.T("search.none"))
+		if !strings.HasPrefix(strings.TrimSpace(scanner.Text()), "~") {
+			fmt.Println(tr.T("search.fuzzy"))
+		}
 		return
 	}
 
-	fmt.Println("\nSearch Results:")
-	for _, contact := range contacts {
-		printContact(contact)
+	keys, ok := readSort(scanner, addressBook, tr.T("sort.relevance"))
+	if !ok {
+		return
+	}
+	if keys != nil {
+		if err := models.SortContacts(contacts, keys, collationLocale(), addressBook.Schema()); err != nil {
+			fmt.Println(tr.T("sort.error", err))
+			return
+		}
+	}
+
+	fmt.Println("\n" + tr.T("search.title"))
+	pageContacts(scanner, addressBook, contacts)
+}
+
+// readSort asks for a sort order such as "last,first" or "-created". It
+// returns nil keys when the user keeps the default order, described by
+// defaultOrder.
+func readSort(scanner *readline.Reader, addressBook *models.AddressBook, defaultOrder string) ([]models.SortKey, bool) {
+	scanner.Prompt(tr.T("sort.prompt", defaultOrder))
+	text := strings.TrimSpace(scanner.Text())
+	if text == "" {
+		return nil, true
+	}
+	keys, err := models.ParseSort(text, addressBook.Schema())
+	if err != nil {
+		fmt.Println(tr.T("sort.invalid", err))
+		return nil, false
+	}
+	return keys, true
+}
+
+// pageSize is how many contacts the pager shows at a time
+const pageSize = 10
+
+// pageContacts prints contacts a page at a time, waiting for Enter between
+// pages. When input is not a terminal everything is printed at once, so
+// scripted input is never mistaken for a pager answer.
+func pageContacts(scanner *readline.Reader, addressBook *models.AddressBook, contacts []*models.Contact) {
+	if !isTerminal(os.Stdin) {
+		for _, contact := range contacts {
+			printContact(addressBook, contact)
+		}
+		return
+	}
+
+	opts := models.ListOptions{Limit: pageSize}
+	for {
+		page, err := models.Paginate(contacts, opts, nil)
+		if err != nil {
+			fmt.Println(tr.T("page.error", err))
+			return
+		}
+		for _, contact := range page.Contacts {
+			printContact(addressBook, contact)
+		}
+		if page.Next == "" {
+			return
+		}
+		more := "\n" + tr.T("page.more", page.Offset+1, page.Offset+len(page.Contacts), page.Total)
+		if !scanner.Prompt(more) || strings.EqualFold(strings.TrimSpace(scanner.Text()), "q") {
+			return
+		}
+		opts.After = page.Next
 	}
 }
 
-func updateContact(scanner *bufio.Scanner, addressBook *models.AddressBook) {
-	fmt.Print("Enter contact ID to update: ")
-	scanner.Scan()
-	id := scanner.Text()
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// collationLocale returns the locale names are sorted for, taken from the
+// environment's LC_COLLATE
+func collationLocale() string {
+	return i18n.EnvLocale("LC_COLLATE")
+}
+
+// printQueryError reports a search error, pointing at the bad part of a query
+func printQueryError(err error) {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		fmt.Println(tr.T("search.invalid", err, strings.ReplaceAll(queryErr.Context(), "\n", "\n  ")))
+		return
+	}
+	fmt.Println(tr.T("search.error", err))
+}
+
+func updateContact(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("update.id"))
+	if !ok {
+		return
+	}
 
 	contact, err := addressBook.GetContact(id)
 	if err != nil {
-		fmt.Printf("Error finding contact: %v\n", err)
+		fmt.Println(tr.T("find.error", err))
 		return
 	}
 
-	fmt.Printf("Current contact: %s %s\n", contact.FirstName, contact.LastName)
-	fmt.Print("Enter new first name (or press Enter to keep current): ")
-	scanner.Scan()
+	fmt.Println(tr.T("update.current", tr.Name(contact.FirstName, contact.LastName)))
+	fmt.Println(tr.T("update.clear", tr.T("menu.edit")))
+	scanner.Prompt(tr.T("update.first"))
 	if firstName := scanner.Text(); firstName != "" {
 		contact.FirstName = firstName
 	}
 
-	fmt.Print("Enter new last name (or press Enter to keep current): ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("update.last"))
 	if lastName := scanner.Text(); lastName != "" {
 		contact.LastName = lastName
 	}
 
-	fmt.Print("Enter new email (or press Enter to keep current): ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("update.email"))
 	if email := scanner.Text(); email != "" {
 		contact.Email = email
 	}
 
-	fmt.Print("Enter new phone (or press Enter to keep current): ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("update.phone"))
 	if phone := scanner.Text(); phone != "" {
 		contact.Phone = phone
 	}
 
-	fmt.Print("Enter new address (or press Enter to keep current): ")
-	scanner.Scan()
+	scanner.Prompt(tr.T("update.address"))
 	if address := scanner.Text(); address != "" {
 		contact.Address = address
 	}
 
+	scanner.Prompt(tr.T("update.org"))
+	if organization := scanner.Text(); organization != "" {
+		contact.Organization = organization
+	}
+
+	scanner.Prompt(tr.T("update.title"))
+	if title := scanner.Text(); title != "" {
+		contact.Title = title
+	}
+
+	scanner.Prompt(tr.T("update.dept"))
+	if department := scanner.Text(); department != "" {
+		contact.Department = department
+	}
+
+	scanner.Prompt(tr.T("update.tags"))
+	if tags := scanner.Text(); tags != "" {
+		contact.Tags = models.ParseTags(tags)
+	}
+
+	if !readDates(scanner, contact, true) {
+		return
+	}
+
+	for _, field := range addressBook.Schema() {
+		scanner.Prompt(tr.T("update.custom", field.Name, field.Hint()))
+		if value := scanner.Text(); value != "" {
+			contact.SetCustom(field.Name, value)
+		}
+	}
+
 	if err := addressBook.UpdateContact(contact); err != nil {
-		fmt.Printf("Error updating contact: %v\n", err)
+		fmt.Println(tr.T("update.error", err))
 		return
 	}
 
-	fmt.Println("Contact updated successfully!")
+	fmt.Println(tr.T("update.done"))
 }
 
-func deleteContact(scanner *bufio.Scanner, addressBook *models.AddressBook) {
-	fmt.Print("Enter contact ID to delete: ")
-	scanner.Scan()
-	id := scanner.Text()
+// editContact opens the contact in $EDITOR, where any field can be changed
+// or cleared
+func editContact(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("edit.id"))
+	if !ok {
+		return
+	}
+
+	changed, err := editor.Edit(addressBook, id, editor.Launch, os.Stdout)
+	switch {
+	case errors.Is(err, editor.ErrCancelled):
+		fmt.Println(tr.T("edit.cancelled"))
+	case err != nil:
+		fmt.Println(tr.T("edit.error", err))
+	case !changed:
+		fmt.Println(tr.T("edit.unchanged"))
+	default:
+		fmt.Println(tr.T("update.done"))
+	}
+}
+
+func deleteContact(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("delete.id"))
+	if !ok {
+		return
+	}
+
+	scanner.Prompt(tr.T("delete.confirm", contactName(addressBook, id), id))
+	if !tr.Yes(scanner.Text()) {
+		fmt.Println(tr.T("delete.cancelled"))
+		return
+	}
 
 	if err := addressBook.DeleteContact(id); err != nil {
-		fmt.Printf("Error deleting contact: %v\n", err)
+		fmt.Println(tr.T("delete.error", err))
+		return
+	}
+
+	fmt.Println(tr.T("delete.done"))
+}
+
+// bulkConfirm holds the message keys that ask to confirm each bulk action
+var bulkConfirm = map[string]string{
+	"update": "bulk.confirm.update",
+	"delete": "bulk.confirm.delete",
+	"tag":    "bulk.confirm.tag",
+	"untag":  "bulk.confirm.untag",
+}
+
+// bulkChange updates, deletes or retags every contact matching a search,
+// after showing them and asking for confirmation. All changes are made in
+// one transaction.
+func bulkChange(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("bulk.search"))
+	contacts, err := query.Search(addressBook, scanner.Text())
+	if err != nil {
+		printQueryError(err)
+		return
+	}
+	if len(contacts) == 0 {
+		fmt.Println(tr.T("bulk.none"))
+		return
+	}
+	printPreview(os.Stdout, tr.N("bulk.matched", len(contacts)), contacts)
+
+	schema := addressBook.Schema()
+	var edit func(c *models.Contact) error
+	scanner.Prompt(tr.T("bulk.action"))
+	action := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	switch action {
+	case "update":
+		scanner.Prompt(tr.T("bulk.field"))
+		name := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		scanner.Prompt(tr.T("bulk.value"))
+		value := scanner.Text()
+
+		cf := newContactFlags(newFlagSet("update"))
+		if _, ok := cf.fields[name]; ok {
+			cf.flags.Set(name, value)
+		} else if field, found := lookupCustom(schema, name); found {
+			cf.custom = listFlag{field.Name + "=" + value}
+		} else {
+			fmt.Println(tr.T("bulk.unknownField", name))
+			return
+		}
+		edit = func(c *models.Contact) error { return cf.apply(c, schema) }
+	case "tag", "untag":
+		scanner.Prompt(tr.T("add.tags"))
+		tags := models.ParseTags(scanner.Text())
+		if len(tags) == 0 {
+			fmt.Println(tr.T("bulk.noTags"))
+			return
+		}
+		edit = func(c *models.Contact) error {
+			if action == "tag" {
+				c.AddTags(tags...)
+			} else {
+				c.RemoveTags(tags...)
+			}
+			return nil
+		}
+	case "delete":
+	default:
+		fmt.Println(tr.T("bulk.invalidAction"))
+		return
+	}
+
+	scanner.Prompt(tr.N(bulkConfirm[action], len(contacts)))
+	if !tr.Yes(scanner.Text()) {
+		fmt.Println(tr.T("bulk.cancelled"))
 		return
 	}
+	ids := make([]string, len(contacts))
+	for i, c := range contacts {
+		ids[i] = c.ID
+	}
 
-	fmt.Println("Contact deleted successfully!")
+	if action == "delete" {
+		if err := addressBook.DeleteAll(ids); err != nil {
+			fmt.Println(tr.T("bulk.deleteError", err))
+			return
+		}
+		fmt.Println(tr.N("bulk.deleted", len(ids)))
+		return
+	}
+	changed, err := addressBook.UpdateAll(ids, edit)
+	if err != nil {
+		fmt.Println(tr.T("bulk.updateError", err))
+		return
+	}
+	fmt.Println(tr.N("bulk.updated", len(changed), len(ids)-len(changed)))
 }
 
 func generateTestData(addressBook *models.AddressBook) {
 	gen := generator.NewGenerator()
 	contacts := gen.GenerateContacts(10)
 
+	ops := make([]models.Op, len(contacts))
+	for i, contact := range contacts {
+		ops[i] = models.AddOp(contact)
+	}
+	if err := addressBook.Apply(ops); err != nil {
+		fmt.Println(tr.T("generate.error", err))
+		return
+	}
+
+	fmt.Println(tr.N("generate.done", len(contacts)))
+}
+
+// readDates prompts for a birthday, an anniversary and other labeled dates.
+// When updating, an empty answer keeps the current value.
+func readDates(scanner *readline.Reader, contact *models.Contact, updating bool) bool {
+	datePrompt, datesPrompt := "dates.yearly", "dates.other"
+	if updating {
+		datePrompt, datesPrompt = "dates.yearlyKeep", "dates.otherKeep"
+	}
+
+	for _, label := range []string{models.LabelBirthday, models.LabelAnniversary} {
+		scanner.Prompt(tr.T(datePrompt, dateLabel(label)))
+		value := scanner.Text()
+		if value == "" {
+			continue
+		}
+		date, err := models.ParseImportantDate(label, value)
+		if err != nil {
+			fmt.Println(tr.T("dates.error", err))
+			return false
+		}
+		contact.SetDate(date)
+	}
+
+	scanner.Prompt(tr.T(datesPrompt))
+	dates, err := models.ParseDates(scanner.Text())
+	if err != nil {
+		fmt.Println(tr.T("dates.error", err))
+		return false
+	}
+	for _, date := range dates {
+		contact.SetDate(date)
+	}
+	return true
+}
+
+// dateLabel translates the label of a birthday or anniversary; other labels
+// are the user's own and shown as entered
+func dateLabel(label string) string {
+	if key := "label." + label; tr.Has(key) {
+		return tr.T(key)
+	}
+	return label
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// formatDate formats a yearly date in the locale's style, without the year
+// when it is not known
+func formatDate(date models.ImportantDate) string {
+	if date.Year == 0 {
+		return tr.MonthDay(time.Date(2000, date.Month, date.Day, 0, 0, 0, 0, time.UTC))
+	}
+	return tr.Date(time.Date(date.Year, date.Month, date.Day, 0, 0, 0, 0, time.UTC))
+}
+
+func upcomingEvents(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("upcoming.days"))
+	days := 30
+	if text := scanner.Text(); text != "" {
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 {
+			fmt.Println(tr.T("days.invalid"))
+			return
+		}
+		days = n
+	}
+
+	printUpcoming(addressBook, days)
+}
+
+func printUpcoming(addressBook *models.AddressBook, days int) {
+	events := addressBook.Upcoming(time.Now(), days)
+	if len(events) == 0 {
+		fmt.Println(tr.N("upcoming.none", days))
+		return
+	}
+
+	fmt.Println("\n" + tr.N("upcoming.title", days))
+	for _, event := range events {
+		fmt.Printf("%s  %s: %s", tr.ShortDate(event.On), tr.Name(event.Contact.FirstName, event.Contact.LastName), dateLabel(event.Date.Label))
+		if years, ok := event.Years(); ok {
+			if event.Date.Label == models.LabelBirthday {
+				fmt.Print(" " + tr.N("upcoming.turns", years))
+			} else {
+				fmt.Print(" " + tr.N("upcoming.years", years))
+			}
+		}
+		fmt.Println()
+	}
+}
+
+func linkContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	fromID, rel, toID, ok := readLink(scanner, addressBook)
+	if !ok {
+		return
+	}
+
+	if err := addressBook.AddLink(fromID, rel, toID); err != nil {
+		fmt.Println(tr.T("link.error", err))
+		return
+	}
+
+	fmt.Println(tr.T("link.done"))
+}
+
+func unlinkContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	fromID, rel, toID, ok := readLink(scanner, addressBook)
+	if !ok {
+		return
+	}
+
+	if err := addressBook.RemoveLink(fromID, rel, toID); err != nil {
+		fmt.Println(tr.T("unlink.error", err))
+		return
+	}
+
+	fmt.Println(tr.T("unlink.done"))
+}
+
+// readLink prompts for the two contacts and the relation between them
+func readLink(scanner *readline.Reader, addressBook *models.AddressBook) (string, models.RelationType, string, bool) {
+	fromID, ok := readID(scanner, addressBook, tr.T("contact.id"))
+	if !ok {
+		return "", "", "", false
+	}
+
+	names := make([]string, len(models.RelationTypes))
+	for i, rel := range models.RelationTypes {
+		names[i] = string(rel)
+	}
+	scanner.Prompt(tr.T("link.relation", strings.Join(names, "/")))
+	rel, err := models.ParseRelationType(scanner.Text())
+	if err != nil {
+		fmt.Println(tr.T("link.relationError", err))
+		return "", "", "", false
+	}
+
+	toID, ok := readID(scanner, addressBook, tr.T("link.to", strings.ToLower(relationLabel(rel, false))))
+	if !ok {
+		return "", "", "", false
+	}
+
+	return fromID, rel, toID, true
+}
+
+// relationLabel translates what a relation makes the linked contact, or
+// with inverse what it makes the link's owner
+func relationLabel(rel models.RelationType, inverse bool) string {
+	key, label := "relation."+string(rel), rel.Label()
+	if inverse {
+		key, label = key+".inverse", rel.InverseLabel()
+	}
+	if tr.Has(key) {
+		return tr.T(key)
+	}
+	return label
+}
+
+func showReports(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("reports.id"))
+	if !ok {
+		return
+	}
+
+	reports, err := addressBook.Reports(id)
+	if err != nil {
+		fmt.Println(tr.T("reports.error", err))
+		return
+	}
+	if len(reports) == 0 {
+		fmt.Println(tr.T("reports.none"))
+		return
+	}
+
+	fmt.Println("\n" + tr.T("reports.title", contactName(addressBook, id)))
+	for _, report := range reports {
+		contact := report.Contact
+		fmt.Printf("%s%s (%s)", strings.Repeat("  ", report.Depth-1), tr.Name(contact.FirstName, contact.LastName), contact.ID)
+		if contact.Title != "" {
+			fmt.Printf(", %s", contact.Title)
+		}
+		fmt.Println()
+	}
+}
+
+// readID prompts for a contact ID, accepting any unambiguous prefix
+func readID(scanner *readline.Reader, addressBook *models.AddressBook, prompt string) (string, bool) {
+	scanner.Prompt(prompt)
+
+	id, err := addressBook.ResolveID(scanner.Text())
+	if err != nil {
+		fmt.Println(tr.T("find.error", err))
+		var ambiguous *models.AmbiguousIDError
+		if errors.As(err, &ambiguous) {
+			for _, match := range ambiguous.Matches {
+				fmt.Printf("  %s  %s\n", match, contactName(addressBook, match))
+			}
+		}
+		return "", false
+	}
+	return id, true
+}
+
+// contactName returns the name of the contact with the given ID
+func contactName(addressBook *models.AddressBook, id string) string {
+	contact, err := addressBook.GetContact(id)
+	if err != nil {
+		return id
+	}
+	return tr.Name(contact.FirstName, contact.LastName)
+}
+
+func addNote(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("contact.id"))
+	if !ok {
+		return
+	}
+
+	names := make([]string, len(models.InteractionKinds))
+	for i, kind := range models.InteractionKinds {
+		names[i] = string(kind)
+	}
+	scanner.Prompt(tr.T("note.kind", strings.Join(names, "/")))
+	kind := models.InteractionNote
+	if text := scanner.Text(); text != "" {
+		parsed, err := models.ParseInteractionKind(text)
+		if err != nil {
+			fmt.Println(tr.T("note.kindError", err))
+			return
+		}
+		kind = parsed
+	}
+
+	scanner.Prompt(tr.T("note.summary"))
+	summary := scanner.Text()
+
+	interaction := models.Interaction{At: time.Now(), Kind: kind, Summary: summary}
+	if err := addressBook.AddInteraction(id, interaction); err != nil {
+		fmt.Println(tr.T("note.error", err))
+		return
+	}
+
+	fmt.Println(tr.T("note.done"))
+}
+
+func listNotes(scanner *readline.Reader, addressBook *models.AddressBook) {
+	id, ok := readID(scanner, addressBook, tr.T("contact.id"))
+	if !ok {
+		return
+	}
+
+	contact, err := addressBook.GetContact(id)
+	if err != nil {
+		fmt.Println(tr.T("find.error", err))
+		return
+	}
+	if len(contact.Interactions) == 0 {
+		fmt.Println(tr.T("notes.none"))
+		return
+	}
+
+	fmt.Println("\n" + tr.T("notes.title", tr.Name(contact.FirstName, contact.LastName)))
+	for _, interaction := range contact.Interactions {
+		fmt.Printf("%s  [%s] %s\n", tr.DateTime(interaction.At), interaction.Kind, interaction.Summary)
+	}
+}
+
+func findStaleContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("stale.days"))
+	days, err := strconv.Atoi(scanner.Text())
+	if err != nil || days < 0 {
+		fmt.Println(tr.T("days.invalid"))
+		return
+	}
+
+	contacts := addressBook.NotContactedSince(time.Now().AddDate(0, 0, -days))
+	if len(contacts) == 0 {
+		fmt.Println(tr.N("stale.none", days))
+		return
+	}
+
+	fmt.Println("\n" + tr.N("stale.title", days))
 	for _, contact := range contacts {
-		if err := addressBook.AddContact(contact); err != nil {
-			fmt.Printf("Error adding test contact: %v\n", err)
+		last := tr.T("stale.never")
+		if at, ok := contact.LastContacted(); ok {
+			last = tr.Date(at)
+		}
+		fmt.Println(tr.T("stale.contact", tr.Name(contact.FirstName, contact.LastName), contact.ID, last))
+	}
+}
+
+func printDuplicates(addressBook *models.AddressBook, minScore float64) {
+	clusters := addressBook.FindDuplicates(minScore)
+	if len(clusters) == 0 {
+		fmt.Println(tr.T("duplicates.none"))
+		return
+	}
+
+	var ids []string
+	for _, cluster := range clusters {
+		for _, contact := range cluster.Contacts {
+			ids = append(ids, contact.ID)
+		}
+	}
+	short := make(map[string]string, len(ids))
+	for i, id := range addressBook.ShortIDs(ids) {
+		short[ids[i]] = id
+	}
+
+	fmt.Println("\n" + tr.N("duplicates.title", len(clusters)))
+	for i, cluster := range clusters {
+		reasons := make([]string, len(cluster.Reasons))
+		for j, reason := range cluster.Reasons {
+			reasons[j] = reason
+			if key := "reason." + strings.ReplaceAll(reason, " ", "-"); tr.Has(key) {
+				reasons[j] = tr.T(key)
+			}
+		}
+		fmt.Println("\n" + tr.T("duplicates.group", i+1, cluster.Score*100, strings.Join(reasons, ", ")))
+		for _, contact := range cluster.Contacts {
+			fmt.Printf("   %s  %s <%s> %s\n", short[contact.ID],
+				tr.Name(contact.FirstName, contact.LastName), contact.Email, contact.Phone)
+		}
+	}
+}
+
+func mergeContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("merge.ids"))
+	var ids []string
+	for _, prefix := range strings.Fields(scanner.Text()) {
+		id, err := addressBook.ResolveID(prefix)
+		if err != nil {
+			fmt.Println(tr.T("find.error", err))
 			return
 		}
+		ids = append(ids, id)
+	}
+
+	names := []string{"interactive"}
+	for _, policy := range models.MergePolicies {
+		names = append(names, string(policy))
+	}
+	scanner.Prompt(tr.T("merge.policy", strings.Join(names, "/")))
+	opts := models.MergeOptions{}
+	if text := strings.TrimSpace(scanner.Text()); text == "" || text == "interactive" {
+		opts.Resolve = func(conflict models.FieldConflict) (string, error) {
+			return readChoice(scanner, conflict)
+		}
+	} else {
+		policy, err := models.ParseMergePolicy(text)
+		if err != nil {
+			fmt.Println(tr.T("merge.policyError", err))
+			return
+		}
+		opts.Policy = policy
+	}
+
+	merged, err := addressBook.Merge(ids, opts)
+	if err != nil {
+		fmt.Println(tr.T("merge.error", err))
+		return
+	}
+
+	fmt.Println(tr.T("merge.done"))
+	printContact(addressBook, merged)
+}
+
+// readChoice asks which of the conflicting values a merged contact keeps
+func readChoice(scanner *readline.Reader, conflict models.FieldConflict) (string, error) {
+	fmt.Println("\n" + tr.T("merge.conflict", conflict.Field))
+	for i, value := range conflict.Values {
+		fmt.Printf("%d. %s\n", i+1, value)
+	}
+	for {
+		if !scanner.Prompt(tr.T("merge.keep", len(conflict.Values))) {
+			return "", errors.New(tr.T("merge.cancelled"))
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			return conflict.Values[0], nil
+		}
+		if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= len(conflict.Values) {
+			return conflict.Values[n-1], nil
+		}
+		fmt.Println(tr.T("menu.invalid"))
+	}
+}
+
+func listCompanies(addressBook *models.AddressBook) {
+	companies := addressBook.Companies()
+	if len(companies) == 0 {
+		fmt.Println(tr.T("companies.none"))
+		return
+	}
+
+	fmt.Println("\n" + tr.T("companies.title"))
+	for _, company := range companies {
+		fmt.Printf("%s (%d)\n", company.Name, len(company.Contacts))
+		for _, contact := range company.Contacts {
+			fmt.Printf("  %s", tr.Name(contact.FirstName, contact.LastName))
+			if contact.Title != "" {
+				fmt.Printf(", %s", contact.Title)
+			}
+			if contact.Department != "" {
+				fmt.Printf(" (%s)", contact.Department)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func listCompanyContacts(scanner *readline.Reader, addressBook *models.AddressBook) {
+	scanner.Prompt(tr.T("company.name"))
+	name := scanner.Text()
+
+	contacts := addressBook.ContactsAtCompany(name)
+	if len(contacts) == 0 {
+		fmt.Println(tr.T("company.none"))
+		return
 	}
 
-	fmt.Println("Generated 10 test contacts successfully!")
+	fmt.Println("\n" + tr.T("company.title", contacts[0].Company()))
+	for _, contact := range contacts {
+		printContact(addressBook, contact)
+	}
 }
 
-func printContact(contact *models.Contact) {
-	fmt.Printf("\nID: %s\n", contact.ID)
-	fmt.Printf("Name: %s %s\n", contact.FirstName, contact.LastName)
-	fmt.Printf("Email: %s\n", contact.Email)
-	fmt.Printf("Phone: %s\n", contact.Phone)
-	fmt.Printf("Address: %s\n", contact.Address)
-	fmt.Printf("Created: %s\n", contact.CreatedAt.Format(time.RFC3339))
-	fmt.Printf("Updated: %s\n", contact.UpdatedAt.Format(time.RFC3339))
+func printContact(addressBook *models.AddressBook, contact *models.Contact) {
+	fmt.Println("\n" + tr.T("show.id", contact.ID, addressBook.ShortID(contact.ID)))
+	fmt.Println(tr.T("show.name", tr.Name(contact.FirstName, contact.LastName)))
+	fmt.Println(tr.T("show.email", contact.Email))
+	fmt.Println(tr.T("show.phone", contact.Phone))
+	fmt.Println(tr.T("show.address", contact.Address))
+	for _, email := range contact.OtherEmails {
+		fmt.Println(tr.T("show.otherEmail", email))
+	}
+	for _, phone := range contact.OtherPhones {
+		fmt.Println(tr.T("show.otherPhone", phone))
+	}
+	for _, address := range contact.OtherAddresses {
+		fmt.Println(tr.T("show.otherAddress", address))
+	}
+	if contact.Organization != "" {
+		fmt.Println(tr.T("show.org", contact.Organization))
+	}
+	if contact.Title != "" {
+		fmt.Println(tr.T("show.title", contact.Title))
+	}
+	if contact.Department != "" {
+		fmt.Println(tr.T("show.dept", contact.Department))
+	}
+	if len(contact.Tags) > 0 {
+		fmt.Println(tr.T("show.tags", strings.Join(contact.Tags, ", ")))
+	}
+	for _, date := range contact.Dates {
+		fmt.Printf("%s: %s\n", capitalize(dateLabel(date.Label)), formatDate(date))
+	}
+	for _, field := range addressBook.Schema() {
+		if value, ok := contact.Custom[field.Name]; ok {
+			fmt.Printf("%s: %s\n", field.Name, value)
+		}
+	}
+	for _, link := range addressBook.Links(contact.ID) {
+		fmt.Printf("%s: %s\n", relationLabel(link.Type, false), contactName(addressBook, link.To))
+	}
+	for _, link := range addressBook.LinksTo(contact.ID) {
+		if link.Type != models.RelationSpouse {
+			fmt.Printf("%s: %s\n", relationLabel(link.Type, true), contactName(addressBook, link.From))
+		}
+	}
+	if last, ok := contact.LastContacted(); ok {
+		fmt.Println(tr.T("show.lastContacted", tr.DateTime(last)))
+	}
+	if len(contact.Interactions) > 0 {
+		fmt.Println(tr.N("show.notes", len(contact.Interactions)))
+	}
+	if len(contact.Aliases) > 0 {
+		fmt.Println(tr.T("show.aliases", strings.Join(contact.Aliases, ", ")))
+	}
+	fmt.Println(tr.T("show.created", tr.DateTime(contact.CreatedAt)))
+	fmt.Println(tr.T("show.updated", tr.DateTime(contact.UpdatedAt)))
 }
@@ -1,3 +1,10 @@
 module github.com/rushi/address-book-cli
 
 go 1.24.1
+
+require (
+	golang.org/x/term v0.31.0
+	golang.org/x/text v0.24.0
+)
+
+require golang.org/x/sys v0.32.0 // indirect
@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/rushi/address-book-cli/internal/i18n"
+	"github.com/rushi/address-book-cli/internal/models"
 )
 
 // Config represents the application configuration
 type Config struct {
-	CSVPath string `json:"csvPath"`
+	CSVPath      string        `json:"csvPath"`
+	HistoryPath  string        `json:"historyPath,omitempty"`
+	Locale       string        `json:"locale,omitempty"`
+	CustomFields models.Schema `json:"customFields,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -65,10 +71,25 @@ func (c *Config) Save(configPath string) error {
 	return nil
 }
 
+// HistoryFile returns where the interactive menu keeps its input history:
+// HistoryPath if set, otherwise next to the CSV file
+func (c *Config) HistoryFile() string {
+	if c.HistoryPath != "" {
+		return c.HistoryPath
+	}
+	return filepath.Join(filepath.Dir(c.CSVPath), ".history")
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if c.CSVPath == "" {
 		return fmt.Errorf("CSV path is required")
 	}
+	if err := c.CustomFields.Validate(); err != nil {
+		return fmt.Errorf("invalid custom fields: %w", err)
+	}
+	if _, err := i18n.Parse(c.Locale); err != nil {
+		return fmt.Errorf("invalid locale %q: %w", c.Locale, err)
+	}
 	return nil
 }
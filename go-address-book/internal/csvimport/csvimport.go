@@ -0,0 +1,308 @@
+// Package csvimport reads the CSV files other address books export, such as
+// Google Contacts and Outlook, whose layouts have dozens of columns like
+// "E-mail 1 - Value" and "Business Phone". The layout, or dialect, is
+// recognized from the header row, and Read reports which column went to
+// which field so columns nothing reads can be pointed out.
+package csvimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/rushi/address-book-cli/internal/importer"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Dialect is the CSV layout of one application
+type Dialect struct {
+	Name    string   // as given to --dialect, such as "google"
+	Title   string   // such as "Google Contacts"
+	markers []string // headers that identify the layout
+	read    func(r *row, c *models.Contact)
+}
+
+// Dialects lists the layouts Read understands
+var Dialects = []*Dialect{google, outlook}
+
+// Names returns the names of Dialects
+func Names() []string {
+	names := make([]string, len(Dialects))
+	for i, d := range Dialects {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// Lookup returns the dialect with the given name, ignoring case
+func Lookup(name string) (*Dialect, error) {
+	for _, d := range Dialects {
+		if strings.EqualFold(d.Name, strings.TrimSpace(name)) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown CSV dialect %q; dialects are %s", name, strings.Join(Names(), ", "))
+}
+
+// Detect returns the dialect whose identifying headers the header row has
+// most of, or nil when it has none of any
+func Detect(header []string) *Dialect {
+	present := make(map[string]bool, len(header))
+	for _, h := range header {
+		present[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+	var best *Dialect
+	bestScore := 0
+	for _, d := range Dialects {
+		score := 0
+		for _, m := range d.markers {
+			if present[strings.ToLower(m)] {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
+
+// Column is how one column of a file was read
+type Column struct {
+	Header string
+	Field  string // the contact field it fills, or "" when nothing reads it
+	Values int    // rows with a value in the column
+}
+
+// Warning is a problem with one row, which Read skips or reads in part
+type Warning struct {
+	Line int
+	Msg  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Msg)
+}
+
+// Result is what Read found in a file
+type Result struct {
+	Dialect  *Dialect
+	Contacts []*models.Contact
+	Columns  []Column // in file order
+	Warnings []Warning
+}
+
+// Unmapped returns the columns nothing reads, with and without values
+func (r *Result) Unmapped() (used, empty []Column) {
+	for _, col := range r.Columns {
+		switch {
+		case col.Field != "":
+		case col.Values > 0:
+			used = append(used, col)
+		default:
+			empty = append(empty, col)
+		}
+	}
+	return used, empty
+}
+
+// Read reads the contacts in a CSV file in the given dialect, or in the
+// one Detect finds when dialect is nil. Files may be UTF-8, UTF-16 with a
+// byte-order mark, or Windows-1252 as older Outlook versions write them,
+// and separated by commas or, as in some locales, semicolons.
+func Read(r io.Reader, dialect *Dialect) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = importer.DecodeText(data); err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(data) {
+		// Older Outlook versions write Windows-1252
+		if data, err = charmap.Windows1252.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = separator(data)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	if dialect == nil {
+		if dialect = Detect(header); dialect == nil {
+			return nil, fmt.Errorf("unrecognized CSV layout; dialects are %s", strings.Join(Names(), ", "))
+		}
+	}
+
+	result := &Result{Dialect: dialect, Columns: make([]Column, len(header))}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		result.Columns[i].Header = h
+		if _, dup := index[strings.ToLower(h)]; !dup {
+			index[strings.ToLower(h)] = i
+		}
+	}
+	// An empty row marks every column the dialect reads, even in a file
+	// without rows
+	dialect.read(&row{index: index, columns: result.Columns, result: &Result{}}, &models.Contact{})
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		blank := true
+		for i, value := range record {
+			if i < len(header) && strings.TrimSpace(value) != "" {
+				result.Columns[i].Values++
+				blank = false
+			}
+		}
+		if blank {
+			continue
+		}
+
+		r := &row{index: index, record: record, columns: result.Columns, line: line, result: result}
+		contact := models.NewContact("", "", "", "", "")
+		dialect.read(r, contact)
+		if !named(contact) {
+			r.warn("no name, email or company; skipped")
+			continue
+		}
+		result.Contacts = append(result.Contacts, contact)
+	}
+	return result, nil
+}
+
+// separator returns the field separator of a file: a semicolon when the
+// header row has more of them than commas
+func separator(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// named makes sure a contact has a name, falling back to its company and
+// then its email, and reports whether it has one
+func named(c *models.Contact) bool {
+	if c.FirstName != "" || c.LastName != "" {
+		return true
+	}
+	switch {
+	case c.Organization != "":
+		c.FirstName = c.Organization
+	case c.Email != "":
+		c.FirstName = c.Email
+	default:
+		return false
+	}
+	return true
+}
+
+// row gives a dialect the values of one record by header, and records the
+// field each column it reads goes to
+type row struct {
+	index   map[string]int
+	record  []string
+	columns []Column
+	line    int
+	result  *Result
+}
+
+// get returns the value of the first of headers the file has, and marks
+// that column as read into field
+func (r *row) get(field string, headers ...string) string {
+	for _, h := range headers {
+		if i, ok := r.index[strings.ToLower(h)]; ok {
+			r.columns[i].Field = field
+			if i < len(r.record) {
+				return strings.TrimSpace(r.record[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// has reports whether the file has any of headers
+func (r *row) has(headers ...string) bool {
+	for _, h := range headers {
+		if _, ok := r.index[strings.ToLower(h)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *row) warn(format string, args ...any) {
+	r.result.Warnings = append(r.result.Warnings, Warning{Line: r.line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// date adds a yearly date to c, warning when the value can't be read
+func (r *row) date(c *models.Contact, label, value string, parse func(string) (string, bool)) {
+	if value == "" {
+		return
+	}
+	normalized, ok := parse(value)
+	if !ok {
+		// A placeholder for no date, such as Outlook's 0/0/00
+		return
+	}
+	date, err := models.ParseImportantDate(label, normalized)
+	if err != nil {
+		r.warn("%v", err)
+		return
+	}
+	c.SetDate(date)
+}
+
+// joinName joins the parts of a name that are given
+func joinName(parts ...string) string {
+	var given []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			given = append(given, p)
+		}
+	}
+	return strings.Join(given, " ")
+}
+
+// joinAddress joins address parts into one line such as
+// "1 Main St, Dallas, TX 75001, USA"
+func joinAddress(box, street, city, region, postalCode, country string) string {
+	var parts []string
+	for _, part := range []string{box, street, city, joinName(region, postalCode), country} {
+		part = strings.Join(strings.Fields(strings.ReplaceAll(part, "\n", ", ")), " ")
+		if part = strings.Trim(part, ", "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// addNote adds a note interaction
+func addNote(c *models.Contact, note string) {
+	if note = strings.TrimSpace(note); note != "" {
+		c.Interactions = append(c.Interactions, models.Interaction{At: c.CreatedAt, Kind: models.InteractionNote, Summary: note})
+	}
+}
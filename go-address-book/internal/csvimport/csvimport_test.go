@@ -0,0 +1,212 @@
+package csvimport
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+const googleCSV = `First Name,Middle Name,Last Name,Nickname,Organization Name,Organization Title,Organization Department,Birthday,Notes,Photo,Labels,E-mail 1 - Label,E-mail 1 - Value,E-mail 2 - Label,E-mail 2 - Value,Phone 1 - Label,Phone 1 - Value,Address 1 - Label,Address 1 - Formatted,Address 1 - Street,Address 1 - City,Address 1 - PO Box,Address 1 - Region,Address 1 - Postal Code,Address 1 - Country,Address 1 - Extended Address,Website 1 - Label,Website 1 - Value,Event 1 - Label,Event 1 - Value,Custom Field 1 - Label,Custom Field 1 - Value
+Ada,King,Lovelace,Ada,Analytical Engines,Countess,Math,1815-12-10,"Met at the salon.
+Likes poetry.",https://photo.example/ada,* myContacts ::: Friends ::: VIP,Home,ada@home.example ::: ada@old.example,* Work,ada@work.example,Mobile,+44 20 7946 0000,Home,"12 St James's Sq
+London",12 St James's Sq,London,,,SW1Y 4JH,UK,,Blog,https://ada.example,Anniversary,1835-07-08,Tier,gold
+,,,,Initech,,,--04-01,,,* starred,,,,,,,,,,,,,,,,,,,,,
+`
+
+const outlookCSV = `Title,First Name,Middle Name,Last Name,Suffix,Company,Department,Job Title,Business Street,Business Street 2,Business City,Business State,Business Postal Code,Business Country/Region,Home Street,Home City,Home State,Home Postal Code,Home Country/Region,Assistant's Phone,Business Fax,Business Phone,Home Phone,Mobile Phone,Anniversary,Birthday,Categories,E-mail Address,E-mail Type,E-mail 2 Address,E-mail 2 Type,Notes,Spouse
+Dr.,Grace,Brewster,Hopper,,US Navy,Computing,Rear Admiral,1 Navy Way,Suite 2,Arlington,VA,22202,USA,,,,,,555-0109,555-0101,555-0102,555-0103,555-0104,0/0/00,12/9/1906,Navy;Pioneers,/o=ExchangeLabs/ou=Exchange/cn=Recipients/cn=grace,EX,grace@navy.example,SMTP,,Vincent
+,Linus,,Torvalds,,,,,,,,,,,Main St 1,Portland,OR,97201,USA,,,,,,,28/12/69,,linus@example.org,SMTP,,,Wrote Linux,
+`
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{strings.SplitN(googleCSV, "\n", 2)[0], "google"},
+		{"Name,Given Name,Additional Name,Family Name,Group Membership,E-mail 1 - Type,E-mail 1 - Value", "google"},
+		{strings.SplitN(outlookCSV, "\n", 2)[0], "outlook"},
+		{"ID,FirstName,LastName,Email,Phone,Address,CreatedAt,UpdatedAt", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if d := Detect(strings.Split(tt.header, ",")); d != nil {
+			got = d.Name
+		}
+		if got != tt.want {
+			t.Errorf("Detect(%.40q...) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestReadGoogle(t *testing.T) {
+	result, err := Read(strings.NewReader(googleCSV), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dialect != google || len(result.Warnings) > 0 || len(result.Contacts) != 2 {
+		t.Fatalf("dialect %s, warnings %v, %d contacts", result.Dialect.Name, result.Warnings, len(result.Contacts))
+	}
+
+	ada := result.Contacts[0]
+	if ada.FirstName != "Ada King" || ada.LastName != "Lovelace" || ada.Organization != "Analytical Engines" ||
+		ada.Title != "Countess" || ada.Department != "Math" {
+		t.Errorf("ada = %+v", ada)
+	}
+	// The "* Work" label makes the second email primary
+	if ada.Email != "ada@work.example" || !slices.Equal(ada.OtherEmails, []string{"ada@home.example", "ada@old.example"}) {
+		t.Errorf("emails = %q, %q", ada.Email, ada.OtherEmails)
+	}
+	if ada.Phone != "+44 20 7946 0000" || ada.Address != "12 St James's Sq, London, SW1Y 4JH, UK" {
+		t.Errorf("phone %q, address %q", ada.Phone, ada.Address)
+	}
+	if !slices.Equal(ada.Tags, []string{"friends", "vip"}) {
+		t.Errorf("tags = %q", ada.Tags)
+	}
+	wantDates := []models.ImportantDate{
+		{Label: models.LabelBirthday, Year: 1815, Month: time.December, Day: 10},
+		{Label: models.LabelAnniversary, Year: 1835, Month: time.July, Day: 8},
+	}
+	if !slices.Equal(ada.Dates, wantDates) {
+		t.Errorf("dates = %v", ada.Dates)
+	}
+	if len(ada.Interactions) != 1 || ada.Interactions[0].Summary != "Met at the salon.\nLikes poetry." {
+		t.Errorf("interactions = %+v", ada.Interactions)
+	}
+	if ada.Custom["Tier"] != "gold" {
+		t.Errorf("custom = %q", ada.Custom)
+	}
+
+	// A company without a person's name is named after the company
+	initech := result.Contacts[1]
+	if initech.FirstName != "Initech" || len(initech.Tags) != 0 || len(initech.Dates) != 1 || initech.Dates[0].Year != 0 {
+		t.Errorf("initech = %+v", initech)
+	}
+
+	used, empty := result.Unmapped()
+	var names []string
+	for _, col := range used {
+		names = append(names, col.Header)
+	}
+	if want := []string{"Nickname", "Photo", "Website 1 - Label", "Website 1 - Value"}; !slices.Equal(names, want) {
+		t.Errorf("unmapped columns with values = %q, want %q", names, want)
+	}
+	if len(empty) != 0 {
+		t.Errorf("unmapped empty columns = %v", empty)
+	}
+	for _, col := range result.Columns {
+		if col.Header == "E-mail 1 - Value" && (col.Field != "email" || col.Values != 1) {
+			t.Errorf("E-mail 1 - Value column = %+v", col)
+		}
+	}
+}
+
+func TestReadOutlook(t *testing.T) {
+	result, err := Read(strings.NewReader(outlookCSV), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dialect != outlook || len(result.Contacts) != 2 {
+		t.Fatalf("dialect %s, %d contacts", result.Dialect.Name, len(result.Contacts))
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Line != 2 || !strings.Contains(result.Warnings[0].Msg, "not an email address") {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+
+	grace := result.Contacts[0]
+	if grace.FirstName != "Grace Brewster" || grace.LastName != "Hopper" || grace.Organization != "US Navy" ||
+		grace.Title != "Rear Admiral" || grace.Department != "Computing" {
+		t.Errorf("grace = %+v", grace)
+	}
+	if grace.Email != "grace@navy.example" || len(grace.OtherEmails) != 0 {
+		t.Errorf("emails = %q, %q", grace.Email, grace.OtherEmails)
+	}
+	// Mobile comes before business and home, and the assistant and fax last
+	if grace.Phone != "555-0104" || !slices.Equal(grace.OtherPhones, []string{"555-0102", "555-0103", "555-0109", "555-0101"}) {
+		t.Errorf("phones = %q, %q", grace.Phone, grace.OtherPhones)
+	}
+	if grace.Address != "1 Navy Way, Suite 2, Arlington, VA 22202, USA" {
+		t.Errorf("address = %q", grace.Address)
+	}
+	if !slices.Equal(grace.Tags, []string{"navy", "pioneers"}) {
+		t.Errorf("tags = %q", grace.Tags)
+	}
+	if want := []models.ImportantDate{{Label: models.LabelBirthday, Year: 1906, Month: time.December, Day: 9}}; !slices.Equal(grace.Dates, want) {
+		t.Errorf("dates = %v", grace.Dates)
+	}
+
+	linus := result.Contacts[1]
+	if linus.Address != "Main St 1, Portland, OR 97201, USA" || linus.Email != "linus@example.org" {
+		t.Errorf("linus = %+v", linus)
+	}
+	if want := []models.ImportantDate{{Label: models.LabelBirthday, Year: 1969, Month: time.December, Day: 28}}; !slices.Equal(linus.Dates, want) {
+		t.Errorf("dates = %v", linus.Dates)
+	}
+
+	used, _ := result.Unmapped()
+	var names []string
+	for _, col := range used {
+		names = append(names, col.Header)
+	}
+	if want := []string{"Title", "E-mail Type", "E-mail 2 Type", "Spouse"}; !slices.Equal(names, want) {
+		t.Errorf("unmapped columns with values = %q, want %q", names, want)
+	}
+}
+
+func TestReadEncodings(t *testing.T) {
+	text := "First Name;Last Name;E-mail Address;Business Phone\r\nJosé;Müller;jose@example.com;555-0100\r\n"
+	latin1, _ := charmap.Windows1252.NewEncoder().String(text)
+	utf16, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(text)
+	for name, data := range map[string]string{"UTF-8 with BOM": "\uFEFF" + text, "Windows-1252": latin1, "UTF-16": utf16} {
+		result, err := Read(strings.NewReader(data), nil)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(result.Contacts) != 1 {
+			t.Errorf("%s: %d contacts", name, len(result.Contacts))
+			continue
+		}
+		if c := result.Contacts[0]; c.FirstName != "José" || c.LastName != "Müller" || c.Phone != "555-0100" {
+			t.Errorf("%s: contact %+v", name, c)
+		}
+	}
+}
+
+func TestReadProblems(t *testing.T) {
+	if _, err := Read(strings.NewReader("a,b,c\n1,2,3\n"), nil); err == nil {
+		t.Error("Read accepted an unknown layout")
+	}
+	if _, err := Read(strings.NewReader(""), nil); err == nil {
+		t.Error("Read accepted an empty file")
+	}
+
+	// A forced dialect reads a file Detect wouldn't recognize
+	result, err := Read(strings.NewReader("First Name,Last Name,Birthday,Notes\nAda,Lovelace,13/13/1815,\n,,,only a note\n,,,\n"), outlook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Contacts) != 1 || len(result.Contacts[0].Dates) != 0 {
+		t.Errorf("contacts = %+v", result.Contacts)
+	}
+	var lines []int
+	for _, w := range result.Warnings {
+		lines = append(lines, w.Line)
+	}
+	if !slices.Equal(lines, []int{2, 3}) {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+
+	if _, err := Lookup("OUTLOOK"); err != nil {
+		t.Error(err)
+	}
+	if _, err := Lookup("yahoo"); err == nil {
+		t.Error("Lookup accepted an unknown dialect")
+	}
+}
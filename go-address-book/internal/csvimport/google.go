@@ -0,0 +1,118 @@
+package csvimport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/importer"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// google reads the CSV files Google Contacts exports, in both the current
+// layout ("First Name", "Labels", "E-mail 1 - Label") and the older one
+// ("Given Name", "Group Membership", "E-mail 1 - Type"). Numbered groups
+// such as "Phone 2 - Value" may hold several values joined by " ::: ", and
+// a label starting with "* " marks the primary value.
+var google = &Dialect{
+	Name:  "google",
+	Title: "Google Contacts",
+	markers: []string{
+		"E-mail 1 - Value", "Phone 1 - Value", "Address 1 - Formatted", "Labels", "Group Membership",
+		"Organization Name", "Organization 1 - Name", "Given Name", "Family Name",
+	},
+	read: readGoogle,
+}
+
+// googleSeparator joins several values in one cell
+const googleSeparator = " ::: "
+
+func readGoogle(r *row, c *models.Contact) {
+	c.FirstName = joinName(r.get("first", "First Name", "Given Name"), r.get("first", "Middle Name", "Additional Name"))
+	c.LastName = r.get("last", "Last Name", "Family Name")
+	if name := r.get("first", "Name"); c.FirstName == "" && c.LastName == "" && name != "" {
+		c.FirstName = name
+	}
+	c.Organization = r.get("org", "Organization Name", "Organization 1 - Name")
+	c.Title = r.get("title", "Organization Title", "Organization 1 - Title")
+	c.Department = r.get("dept", "Organization Department", "Organization 1 - Department")
+
+	var tags []string
+	for _, label := range strings.Split(r.get("tags", "Labels", "Group Membership"), googleSeparator) {
+		// "* myContacts" and "* starred" are Google's own groups
+		if label = strings.TrimSpace(label); label != "" && !strings.HasPrefix(label, "* ") {
+			tags = append(tags, label)
+		}
+	}
+	c.Tags = models.ParseTags(strings.Join(tags, ","))
+
+	var emails, phones, addresses []importer.Ranked
+	for n := 1; r.has(googleGroup("E-mail", n, "Value")); n++ {
+		rank := googleRank(r, "email", "E-mail", n)
+		for _, email := range strings.Split(r.get("email", googleGroup("E-mail", n, "Value")), googleSeparator) {
+			emails = append(emails, importer.Ranked{Value: strings.TrimSpace(email), Rank: rank})
+		}
+	}
+	for n := 1; r.has(googleGroup("Phone", n, "Value")); n++ {
+		rank := googleRank(r, "phone", "Phone", n)
+		for _, phone := range strings.Split(r.get("phone", googleGroup("Phone", n, "Value")), googleSeparator) {
+			phones = append(phones, importer.Ranked{Value: strings.TrimSpace(phone), Rank: rank})
+		}
+	}
+	for n := 1; r.has(googleGroup("Address", n, "Formatted"), googleGroup("Address", n, "Street")); n++ {
+		rank := googleRank(r, "address", "Address", n)
+		part := func(name string) string {
+			return r.get("address", googleGroup("Address", n, name))
+		}
+		formatted := part("Formatted")
+		address := joinAddress(part("PO Box"), joinName(part("Street"), part("Extended Address")),
+			part("City"), part("Region"), part("Postal Code"), part("Country"))
+		if address == "" {
+			address = joinAddress("", formatted, "", "", "", "")
+		}
+		addresses = append(addresses, importer.Ranked{Value: address, Rank: rank})
+	}
+	c.Email, c.OtherEmails = importer.Primary(emails)
+	c.Phone, c.OtherPhones = importer.Primary(phones)
+	c.Address, c.OtherAddresses = importer.Primary(addresses)
+
+	r.date(c, models.LabelBirthday, r.get("dates", "Birthday"), googleDate)
+	for n := 1; r.has(googleGroup("Event", n, "Value")); n++ {
+		label := r.get("dates", googleGroup("Event", n, "Label"), googleGroup("Event", n, "Type"))
+		switch {
+		case label == "":
+			label = "other"
+		case strings.EqualFold(label, models.LabelAnniversary):
+			label = models.LabelAnniversary
+		}
+		r.date(c, label, r.get("dates", googleGroup("Event", n, "Value")), googleDate)
+	}
+	for n := 1; r.has(googleGroup("Custom Field", n, "Value")); n++ {
+		name := r.get("custom fields", googleGroup("Custom Field", n, "Label"), googleGroup("Custom Field", n, "Type"))
+		if value := r.get("custom fields", googleGroup("Custom Field", n, "Value")); name != "" && value != "" {
+			c.SetCustom(name, value)
+		}
+	}
+	addNote(c, r.get("notes", "Notes"))
+}
+
+// googleGroup returns the header of one column of a numbered group, such as
+// "E-mail 2 - Value"
+func googleGroup(group string, n int, column string) string {
+	return fmt.Sprintf("%s %d - %s", group, n, column)
+}
+
+// googleRank returns the preference of a numbered group: first when its
+// label starts with "* ", and otherwise in the order of the groups
+func googleRank(r *row, field, group string, n int) int {
+	label := r.get(field, googleGroup(group, n, "Label"), googleGroup(group, n, "Type"))
+	if strings.HasPrefix(label, "* ") {
+		return 0
+	}
+	return n
+}
+
+// googleDate reads Google's "1985-04-12", and "--04-12" for a date without
+// a year
+func googleDate(value string) (string, bool) {
+	return strings.TrimSpace(value), true
+}
@@ -0,0 +1,107 @@
+package csvimport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rushi/address-book-cli/internal/importer"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// outlook reads the CSV files Outlook exports. Each kind of phone and
+// address has its own columns ("Business Phone", "Home City"), emails are
+// "E-mail Address" to "E-mail 3 Address", and dates are written month
+// first, with "0/0/00" for none.
+var outlook = &Dialect{
+	Name:  "outlook",
+	Title: "Outlook",
+	markers: []string{
+		"E-mail Address", "E-mail 2 Address", "Business Phone", "Mobile Phone", "Home Phone",
+		"Job Title", "Business Street", "Home Street", "Company",
+	},
+	read: readOutlook,
+}
+
+// outlookPhones are Outlook's phone columns, most likely primary first
+var outlookPhones = []string{
+	"Primary Phone", "Mobile Phone", "Business Phone", "Home Phone", "Business Phone 2", "Home Phone 2",
+	"Company Main Phone", "Other Phone", "Car Phone", "Assistant's Phone", "Callback", "Radio Phone",
+	"Pager", "ISDN", "TTY/TDD Phone", "Business Fax", "Home Fax", "Other Fax",
+}
+
+// outlookAddresses are the kinds of address Outlook has columns for
+var outlookAddresses = []string{"Business", "Home", "Other"}
+
+func readOutlook(r *row, c *models.Contact) {
+	c.FirstName = joinName(r.get("first", "First Name"), r.get("first", "Middle Name"))
+	c.LastName = r.get("last", "Last Name")
+	c.Organization = r.get("org", "Company")
+	c.Title = r.get("title", "Job Title")
+	c.Department = r.get("dept", "Department")
+	// Outlook separates categories with semicolons
+	c.Tags = models.ParseTags(strings.ReplaceAll(r.get("tags", "Categories"), ";", ","))
+
+	var emails, phones, addresses []importer.Ranked
+	for i, header := range []string{"E-mail Address", "E-mail 2 Address", "E-mail 3 Address"} {
+		email := r.get("email", header)
+		// Exchange addresses such as "/o=ExchangeLabs/ou=..." can't be mailed
+		if email != "" && !strings.Contains(email, "@") {
+			r.warn("%s %q is not an email address; skipped", header, email)
+			continue
+		}
+		emails = append(emails, importer.Ranked{Value: email, Rank: i})
+	}
+	for i, header := range outlookPhones {
+		phones = append(phones, importer.Ranked{Value: r.get("phone", header), Rank: i})
+	}
+	for i, kind := range outlookAddresses {
+		part := func(name string) string {
+			return r.get("address", kind+" "+name)
+		}
+		street := strings.Join([]string{part("Street"), part("Street 2"), part("Street 3")}, "\n")
+		address := joinAddress(part("Address PO Box"), street, part("City"), part("State"), part("Postal Code"), part("Country/Region"))
+		addresses = append(addresses, importer.Ranked{Value: address, Rank: i})
+	}
+	c.Email, c.OtherEmails = importer.Primary(emails)
+	c.Phone, c.OtherPhones = importer.Primary(phones)
+	c.Address, c.OtherAddresses = importer.Primary(addresses)
+
+	r.date(c, models.LabelBirthday, r.get("dates", "Birthday"), outlookDate)
+	r.date(c, models.LabelAnniversary, r.get("dates", "Anniversary"), outlookDate)
+	addNote(c, r.get("notes", "Notes"))
+}
+
+// outlookDate reads Outlook's month-first dates such as "4/12/1985", and
+// ISO dates; "0/0/00" means there is no date. A first number over 12 is
+// taken as the day, as in day-first locales.
+func outlookDate(value string) (string, bool) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return value, true
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return value, true
+		}
+		nums[i] = n
+	}
+	month, day, year := nums[0], nums[1], nums[2]
+	if month == 0 && day == 0 {
+		return "", false
+	}
+	if month > 12 {
+		month, day = day, month
+	}
+	if year < 100 {
+		// A two-digit year is the latest one that isn't in the future
+		year += 2000
+		if year > time.Now().Year() {
+			year -= 100
+		}
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day), true
+}
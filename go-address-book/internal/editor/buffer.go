@@ -0,0 +1,343 @@
+// Package editor edits a contact as text in the user's $EDITOR. The buffer
+// has one "key: value" line per field, with custom fields in a [custom]
+// section:
+//
+//	first: John
+//	last: Smith
+//	tags: vendor, vip
+//	birthday: 1980-01-05
+//
+//	[custom]
+//	Account Manager: Pat Lee
+//
+// Every field is listed, so clearing a value or deleting its line clears
+// the field.
+package editor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// errorPrefix starts the comment lines Annotate adds for invalid values
+const errorPrefix = "# ERROR: "
+
+// customSection heads the custom fields
+const customSection = "[custom]"
+
+// field is a line of the buffer's main section
+type field struct {
+	key  string
+	get  func(c *models.Contact) string
+	set  func(c *models.Contact, value string) error
+	hint string // shown in the header comment
+}
+
+func stringField(key string, ptr func(c *models.Contact) *string) field {
+	return field{
+		key: key,
+		get: func(c *models.Contact) string { return *ptr(c) },
+		set: func(c *models.Contact, value string) error {
+			*ptr(c) = value
+			return nil
+		},
+	}
+}
+
+// listField is a list of values joined by sep; addresses hold commas, so
+// they use ";"
+func listField(key, sep string, ptr func(c *models.Contact) *[]string) field {
+	return field{
+		key:  key,
+		get:  func(c *models.Contact) string { return strings.Join(*ptr(c), sep+" ") },
+		hint: "separated by '" + sep + "'",
+		set: func(c *models.Contact, value string) error {
+			var values []string
+			for _, v := range strings.Split(value, sep) {
+				if v = strings.TrimSpace(v); v != "" && !slices.Contains(values, v) {
+					values = append(values, v)
+				}
+			}
+			*ptr(c) = values
+			return nil
+		},
+	}
+}
+
+func yearlyField(label string) field {
+	return field{
+		key: label,
+		get: func(c *models.Contact) string {
+			if date, ok := c.Date(label); ok {
+				return date.String()
+			}
+			return ""
+		},
+		hint: "YYYY-MM-DD or MM-DD",
+		set: func(c *models.Contact, value string) error {
+			if value == "" {
+				c.RemoveDate(label)
+				return nil
+			}
+			date, err := models.ParseImportantDate(label, value)
+			if err != nil {
+				return err
+			}
+			c.SetDate(date)
+			return nil
+		},
+	}
+}
+
+func isYearly(label string) bool {
+	return strings.EqualFold(label, models.LabelBirthday) || strings.EqualFold(label, models.LabelAnniversary)
+}
+
+// fields are the main section's lines, in order
+var fields = []field{
+	stringField("first", func(c *models.Contact) *string { return &c.FirstName }),
+	stringField("last", func(c *models.Contact) *string { return &c.LastName }),
+	stringField("email", func(c *models.Contact) *string { return &c.Email }),
+	stringField("phone", func(c *models.Contact) *string { return &c.Phone }),
+	stringField("address", func(c *models.Contact) *string { return &c.Address }),
+	stringField("org", func(c *models.Contact) *string { return &c.Organization }),
+	stringField("title", func(c *models.Contact) *string { return &c.Title }),
+	stringField("dept", func(c *models.Contact) *string { return &c.Department }),
+	{
+		key:  "tags",
+		get:  func(c *models.Contact) string { return strings.Join(c.Tags, ", ") },
+		set:  func(c *models.Contact, value string) error { c.Tags = models.ParseTags(value); return nil },
+		hint: "separated by ','",
+	},
+	listField("other-emails", ",", func(c *models.Contact) *[]string { return &c.OtherEmails }),
+	listField("other-phones", ",", func(c *models.Contact) *[]string { return &c.OtherPhones }),
+	listField("other-addresses", ";", func(c *models.Contact) *[]string { return &c.OtherAddresses }),
+	yearlyField(models.LabelBirthday),
+	yearlyField(models.LabelAnniversary),
+	{
+		key: "dates",
+		get: func(c *models.Contact) string {
+			var others []models.ImportantDate
+			for _, date := range c.Dates {
+				if !isYearly(date.Label) {
+					others = append(others, date)
+				}
+			}
+			return strings.ReplaceAll(models.FormatDates(others), ";", "; ")
+		},
+		hint: "label=YYYY-MM-DD, separated by ';'",
+		set: func(c *models.Contact, value string) error {
+			dates, err := models.ParseDates(value)
+			if err != nil {
+				return err
+			}
+			for _, date := range dates {
+				if isYearly(date.Label) {
+					return fmt.Errorf("put the %s on its own line", date.Label)
+				}
+				c.SetDate(date)
+			}
+			return nil
+		},
+	},
+}
+
+// Marshal writes a contact as an edit buffer
+func Marshal(c *models.Contact, schema models.Schema) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Editing %s (%s)\n", strings.TrimSpace(c.FirstName+" "+c.LastName), c.ID)
+	b.WriteString("# Lines starting with # are ignored. Leave a value empty to clear it.\n")
+	b.WriteString("# Delete everything to cancel.\n")
+	for _, f := range fields {
+		if f.hint != "" {
+			fmt.Fprintf(&b, "# %s: %s\n", f.key, f.hint)
+		}
+	}
+	b.WriteString("\n")
+	for _, f := range fields {
+		writeLine(&b, f.key, f.get(c))
+	}
+	if len(schema) > 0 {
+		b.WriteString("\n" + customSection + "\n")
+		for _, def := range schema {
+			if def.Type != models.FieldString {
+				fmt.Fprintf(&b, "# %s: %s\n", def.Name, def.Hint())
+			}
+			writeLine(&b, def.Name, c.Custom[def.Name])
+		}
+	}
+	return b.Bytes()
+}
+
+func writeLine(b *bytes.Buffer, key, value string) {
+	if value == "" {
+		b.WriteString(key + ":\n")
+		return
+	}
+	b.WriteString(key + ": " + value + "\n")
+}
+
+// LineError is a problem with one line of a buffer. Line is 1-based, or 0
+// for problems with the buffer as a whole.
+type LineError struct {
+	Line int
+	Msg  string
+}
+
+func (e LineError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// IsEmpty reports whether a buffer holds nothing but comments and blank
+// lines, which cancels the edit
+func IsEmpty(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse applies an edited buffer to a copy of base. Fields missing from the
+// buffer are cleared; the ID, version, notes and history are kept. It
+// returns every problem found, so they can all be fixed in one go.
+func Parse(data []byte, base *models.Contact, schema models.Schema) (*models.Contact, []LineError) {
+	c := base.Clone()
+	c.FirstName, c.LastName, c.Email, c.Phone, c.Address = "", "", "", "", ""
+	c.Organization, c.Title, c.Department = "", "", ""
+	c.Tags, c.OtherEmails, c.OtherPhones, c.OtherAddresses, c.Dates = nil, nil, nil, nil, nil
+	for _, def := range schema {
+		c.SetCustom(def.Name, "")
+	}
+
+	var errs []LineError
+	seen := make(map[string]int)
+	firstLine := 0
+	custom := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == customSection {
+			custom = true
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			errs = append(errs, LineError{n, `expected "field: value"`})
+			continue
+		}
+
+		var set func(c *models.Contact, value string) error
+		if custom {
+			def, found := lookupCustom(schema, key)
+			if !found {
+				errs = append(errs, LineError{n, fmt.Sprintf("unknown custom field %q", key)})
+				continue
+			}
+			key = "custom " + def.Name
+			set = func(c *models.Contact, value string) error {
+				if value != "" {
+					if err := def.Check(value); err != nil {
+						return err
+					}
+				}
+				c.SetCustom(def.Name, value)
+				return nil
+			}
+		} else {
+			i := slices.IndexFunc(fields, func(f field) bool { return strings.EqualFold(f.key, key) })
+			if i < 0 {
+				errs = append(errs, LineError{n, fmt.Sprintf("unknown field %q", key)})
+				continue
+			}
+			key, set = fields[i].key, fields[i].set
+			if key == "first" {
+				firstLine = n
+			}
+		}
+
+		if previous, dup := seen[key]; dup {
+			errs = append(errs, LineError{n, fmt.Sprintf("%s is already set on line %d", strings.TrimPrefix(key, "custom "), previous)})
+			continue
+		}
+		seen[key] = n
+		if err := set(c, value); err != nil {
+			errs = append(errs, LineError{n, err.Error()})
+		}
+	}
+	if c.FirstName == "" && c.LastName == "" {
+		errs = append(errs, LineError{firstLine, "a first or last name is required"})
+	}
+	slices.SortStableFunc(errs, func(a, b LineError) int { return a.Line - b.Line })
+	return c, errs
+}
+
+func lookupCustom(schema models.Schema, name string) (models.FieldDef, bool) {
+	for _, def := range schema {
+		if strings.EqualFold(def.Name, name) {
+			return def, true
+		}
+	}
+	return models.FieldDef{}, false
+}
+
+// Annotate returns the buffer with each error as a comment line above the
+// line it concerns, replacing the comments of an earlier attempt
+func Annotate(data []byte, errs []LineError) []byte {
+	var b bytes.Buffer
+	for _, err := range errs {
+		if err.Line == 0 {
+			b.WriteString(errorPrefix + err.Msg + "\n")
+		}
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), errorPrefix) {
+			continue
+		}
+		for _, err := range errs {
+			if err.Line == i+1 {
+				b.WriteString(errorPrefix + err.Msg + "\n")
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.Bytes()
+}
+
+// Diff lists the lines of the buffer that differ between two versions of a
+// contact, as "-" lines for old values and "+" lines for new ones
+func Diff(before, after *models.Contact, schema models.Schema) []string {
+	var diff []string
+	compare := func(key, old, new string) {
+		if old == new {
+			return
+		}
+		if old != "" {
+			diff = append(diff, "- "+key+": "+old)
+		}
+		if new != "" {
+			diff = append(diff, "+ "+key+": "+new)
+		}
+	}
+	for _, f := range fields {
+		compare(f.key, f.get(before), f.get(after))
+	}
+	for _, def := range schema {
+		compare(def.Name, before.Custom[def.Name], after.Custom[def.Name])
+	}
+	return diff
+}
@@ -0,0 +1,101 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// ErrCancelled is returned by Edit when the buffer is emptied, or handed
+// back unchanged after errors were pointed out
+var ErrCancelled = errors.New("edit cancelled")
+
+// Launcher opens a file in an editor and waits for it to close
+type Launcher func(path string) error
+
+// Command returns the editor to run: $VISUAL, then $EDITOR, then vi. The
+// variable may include arguments, such as "code --wait".
+func Command() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(name)); len(args) > 0 {
+			return args
+		}
+	}
+	return []string{"vi"}
+}
+
+// Launch runs the user's editor on path, attached to the terminal
+func Launch(path string) error {
+	args := Command()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// Edit opens a contact in an editor and saves the result with
+// UpdateContact, printing the changes to out. Invalid buffers are reopened
+// with the errors added as comments. It reports whether anything changed.
+func Edit(ab *models.AddressBook, id string, launch Launcher, out io.Writer) (bool, error) {
+	contact, err := ab.GetContact(id)
+	if err != nil {
+		return false, err
+	}
+	schema := ab.Schema()
+
+	file, err := os.CreateTemp("", "contact-*.txt")
+	if err != nil {
+		return false, err
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	buffer := Marshal(contact, schema)
+	annotated := false
+	for {
+		if err := os.WriteFile(path, buffer, 0o600); err != nil {
+			return false, err
+		}
+		if err := launch(path); err != nil {
+			return false, err
+		}
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		if IsEmpty(edited) || (annotated && bytes.Equal(edited, buffer)) {
+			return false, ErrCancelled
+		}
+
+		updated, errs := Parse(edited, contact, schema)
+		if len(errs) > 0 {
+			fmt.Fprintln(out, "The contact has errors; reopening the editor:")
+			for _, err := range errs {
+				fmt.Fprintln(out, "  "+err.Error())
+			}
+			buffer, annotated = Annotate(edited, errs), true
+			continue
+		}
+
+		diff := Diff(contact, updated, schema)
+		if len(diff) == 0 {
+			return false, nil
+		}
+		for _, line := range diff {
+			fmt.Fprintln(out, line)
+		}
+		if err := ab.UpdateContact(updated); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
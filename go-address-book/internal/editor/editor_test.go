@@ -0,0 +1,231 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+var testSchema = models.Schema{
+	{Name: "Tier", Type: models.FieldEnum, Options: []string{"gold", "silver"}},
+	{Name: "Account Manager", Type: models.FieldString},
+}
+
+func testContact() *models.Contact {
+	c := models.NewContact("John", "Smith", "john@example.com", "555-0100", "1 Main St, Dallas, TX")
+	c.Tags = []string{"vendor", "vip"}
+	c.OtherAddresses = []string{"2 Side St, Austin, TX", "3 Back Rd, Waco, TX"}
+	c.SetDate(models.ImportantDate{Label: models.LabelBirthday, Year: 1980, Month: 1, Day: 5})
+	c.SetDate(models.ImportantDate{Label: "graduation", Year: 2002, Month: 6, Day: 1})
+	c.SetCustom("Tier", "gold")
+	return c
+}
+
+// replaceLine swaps the line starting with prefix for line
+func replaceLine(buffer []byte, prefix, line string) []byte {
+	lines := strings.Split(string(buffer), "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			lines[i] = line
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	c := testContact()
+	buffer := Marshal(c, testSchema)
+	for _, want := range []string{
+		"tags: vendor, vip\n",
+		"other-addresses: 2 Side St, Austin, TX; 3 Back Rd, Waco, TX\n",
+		"birthday: 1980-01-05\n",
+		"dates: graduation=2002-06-01\n",
+		"[custom]\n# Tier: gold/silver\nTier: gold\nAccount Manager:\n",
+	} {
+		if !bytes.Contains(buffer, []byte(want)) {
+			t.Errorf("Expected the buffer to contain %q, got:\n%s", want, buffer)
+		}
+	}
+
+	parsed, errs := Parse(buffer, c, testSchema)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if diff := Diff(c, parsed, testSchema); len(diff) > 0 {
+		t.Errorf("Expected no changes, got %v", diff)
+	}
+	if parsed.ID != c.ID || parsed.Version != c.Version {
+		t.Error("Expected the ID and version to be kept")
+	}
+}
+
+func TestParseClearsFields(t *testing.T) {
+	c := testContact()
+	buffer := Marshal(c, testSchema)
+	buffer = replaceLine(buffer, "email:", "email:")
+	buffer = replaceLine(buffer, "birthday:", "")   // deleting a line clears the field too
+	buffer = replaceLine(buffer, "Tier:", "TIER: ") // keys are case-insensitive
+	buffer = replaceLine(buffer, "Account Manager:", "Account Manager: Pat Lee")
+
+	parsed, errs := Parse(buffer, c, testSchema)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if parsed.Email != "" || parsed.Custom["Tier"] != "" {
+		t.Errorf("Expected email and tier cleared, got %q %q", parsed.Email, parsed.Custom["Tier"])
+	}
+	if _, ok := parsed.Date(models.LabelBirthday); ok {
+		t.Error("Expected the birthday removed")
+	}
+	if _, ok := parsed.Date("graduation"); !ok {
+		t.Error("Expected other dates kept")
+	}
+
+	want := []string{
+		"- email: john@example.com",
+		"- birthday: 1980-01-05",
+		"- Tier: gold",
+		"+ Account Manager: Pat Lee",
+	}
+	if diff := Diff(c, parsed, testSchema); strings.Join(diff, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff = %q, want %q", diff, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	c := testContact()
+	buffer := Marshal(c, testSchema)
+	buffer = replaceLine(buffer, "first:", "first:")
+	buffer = replaceLine(buffer, "last:", "last")
+	buffer = replaceLine(buffer, "phone:", "fax: 555-0199")
+	buffer = replaceLine(buffer, "birthday:", "birthday: 13-45")
+	buffer = replaceLine(buffer, "Tier:", "Tier: bronze")
+	buffer = append(buffer, "email: again@example.com\n"...)
+
+	_, errs := Parse(buffer, c, testSchema)
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Msg)
+	}
+	want := []string{
+		"a first or last name is required",
+		`expected "field: value"`,
+		`unknown field "fax"`,
+		"",
+		"",
+		`unknown custom field "email"`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d errors, got %q", len(want), got)
+	}
+	for i := range want {
+		if want[i] != "" && got[i] != want[i] {
+			t.Errorf("Error %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// Errors go above their lines and replace those of the last attempt
+	annotated := Annotate(buffer, errs)
+	_, again := Parse(annotated, c, testSchema)
+	annotated = Annotate(annotated, again)
+	if n := bytes.Count(annotated, []byte(errorPrefix)); n != len(errs) {
+		t.Errorf("Expected %d error comments, got %d:\n%s", len(errs), n, annotated)
+	}
+	if !bytes.Contains(annotated, []byte(errorPrefix+`unknown field "fax"`+"\nfax: 555-0199\n")) {
+		t.Errorf("Expected the error above its line, got:\n%s", annotated)
+	}
+	if !bytes.Contains(annotated, []byte(errorPrefix+"a first or last name is required\nfirst:\n")) {
+		t.Errorf("Expected the name error above the first name, got:\n%s", annotated)
+	}
+}
+
+func TestDuplicateField(t *testing.T) {
+	c := testContact()
+	buffer := append(Marshal(c, nil), "Email: second@example.com\n"...)
+	_, errs := Parse(buffer, c, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0].Msg, "already set on line") {
+		t.Errorf("Expected a duplicate field error, got %v", errs)
+	}
+}
+
+// editWith returns a launcher that rewrites the buffer with each edit in turn
+func editWith(t *testing.T, edits ...func([]byte) []byte) (Launcher, *[][]byte) {
+	var seen [][]byte
+	return func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		seen = append(seen, data)
+		if len(seen) > len(edits) {
+			t.Fatal("Editor opened too many times")
+		}
+		return os.WriteFile(path, edits[len(seen)-1](data), 0o600)
+	}, &seen
+}
+
+func testBook(t *testing.T) (*models.AddressBook, string) {
+	t.Helper()
+	ab := models.NewAddressBook()
+	if err := ab.SetSchema(testSchema); err != nil {
+		t.Fatal(err)
+	}
+	c := testContact()
+	if err := ab.AddContact(c); err != nil {
+		t.Fatal(err)
+	}
+	return ab, c.ID
+}
+
+func TestEdit(t *testing.T) {
+	ab, id := testBook(t)
+	launch, seen := editWith(t,
+		func(b []byte) []byte { return replaceLine(b, "Tier:", "Tier: bronze") },
+		func(b []byte) []byte { return replaceLine(b, "Tier:", "Tier: silver") },
+	)
+	var out bytes.Buffer
+	changed, err := Edit(ab, id, launch, &out)
+	if err != nil || !changed {
+		t.Fatalf("Edit = %v, %v", changed, err)
+	}
+	if len(*seen) != 2 || !bytes.Contains((*seen)[1], []byte(errorPrefix)) {
+		t.Error("Expected the editor to reopen with the error")
+	}
+	if !strings.Contains(out.String(), "- Tier: gold\n+ Tier: silver\n") {
+		t.Errorf("Expected the diff to be shown, got:\n%s", out.String())
+	}
+	stored, _ := ab.GetContact(id)
+	if stored.Custom["Tier"] != "silver" || stored.Version != 2 {
+		t.Errorf("Expected the update saved, got %v version %d", stored.Custom, stored.Version)
+	}
+}
+
+func TestEditCancelled(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits []func([]byte) []byte
+		err   error
+	}{
+		{"unchanged", []func([]byte) []byte{func(b []byte) []byte { return b }}, nil},
+		{"emptied", []func([]byte) []byte{func([]byte) []byte { return nil }}, ErrCancelled},
+		{"errors left", []func([]byte) []byte{
+			func(b []byte) []byte { return replaceLine(b, "birthday:", "birthday: soon") },
+			func(b []byte) []byte { return b },
+		}, ErrCancelled},
+	}
+	for _, tt := range tests {
+		ab, id := testBook(t)
+		launch, _ := editWith(t, tt.edits...)
+		changed, err := Edit(ab, id, launch, &bytes.Buffer{})
+		if changed || !errors.Is(err, tt.err) {
+			t.Errorf("%s: Edit = %v, %v; want false, %v", tt.name, changed, err, tt.err)
+		}
+		if stored, _ := ab.GetContact(id); stored.Version != 1 {
+			t.Errorf("%s: expected no update", tt.name)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Column is one field of a contact that can be output
+type Column struct {
+	Name   string // as given to --columns
+	Header string // heading in tables and CSV files
+	Key    string // key in JSON and YAML, matching Contact's JSON field names
+
+	text  func(c *models.Contact) string
+	value func(c *models.Contact) any // for JSON and YAML; text when nil
+}
+
+// Text returns the column's value as a single line of text. Lists are
+// joined with ", ".
+func (col *Column) Text(c *models.Contact) string {
+	return col.text(c)
+}
+
+// Value returns the column's value for JSON and YAML, where lists stay lists
+// and numbers stay numbers. Missing values are nil.
+func (col *Column) Value(c *models.Contact) any {
+	if col.value != nil {
+		return col.value(c)
+	}
+	if text := col.text(c); text != "" {
+		return text
+	}
+	return nil
+}
+
+func textColumn(name, header, key string, text func(c *models.Contact) string) *Column {
+	return &Column{Name: name, Header: header, Key: key, text: text}
+}
+
+func listColumn(name, header, key string, list func(c *models.Contact) []string) *Column {
+	return &Column{
+		Name: name, Header: header, Key: key,
+		text: func(c *models.Contact) string { return strings.Join(list(c), ", ") },
+		value: func(c *models.Contact) any {
+			if values := list(c); len(values) > 0 {
+				return values
+			}
+			return nil
+		},
+	}
+}
+
+func timeColumn(name, header, key string, at func(c *models.Contact) (time.Time, bool)) *Column {
+	return &Column{
+		Name: name, Header: header, Key: key,
+		text: func(c *models.Contact) string {
+			if t, ok := at(c); ok {
+				return t.Format(time.RFC3339)
+			}
+			return ""
+		},
+	}
+}
+
+func always(t time.Time) (time.Time, bool) {
+	return t, !t.IsZero()
+}
+
+// builtinColumns are the columns every contact has
+var builtinColumns = []*Column{
+	textColumn("id", "ID", "id", func(c *models.Contact) string { return c.ID }),
+	textColumn("first", "First Name", "firstName", func(c *models.Contact) string { return c.FirstName }),
+	textColumn("last", "Last Name", "lastName", func(c *models.Contact) string { return c.LastName }),
+	textColumn("name", "Name", "name", func(c *models.Contact) string {
+		return strings.TrimSpace(c.FirstName + " " + c.LastName)
+	}),
+	textColumn("email", "Email", "email", func(c *models.Contact) string { return c.Email }),
+	textColumn("phone", "Phone", "phone", func(c *models.Contact) string { return c.Phone }),
+	textColumn("address", "Address", "address", func(c *models.Contact) string { return c.Address }),
+	textColumn("city", "City", "city", func(c *models.Contact) string { return c.City() }),
+	textColumn("org", "Organization", "organization", func(c *models.Contact) string { return c.Organization }),
+	textColumn("company", "Company", "company", func(c *models.Contact) string { return c.Company() }),
+	textColumn("title", "Title", "title", func(c *models.Contact) string { return c.Title }),
+	textColumn("dept", "Department", "department", func(c *models.Contact) string { return c.Department }),
+	listColumn("tags", "Tags", "tags", func(c *models.Contact) []string { return c.Tags }),
+	listColumn("other-emails", "Other Emails", "otherEmails", func(c *models.Contact) []string { return c.OtherEmails }),
+	listColumn("other-phones", "Other Phones", "otherPhones", func(c *models.Contact) []string { return c.OtherPhones }),
+	listColumn("other-addresses", "Other Addresses", "otherAddresses", func(c *models.Contact) []string { return c.OtherAddresses }),
+	{
+		Name: "dates", Header: "Dates", Key: "dates",
+		text: func(c *models.Contact) string { return models.FormatDates(c.Dates) },
+		value: func(c *models.Contact) any {
+			if len(c.Dates) == 0 {
+				return nil
+			}
+			dates := make(object, len(c.Dates))
+			for i, date := range c.Dates {
+				dates[i] = pair{date.Label, date.String()}
+			}
+			return dates
+		},
+	},
+	{
+		Name: "notes", Header: "Notes", Key: "notes",
+		text:  func(c *models.Contact) string { return strconv.Itoa(len(c.Interactions)) },
+		value: func(c *models.Contact) any { return len(c.Interactions) },
+	},
+	listColumn("aliases", "Merged From", "aliases", func(c *models.Contact) []string { return c.Aliases }),
+	{
+		Name: "version", Header: "Version", Key: "version",
+		text:  func(c *models.Contact) string { return strconv.Itoa(c.Version) },
+		value: func(c *models.Contact) any { return c.Version },
+	},
+	timeColumn("created", "Created", "createdAt", func(c *models.Contact) (time.Time, bool) { return always(c.CreatedAt) }),
+	timeColumn("updated", "Updated", "updatedAt", func(c *models.Contact) (time.Time, bool) { return always(c.UpdatedAt) }),
+	timeColumn("contacted", "Last Contacted", "lastContacted", func(c *models.Contact) (time.Time, bool) { return c.LastContacted() }),
+}
+
+// columnAliases are other accepted spellings of column names
+var columnAliases = map[string]string{
+	"firstname": "first", "lastname": "last", "organization": "org", "department": "dept",
+	"emails": "other-emails", "phones": "other-phones", "addresses": "other-addresses",
+}
+
+// customColumn outputs a custom field. Number fields are numbers in JSON
+// and YAML.
+func customColumn(def models.FieldDef) *Column {
+	name := def.Name
+	col := textColumn(name, name, name, func(c *models.Contact) string { return c.Custom[name] })
+	if def.Type == models.FieldNumber {
+		col.value = func(c *models.Contact) any {
+			value := c.Custom[name]
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				return json.Number(value)
+			}
+			if value == "" {
+				return nil
+			}
+			return value
+		}
+	}
+	return col
+}
+
+// ColumnNames lists the builtin column names, for help text
+func ColumnNames() []string {
+	names := make([]string, len(builtinColumns))
+	for i, col := range builtinColumns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// AllColumns returns every builtin column followed by the schema's custom fields
+func AllColumns(schema models.Schema) []*Column {
+	columns := append([]*Column(nil), builtinColumns...)
+	for _, def := range schema {
+		columns = append(columns, customColumn(def))
+	}
+	return columns
+}
+
+// ParseColumns parses a comma-separated list of column names such as
+// "first,last,email". Names are those listed by ColumnNames plus the custom
+// fields in schema, ignoring case.
+func ParseColumns(spec string, schema models.Schema) ([]*Column, error) {
+	var columns []*Column
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := lookupColumn(name, schema)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q; columns are %s and custom fields",
+				name, strings.Join(ColumnNames(), ", "))
+		}
+		columns = append(columns, col)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return columns, nil
+}
+
+func lookupColumn(name string, schema models.Schema) (*Column, bool) {
+	lower := strings.ToLower(name)
+	if canonical, ok := columnAliases[lower]; ok {
+		lower = canonical
+	}
+	for _, col := range builtinColumns {
+		if col.Name == lower {
+			return col, true
+		}
+	}
+	for _, def := range schema {
+		if strings.EqualFold(def.Name, name) {
+			return customColumn(def), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,386 @@
+// Package format writes contacts as tables, JSON, NDJSON, CSV, YAML, vCards
+// or through a Go template, so results can be read by people, jq,
+// spreadsheets and phones alike.
+package format
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/width"
+
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/vcard"
+)
+
+// Formats lists the output formats New accepts
+var Formats = []string{"text", "table", "json", "ndjson", "csv", "yaml", "vcf", "template"}
+
+// DefaultTableColumns are the columns a table shows unless told otherwise
+const DefaultTableColumns = "id,name,email,phone,org"
+
+// Options selects an output format
+type Options struct {
+	Format   string    // one of Formats
+	Columns  []*Column // nil for the format's defaults
+	Template string    // Go template for the template format
+	Schema   models.Schema
+	VCard    string // vCard version for the vcf format; "" for vcard.DefaultVersion
+}
+
+// Writer writes contacts one at a time. Close must be called after the last
+// contact, as tables are aligned and JSON arrays closed only then.
+type Writer interface {
+	Write(c *models.Contact) error
+	Close() error
+}
+
+// New returns a writer for the chosen format. Without columns, text shows
+// every field that has a value, tables the DefaultTableColumns, CSV and
+// templates every column, and JSON, NDJSON and YAML the whole contact as
+// Contact.ToJSON encodes it. The vcf format ignores columns.
+func New(w io.Writer, opts Options) (Writer, error) {
+	columns := opts.Columns
+	switch opts.Format {
+	case "", "text":
+		return &textWriter{w: bufio.NewWriter(w), columns: columns, schema: opts.Schema}, nil
+	case "table":
+		if columns == nil {
+			columns, _ = ParseColumns(DefaultTableColumns, nil)
+		}
+		return newTableWriter(w, columns), nil
+	case "json":
+		return &jsonWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case "ndjson":
+		return &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case "csv":
+		if columns == nil {
+			columns = AllColumns(opts.Schema)
+		}
+		return newCSVWriter(w, columns), nil
+	case "yaml":
+		return &yamlWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case "vcf":
+		return vcard.NewWriter(w, opts.VCard)
+	case "template":
+		if columns == nil {
+			columns = AllColumns(opts.Schema)
+		}
+		return newTemplateWriter(w, columns, opts.Template)
+	}
+	return nil, fmt.Errorf("unknown output format %q; formats are %s", opts.Format, strings.Join(Formats, ", "))
+}
+
+// record returns a contact as an ordered object: the selected columns, or
+// the whole contact when there are none
+func record(c *models.Contact, columns []*Column) (any, error) {
+	if columns == nil {
+		data, err := c.ToJSON()
+		if err != nil {
+			return nil, err
+		}
+		return decodeOrdered([]byte(data))
+	}
+	obj := make(object, len(columns))
+	for i, col := range columns {
+		obj[i] = pair{col.Key, col.Value(c)}
+	}
+	return obj, nil
+}
+
+// textWriter writes "Header: value" lines, one block per contact
+type textWriter struct {
+	w       *bufio.Writer
+	columns []*Column
+	schema  models.Schema
+}
+
+func (t *textWriter) Write(c *models.Contact) error {
+	columns := t.columns
+	if columns == nil {
+		columns = AllColumns(t.schema)
+	}
+	fmt.Fprintln(t.w)
+	for _, col := range columns {
+		value := col.Text(c)
+		if t.columns == nil && (value == "" || (col.Name == "notes" || col.Name == "version") && value == "0") {
+			continue
+		}
+		fmt.Fprintf(t.w, "%s: %s\n", col.Header, value)
+	}
+	return nil
+}
+
+func (t *textWriter) Close() error {
+	return t.w.Flush()
+}
+
+// tableWriter aligns columns under a header row. Cells in right-to-left
+// scripts such as Arabic and Hebrew are wrapped in Unicode directional
+// isolates, so a terminal that reorders bidirectional text keeps each cell
+// in its column and the columns in order.
+type tableWriter struct {
+	w       io.Writer
+	columns []*Column
+	rows    [][]string
+}
+
+// tablePadding is the space between columns
+const tablePadding = 2
+
+func newTableWriter(w io.Writer, columns []*Column) *tableWriter {
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = strings.ToUpper(col.Header)
+	}
+	return &tableWriter{w: w, columns: columns, rows: [][]string{headers}}
+}
+
+func (t *tableWriter) Write(c *models.Contact) error {
+	cells := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		// Tabs and newlines would break the alignment
+		cells[i] = isolate(strings.NewReplacer("\t", " ", "\n", " ").Replace(col.Text(c)))
+	}
+	t.rows = append(t.rows, cells)
+	return nil
+}
+
+func (t *tableWriter) Close() error {
+	widths := make([]int, len(t.columns))
+	for _, row := range t.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	w := bufio.NewWriter(t.w)
+	for _, row := range t.rows {
+		for i, cell := range row {
+			w.WriteString(cell)
+			if i < len(row)-1 {
+				w.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+tablePadding))
+			}
+		}
+		w.WriteByte('\n')
+	}
+	return w.Flush()
+}
+
+// isolate wraps text containing right-to-left characters in a first strong
+// isolate and a pop directional isolate, so it cannot reorder the text
+// around it
+func isolate(text string) string {
+	for _, r := range text {
+		if props, _ := bidi.LookupRune(r); props.Class() == bidi.R || props.Class() == bidi.AL {
+			return "\u2068" + text + "\u2069"
+		}
+	}
+	return text
+}
+
+// displayWidth returns how many terminal columns text takes: combining marks
+// and invisible formatting characters such as the isolates take none, and
+// wide East Asian characters take two
+func displayWidth(text string) int {
+	n := 0
+	for _, r := range text {
+		switch kind := width.LookupRune(r).Kind(); {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case kind == width.EastAsianWide || kind == width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// jsonWriter writes one indented JSON array
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns []*Column
+	count   int
+}
+
+func (j *jsonWriter) Write(c *models.Contact) error {
+	rec, err := record(c, j.columns)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if j.count == 0 {
+		j.w.WriteString("[\n  ")
+	} else {
+		j.w.WriteString(",\n  ")
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		j.w.WriteString("[]\n")
+	} else {
+		j.w.WriteString("\n]\n")
+	}
+	return j.w.Flush()
+}
+
+// ndjsonWriter writes one JSON object per line
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []*Column
+}
+
+func (n *ndjsonWriter) Write(c *models.Contact) error {
+	var data []byte
+	if n.columns == nil {
+		text, err := c.ToJSON()
+		if err != nil {
+			return err
+		}
+		data = []byte(text)
+	} else {
+		rec, err := record(c, n.columns)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(rec); err != nil {
+			return err
+		}
+	}
+	n.w.Write(data)
+	return n.w.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+// csvWriter writes a header row and one row per contact
+type csvWriter struct {
+	w       *csv.Writer
+	columns []*Column
+	err     error
+}
+
+func newCSVWriter(w io.Writer, columns []*Column) *csvWriter {
+	c := &csvWriter{w: csv.NewWriter(w), columns: columns}
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+	}
+	c.err = c.w.Write(headers)
+	return c
+}
+
+func (c *csvWriter) Write(contact *models.Contact) error {
+	if c.err != nil {
+		return c.err
+	}
+	row := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		row[i] = col.Text(contact)
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// yamlWriter writes a YAML list with one mapping per contact
+type yamlWriter struct {
+	w       *bufio.Writer
+	columns []*Column
+	count   int
+}
+
+func (y *yamlWriter) Write(c *models.Contact) error {
+	rec, err := record(c, y.columns)
+	if err != nil {
+		return err
+	}
+	y.count++
+	y.w.WriteString("- ")
+	return writeYAML(y.w, rec, 1)
+}
+
+func (y *yamlWriter) Close() error {
+	if y.count == 0 {
+		y.w.WriteString("[]\n")
+	}
+	return y.w.Flush()
+}
+
+// templateWriter executes a Go template once per contact. The template sees
+// a map from column name to text, so {{.first}} or {{index . "Account
+// Manager"}}, and a newline is added unless the template ends with one.
+type templateWriter struct {
+	w       *bufio.Writer
+	tmpl    *template.Template
+	columns []*Column
+	newline bool
+}
+
+func newTemplateWriter(w io.Writer, columns []*Column, text string) (*templateWriter, error) {
+	if text == "" {
+		return nil, errors.New("the template format needs a template, such as '{{.first}} {{.last}} <{{.email}}>'")
+	}
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"join":  strings.Join,
+		"split": strings.Split,
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &templateWriter{w: bufio.NewWriter(w), tmpl: tmpl, columns: columns, newline: !strings.HasSuffix(text, "\n")}, nil
+}
+
+func (t *templateWriter) Write(c *models.Contact) error {
+	data := make(map[string]string, len(t.columns))
+	for _, col := range t.columns {
+		data[col.Name] = col.Text(c)
+	}
+	if err := t.tmpl.Execute(t.w, data); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	if t.newline {
+		return t.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (t *templateWriter) Close() error {
+	return t.w.Flush()
+}
+
+// WriteAll writes contacts with a new writer and closes it
+func WriteAll(w io.Writer, opts Options, contacts []*models.Contact) error {
+	out, err := New(w, opts)
+	if err != nil {
+		return err
+	}
+	for _, c := range contacts {
+		if err := out.Write(c); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,255 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+var testSchema = models.Schema{
+	{Name: "Score", Type: models.FieldNumber},
+	{Name: "Account Manager", Type: models.FieldString},
+}
+
+func testContacts() []*models.Contact {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	john := models.NewContact("John", "Smith", "john@example.com", "555-0100", "1 Main St, Dallas, TX 75001")
+	john.ID = "01HX0000000000000000000001"
+	john.Tags = []string{"vendor", "vip"}
+	john.Custom = map[string]string{"Score": "7", "Account Manager": "Pat: Lee"}
+	john.CreatedAt, john.UpdatedAt = at, at
+
+	jane := models.NewContact("Jane", "O'Neil", "", "", "")
+	jane.ID = "01HX0000000000000000000002"
+	jane.CreatedAt, jane.UpdatedAt = at, at
+	return []*models.Contact{john, jane}
+}
+
+func render(t *testing.T, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteAll(&buf, opts, testContacts()); err != nil {
+		t.Fatalf("WriteAll(%s) failed: %v", opts.Format, err)
+	}
+	return buf.String()
+}
+
+func columns(t *testing.T, spec string) []*Column {
+	t.Helper()
+	cols, err := ParseColumns(spec, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cols
+}
+
+func TestTable(t *testing.T) {
+	got := render(t, Options{Format: "table", Columns: columns(t, "first,last,tags,score")})
+	want := "FIRST NAME  LAST NAME  TAGS         SCORE\n" +
+		"John        Smith      vendor, vip  7\n" +
+		"Jane        O'Neil                  \n"
+	if got != want {
+		t.Errorf("Table =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableRightToLeft(t *testing.T) {
+	arabic := models.NewContact("ليلى", "حداد", "layla@example.com", "", "")
+	japanese := models.NewContact("太郎", "山田", "taro@example.com", "", "")
+	var buf bytes.Buffer
+	opts := Options{Format: "table", Columns: columns(t, "first,last,email")}
+	if err := WriteAll(&buf, opts, []*models.Contact{arabic, japanese}); err != nil {
+		t.Fatal(err)
+	}
+	want := "FIRST NAME  LAST NAME  EMAIL\n" +
+		"\u2068ليلى\u2069        \u2068حداد\u2069       layla@example.com\n" +
+		"太郎        山田       taro@example.com\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Table =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	got := render(t, Options{Format: "json", Columns: columns(t, "id,name,tags,score,account manager")})
+	want := `[
+  {
+    "id": "01HX0000000000000000000001",
+    "name": "John Smith",
+    "tags": [
+      "vendor",
+      "vip"
+    ],
+    "Score": 7,
+    "Account Manager": "Pat: Lee"
+  },
+  {
+    "id": "01HX0000000000000000000002",
+    "name": "Jane O'Neil",
+    "tags": null,
+    "Score": null,
+    "Account Manager": null
+  }
+]
+`
+	if got != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := render(t, Options{Format: "json", Columns: columns(t, "id")}); !json.Valid([]byte(got)) {
+		t.Errorf("Expected valid JSON, got %s", got)
+	}
+	var buf bytes.Buffer
+	if err := WriteAll(&buf, Options{Format: "json"}, nil); err != nil || buf.String() != "[]\n" {
+		t.Errorf("Expected an empty array, got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestNDJSONWholeContact(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(render(t, Options{Format: "ndjson"}), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per contact, got %d", len(lines))
+	}
+	want, _ := testContacts()[0].ToJSON()
+	if lines[0] != want {
+		t.Errorf("Expected the line to be Contact.ToJSON\n got %s\nwant %s", lines[0], want)
+	}
+	parsed, err := models.FromJSON(lines[1])
+	if err != nil || parsed.LastName != "O'Neil" {
+		t.Errorf("Expected the line to parse back, got %v (%v)", parsed, err)
+	}
+
+	got := render(t, Options{Format: "ndjson", Columns: columns(t, "email,first")})
+	if want := "{\"email\":\"john@example.com\",\"firstName\":\"John\"}\n{\"email\":null,\"firstName\":\"Jane\"}\n"; got != want {
+		t.Errorf("NDJSON columns =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	got := render(t, Options{Format: "csv", Columns: columns(t, "last,address,Account Manager")})
+	rows, err := csv.NewReader(strings.NewReader(got)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Last Name", "Address", "Account Manager"},
+		{"Smith", "1 Main St, Dallas, TX 75001", "Pat: Lee"},
+		{"O'Neil", "", ""},
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("Row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+
+	all := render(t, Options{Format: "csv", Schema: testSchema})
+	header := strings.SplitN(all, "\n", 2)[0]
+	if !strings.HasPrefix(header, "ID,First Name,Last Name") || !strings.HasSuffix(header, "Score,Account Manager") {
+		t.Errorf("Expected every column by default, got %s", header)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	got := render(t, Options{Format: "yaml", Columns: columns(t, "first,last,tags,score,account manager,dates")})
+	want := `- firstName: John
+  lastName: Smith
+  tags:
+    - vendor
+    - vip
+  Score: 7
+  Account Manager: "Pat: Lee"
+  dates: null
+- firstName: Jane
+  lastName: O'Neil
+  tags: null
+  Score: null
+  Account Manager: null
+  dates: null
+`
+	if got != want {
+		t.Errorf("YAML =\n%s\nwant\n%s", got, want)
+	}
+
+	whole := render(t, Options{Format: "yaml"})
+	for _, line := range []string{"- id: 01HX0000000000000000000001", "  custom:", "    Score: \"7\"", "  version: 0"} {
+		if !strings.Contains(whole, line+"\n") {
+			t.Errorf("Expected the whole contact to contain %q, got\n%s", line, whole)
+		}
+	}
+}
+
+func TestYAMLScalars(t *testing.T) {
+	tests := map[string]string{
+		"plain":    "plain",
+		"":         `""`,
+		"yes":      `"yes"`,
+		"42":       `"42"`,
+		"- dash":   `"- dash"`,
+		"a: b":     `"a: b"`,
+		" padded":  `" padded"`,
+		"line\nx":  `"line\nx"`,
+		"O'Neil":   "O'Neil",
+		"a@b.com":  "a@b.com",
+		"x # note": `"x # note"`,
+	}
+	for in, want := range tests {
+		if got := yamlScalar(in); got != want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	got := render(t, Options{Format: "template", Template: `{{.first}} {{upper .last}} <{{.email}}> {{index . "Account Manager"}}`, Schema: testSchema})
+	want := "John SMITH <john@example.com> Pat: Lee\nJane O'NEIL <> \n"
+	if got != want {
+		t.Errorf("Template =\n%q\nwant\n%q", got, want)
+	}
+
+	// Only the selected columns are available
+	var buf bytes.Buffer
+	err := WriteAll(&buf, Options{Format: "template", Template: "{{.email}}", Columns: columns(t, "first")}, testContacts())
+	if err == nil {
+		t.Error("Expected a template using an unselected column to fail")
+	}
+	if _, err := New(&buf, Options{Format: "template"}); err == nil {
+		t.Error("Expected the template format to need a template")
+	}
+	if _, err := New(&buf, Options{Format: "template", Template: "{{.first"}); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+}
+
+func TestText(t *testing.T) {
+	got := render(t, Options{Format: "text", Columns: columns(t, "name,email")})
+	want := "\nName: John Smith\nEmail: john@example.com\n\nName: Jane O'Neil\nEmail: \n"
+	if got != want {
+		t.Errorf("Text =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	cols, err := ParseColumns("First, LASTNAME,score", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
+	if strings.Join(names, ",") != "first,last,Score" {
+		t.Errorf("Expected canonical names, got %v", names)
+	}
+	for _, spec := range []string{"bogus", "", ","} {
+		if _, err := ParseColumns(spec, testSchema); err == nil {
+			t.Errorf("Expected ParseColumns(%q) to fail", spec)
+		}
+	}
+	if _, err := New(&bytes.Buffer{}, Options{Format: "xml"}); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
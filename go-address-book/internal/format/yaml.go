@@ -0,0 +1,227 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pair is one key and value of an object
+type pair struct {
+	key   string
+	value any
+}
+
+// object is a JSON object that keeps its keys in order, so output follows
+// the column order rather than the alphabet
+type object []pair
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(p.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered decodes JSON into objects, []any, json.Number, string, bool
+// and nil, keeping the order of object keys
+func decodeOrdered(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, pair{key.(string), value})
+		}
+		_, err = dec.Token() // '}'
+		return obj, err
+	case '[':
+		list := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token() // ']'
+		return list, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// writeYAML writes a value decoded by decodeOrdered, or built from objects
+// and slices, as block-style YAML at the given indent
+func writeYAML(w io.Writer, value any, indent int) error {
+	pad := strings.Repeat("  ", indent)
+	switch v := value.(type) {
+	case object:
+		if len(v) == 0 {
+			_, err := fmt.Fprintln(w, "{}")
+			return err
+		}
+		for i, p := range v {
+			prefix := pad
+			if i == 0 {
+				prefix = "" // continues the line of a list item or key
+			}
+			if err := writeYAMLEntry(w, prefix+yamlScalar(p.key)+":", p.value, indent); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		return writeYAMLList(w, v, indent)
+	case []string:
+		list := make([]any, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return writeYAMLList(w, list, indent)
+	}
+	_, err := fmt.Fprintln(w, yamlScalar(value))
+	return err
+}
+
+func writeYAMLList(w io.Writer, list []any, indent int) error {
+	if len(list) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	pad := strings.Repeat("  ", indent)
+	for i, item := range list {
+		prefix := pad
+		if i == 0 {
+			prefix = ""
+		}
+		if _, err := fmt.Fprint(w, prefix+"- "); err != nil {
+			return err
+		}
+		if err := writeYAML(w, item, indent+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAMLEntry writes "key:" followed by the value, nesting collections
+// on the following lines
+func writeYAMLEntry(w io.Writer, key string, value any, indent int) error {
+	pad := strings.Repeat("  ", indent+1)
+	switch v := value.(type) {
+	case object:
+		if len(v) > 0 {
+			if _, err := fmt.Fprint(w, key+"\n"+pad); err != nil {
+				return err
+			}
+			return writeYAML(w, v, indent+1)
+		}
+	case []any, []string:
+		if n := yamlLen(v); n > 0 {
+			if _, err := fmt.Fprint(w, key+"\n"+pad); err != nil {
+				return err
+			}
+			return writeYAML(w, v, indent+1)
+		}
+	}
+	if _, err := fmt.Fprint(w, key+" "); err != nil {
+		return err
+	}
+	return writeYAML(w, value, indent+1)
+}
+
+func yamlLen(value any) int {
+	switch v := value.(type) {
+	case []any:
+		return len(v)
+	case []string:
+		return len(v)
+	}
+	return 0
+}
+
+// yamlScalar formats a single value, quoting strings YAML would otherwise
+// read as something else
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		if yamlNeedsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,10 @@ type Generator struct {
 	firstNames []string
 	lastNames  []string
 	domains    []string
+	companies  []string
+	titles     []string
+	depts      []string
+	tags       []string
 }
 
 // NewGenerator creates a new generator instance
@@ -30,6 +34,18 @@ func NewGenerator() *Generator {
 		domains: []string{
 			"gmail.com", "yahoo.com", "hotmail.com", "outlook.com", "example.com",
 		},
+		companies: []string{
+			"Acme", "Globex", "Initech", "Umbrella", "Stark Industries", "Wayne Enterprises",
+		},
+		titles: []string{
+			"Engineer", "Senior Engineer", "Manager", "Director", "Analyst", "Designer",
+		},
+		depts: []string{
+			"Engineering", "Sales", "Marketing", "Finance", "Support",
+		},
+		tags: []string{
+			"client", "family", "friend", "vendor", "vip",
+		},
 	}
 }
 
@@ -42,7 +58,20 @@ func (g *Generator) GenerateContact() *models.Contact {
 	phone := g.generatePhoneNumber()
 	address := g.generateAddress()
 
-	return models.NewContact(firstName, lastName, email, phone, address)
+	contact := models.NewContact(firstName, lastName, email, phone, address)
+	// Leave some contacts without an organization so the email fallback gets exercised
+	if rand.Intn(3) > 0 {
+		contact.Organization = g.companies[rand.Intn(len(g.companies))]
+		contact.Title = g.titles[rand.Intn(len(g.titles))]
+		contact.Department = g.depts[rand.Intn(len(g.depts))]
+	}
+	contact.Dates = g.generateDates()
+	for _, tag := range g.tags {
+		if rand.Intn(5) == 0 {
+			contact.Tags = append(contact.Tags, tag)
+		}
+	}
+	return contact
 }
 
 // GenerateContacts generates n random contacts
@@ -66,6 +95,39 @@ func (g *Generator) generatePhoneNumber() string {
 		string(digits[6:]))
 }
 
+// generateDates generates a birthday for most contacts and an anniversary for some.
+// A few birthdays have no year and leap-day birthdays show up occasionally.
+func (g *Generator) generateDates() []models.ImportantDate {
+	var dates []models.ImportantDate
+
+	if rand.Intn(10) < 8 {
+		birthday := randomDate(models.LabelBirthday, 1950, 2005)
+		if rand.Intn(20) == 0 {
+			birthday = models.ImportantDate{Label: models.LabelBirthday, Year: 1996, Month: time.February, Day: 29}
+		}
+		if rand.Intn(10) == 0 {
+			birthday.Year = 0
+		}
+		dates = append(dates, birthday)
+
+		if birthday.Year != 0 && rand.Intn(3) == 0 {
+			earliest := birthday.Year + 20
+			if earliest < 2024 {
+				dates = append(dates, randomDate(models.LabelAnniversary, earliest, 2024))
+			}
+		}
+	}
+	return dates
+}
+
+// randomDate returns a valid date between the given years
+func randomDate(label string, fromYear, toYear int) models.ImportantDate {
+	start := time.Date(fromYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(toYear, time.December, 31, 0, 0, 0, 0, time.UTC)
+	day := start.AddDate(0, 0, rand.Intn(int(end.Sub(start).Hours()/24)+1))
+	return models.ImportantDate{Label: label, Year: day.Year(), Month: day.Month(), Day: day.Day()}
+}
+
 // generateAddress generates a random address
 func (g *Generator) generateAddress() string {
 	streets := []string{
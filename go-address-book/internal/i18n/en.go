@@ -0,0 +1,247 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// english is the default locale, and the catalog every other locale must
+// translate in full
+var english = &locale{
+	tag: language.English,
+	plural: func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	months:    [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	days:      [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	date:      "Jan 2, 2006",
+	dateTime:  "Jan 2, 2006 3:04 PM",
+	monthDay:  "Jan 2",
+	shortDate: "Mon Jan 02",
+	messages: map[string]string{
+		"answer.yes": "y,yes",
+
+		"menu.title":      "Address Book CLI",
+		"menu.prompt":     "Enter your choice (1-%d): ",
+		"menu.invalid":    "Invalid choice. Please try again.",
+		"menu.goodbye":    "Goodbye!",
+		"menu.add":        "Add Contact",
+		"menu.list":       "List Contacts",
+		"menu.search":     "Search Contacts",
+		"menu.update":     "Update Contact",
+		"menu.delete":     "Delete Contact",
+		"menu.generate":   "Generate Test Data",
+		"menu.companies":  "Company View",
+		"menu.company":    "List Company Contacts",
+		"menu.upcoming":   "Upcoming Events",
+		"menu.link":       "Link Contacts",
+		"menu.unlink":     "Unlink Contacts",
+		"menu.reports":    "Show Reporting Chain",
+		"menu.note":       "Add Note or Interaction",
+		"menu.notes":      "List Notes",
+		"menu.stale":      "Find Contacts Not Contacted Recently",
+		"menu.duplicates": "Find Duplicates",
+		"menu.merge":      "Merge Contacts",
+		"menu.edit":       "Edit Contact in Editor",
+		"menu.bulk":       "Bulk Change by Search",
+		"menu.exit":       "Exit",
+
+		"history.error": "Warning: cannot read history: %v",
+		"save.error":    "Error saving address book: %v",
+		"find.error":    "Error finding contact: %v",
+		"contact.id":    "Enter contact ID: ",
+		"days.invalid":  "Invalid number of days.",
+
+		"add.first":   "Enter first name: ",
+		"add.last":    "Enter last name: ",
+		"add.email":   "Enter email: ",
+		"add.phone":   "Enter phone: ",
+		"add.address": "Enter address: ",
+		"add.org":     "Enter organization: ",
+		"add.title":   "Enter job title: ",
+		"add.dept":    "Enter department: ",
+		"add.tags":    "Enter tags (separated by commas): ",
+		"add.custom":  "Enter %s (%s): ",
+		"add.error":   "Error adding contact: %v",
+		"add.done":    "Contact added successfully!",
+
+		"dates.yearly":      "Enter %s (YYYY-MM-DD or MM-DD): ",
+		"dates.yearlyKeep":  "Enter %s (YYYY-MM-DD or MM-DD, or press Enter to keep current): ",
+		"dates.other":       "Enter other dates (label=YYYY-MM-DD, separated by ';'): ",
+		"dates.otherKeep":   "Enter other dates (label=YYYY-MM-DD, separated by ';', or press Enter to keep current): ",
+		"dates.error":       "Error reading date: %v",
+		"label.birthday":    "birthday",
+		"label.anniversary": "anniversary",
+
+		"list.none":  "No contacts found.",
+		"list.title": "Contacts:",
+
+		"search.help":    "Enter words to search for, ~words to forgive typos in names,",
+		"search.prompt":  "or a query such as last:smith -city:dallas OR tag:vendor: ",
+		"search.none":    "No contacts found matching your search.",
+		"search.fuzzy":   "Start the query with ~ to also find misspelled and similar-sounding names.",
+		"search.title":   "Search Results:",
+		"search.invalid": "Invalid query at %v\n  %s",
+		"search.error":   "Error searching contacts: %v",
+
+		"sort.prompt":    "Sort by (e.g. last,first or -created; Enter for %s): ",
+		"sort.name":      "last name, first name",
+		"sort.relevance": "best match first",
+		"sort.invalid":   "Invalid sort order: %v",
+		"sort.error":     "Error sorting contacts: %v",
+
+		"page.more":  "-- %d-%d of %d. Enter for more, q to stop: ",
+		"page.error": "Error paging contacts: %v",
+
+		"update.id":      "Enter contact ID to update: ",
+		"update.current": "Current contact: %s",
+		"update.clear":   "(To clear a field, use %s instead.)",
+		"update.first":   "Enter new first name (or press Enter to keep current): ",
+		"update.last":    "Enter new last name (or press Enter to keep current): ",
+		"update.email":   "Enter new email (or press Enter to keep current): ",
+		"update.phone":   "Enter new phone (or press Enter to keep current): ",
+		"update.address": "Enter new address (or press Enter to keep current): ",
+		"update.org":     "Enter new organization (or press Enter to keep current): ",
+		"update.title":   "Enter new job title (or press Enter to keep current): ",
+		"update.dept":    "Enter new department (or press Enter to keep current): ",
+		"update.tags":    "Enter new tags (separated by commas, or press Enter to keep current): ",
+		"update.custom":  "Enter new %s (%s, or press Enter to keep current): ",
+		"update.error":   "Error updating contact: %v",
+		"update.done":    "Contact updated successfully!",
+
+		"edit.id":        "Enter contact ID to edit: ",
+		"edit.cancelled": "Edit cancelled.",
+		"edit.unchanged": "No changes.",
+		"edit.error":     "Error editing contact: %v",
+
+		"delete.id":        "Enter contact ID to delete: ",
+		"delete.confirm":   "Delete %s (%s)? [y/N]: ",
+		"delete.cancelled": "Cancelled; nothing was deleted.",
+		"delete.error":     "Error deleting contact: %v",
+		"delete.done":      "Contact deleted successfully!",
+
+		"bulk.search":               "Enter a search for the contacts to change: ",
+		"bulk.none":                 "No contacts match.",
+		"bulk.matched.one":          "Matched %d contact:",
+		"bulk.matched.other":        "Matched %d contacts:",
+		"bulk.action":               "Enter action (update/delete/tag/untag): ",
+		"bulk.field":                "Enter field to set (first, last, email, phone, address, org, title, dept, tags, birthday, anniversary or a custom field): ",
+		"bulk.value":                "Enter new value (or press Enter to clear it): ",
+		"bulk.unknownField":         "Unknown field %q.",
+		"bulk.noTags":               "No tags given.",
+		"bulk.invalidAction":        "Invalid action.",
+		"bulk.confirm.update.one":   "Update %d contact? [y/N]: ",
+		"bulk.confirm.update.other": "Update %d contacts? [y/N]: ",
+		"bulk.confirm.delete.one":   "Delete %d contact? [y/N]: ",
+		"bulk.confirm.delete.other": "Delete %d contacts? [y/N]: ",
+		"bulk.confirm.tag.one":      "Tag %d contact? [y/N]: ",
+		"bulk.confirm.tag.other":    "Tag %d contacts? [y/N]: ",
+		"bulk.confirm.untag.one":    "Untag %d contact? [y/N]: ",
+		"bulk.confirm.untag.other":  "Untag %d contacts? [y/N]: ",
+		"bulk.cancelled":            "Cancelled; nothing was changed.",
+		"bulk.deleteError":          "Error deleting contacts: %v",
+		"bulk.deleted.one":          "Deleted %d contact.",
+		"bulk.deleted.other":        "Deleted %d contacts.",
+		"bulk.updateError":          "Error updating contacts: %v",
+		"bulk.updated.one":          "Updated %d contact (%d already up to date).",
+		"bulk.updated.other":        "Updated %d contacts (%d already up to date).",
+		"preview.more.one":          "... and %d more",
+		"preview.more.other":        "... and %d more",
+
+		"generate.error":      "Error adding test contacts: %v",
+		"generate.done.one":   "Generated %d test contact successfully!",
+		"generate.done.other": "Generated %d test contacts successfully!",
+
+		"upcoming.days":        "Enter number of days to look ahead (default 30): ",
+		"upcoming.none.one":    "No events in the next %d day.",
+		"upcoming.none.other":  "No events in the next %d days.",
+		"upcoming.title.one":   "Upcoming events in the next %d day:",
+		"upcoming.title.other": "Upcoming events in the next %d days:",
+		"upcoming.turns.one":   "(turns %d)",
+		"upcoming.turns.other": "(turns %d)",
+		"upcoming.years.one":   "(%d year)",
+		"upcoming.years.other": "(%d years)",
+
+		"link.relation":      "Enter relation (%s): ",
+		"link.relationError": "Error reading relation: %v",
+		"link.to":            "Enter ID of the contact's %s: ",
+		"link.error":         "Error linking contacts: %v",
+		"link.done":          "Contacts linked successfully!",
+		"unlink.error":       "Error unlinking contacts: %v",
+		"unlink.done":        "Contacts unlinked successfully!",
+
+		"relation.spouse":              "Spouse",
+		"relation.spouse.inverse":      "Spouse",
+		"relation.manager":             "Manager",
+		"relation.manager.inverse":     "Direct report",
+		"relation.assistant":           "Assistant",
+		"relation.assistant.inverse":   "Assistant to",
+		"relation.referred-by":         "Referred by",
+		"relation.referred-by.inverse": "Referred",
+
+		"reports.id":    "Enter manager's contact ID: ",
+		"reports.error": "Error finding reports: %v",
+		"reports.none":  "Nobody reports to this contact.",
+		"reports.title": "Everyone reporting to %s:",
+
+		"note.kind":      "Enter kind (%s, default note): ",
+		"note.kindError": "Error reading kind: %v",
+		"note.summary":   "Enter summary: ",
+		"note.error":     "Error adding note: %v",
+		"note.done":      "Note added successfully!",
+		"notes.none":     "No notes found.",
+		"notes.title":    "Notes for %s:",
+
+		"stale.days":        "Enter number of days: ",
+		"stale.none.one":    "Everyone has been contacted in the last %d day.",
+		"stale.none.other":  "Everyone has been contacted in the last %d days.",
+		"stale.title.one":   "Not contacted in the last %d day:",
+		"stale.title.other": "Not contacted in the last %d days:",
+		"stale.never":       "never",
+		"stale.contact":     "%s (%s), last contacted: %s",
+
+		"duplicates.none":        "No likely duplicates found.",
+		"duplicates.title.one":   "Found %d group of likely duplicates:",
+		"duplicates.title.other": "Found %d groups of likely duplicates:",
+		"duplicates.group":       "%d. Confidence %.0f%% (%s)",
+		"reason.same-email":      "same email",
+		"reason.same-phone":      "same phone",
+		"reason.same-name":       "same name",
+		"reason.similar-name":    "similar name",
+		"reason.same-company":    "same company",
+
+		"merge.ids":         "Enter contact IDs to merge (separated by spaces): ",
+		"merge.policy":      "Enter policy (%s, default interactive): ",
+		"merge.policyError": "Error reading policy: %v",
+		"merge.error":       "Error merging contacts: %v",
+		"merge.done":        "Contacts merged successfully!",
+		"merge.conflict":    "Contacts disagree on %s:",
+		"merge.keep":        "Keep which value (1-%d, default 1): ",
+		"merge.cancelled":   "merge cancelled",
+
+		"companies.none":  "No companies found.",
+		"companies.title": "Companies:",
+		"company.name":    "Enter company name: ",
+		"company.none":    "No contacts found at that company.",
+		"company.title":   "Contacts at %s:",
+
+		"show.id":            "ID: %s (short: %s)",
+		"show.name":          "Name: %s",
+		"show.email":         "Email: %s",
+		"show.phone":         "Phone: %s",
+		"show.address":       "Address: %s",
+		"show.otherEmail":    "Other email: %s",
+		"show.otherPhone":    "Other phone: %s",
+		"show.otherAddress":  "Other address: %s",
+		"show.org":           "Organization: %s",
+		"show.title":         "Title: %s",
+		"show.dept":          "Department: %s",
+		"show.tags":          "Tags: %s",
+		"show.lastContacted": "Last contacted: %s",
+		"show.notes.one":     "Notes: %d entry",
+		"show.notes.other":   "Notes: %d entries",
+		"show.aliases":       "Merged from: %s",
+		"show.created":       "Created: %s",
+		"show.updated":       "Updated: %s",
+	},
+}
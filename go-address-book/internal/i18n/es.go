@@ -0,0 +1,241 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// spanish is the Spanish locale
+var spanish = &locale{
+	tag:       language.Spanish,
+	plural:    english.plural,
+	months:    [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+	days:      [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	date:      "2 Jan 2006",
+	dateTime:  "2 Jan 2006, 15:04",
+	monthDay:  "2 Jan",
+	shortDate: "Mon 02 Jan",
+	messages: map[string]string{
+		"answer.yes": "s,si,sí,y,yes",
+
+		"menu.title":      "Libreta de direcciones",
+		"menu.prompt":     "Elija una opción (1-%d): ",
+		"menu.invalid":    "Opción no válida. Inténtelo de nuevo.",
+		"menu.goodbye":    "¡Hasta luego!",
+		"menu.add":        "Añadir contacto",
+		"menu.list":       "Listar contactos",
+		"menu.search":     "Buscar contactos",
+		"menu.update":     "Modificar contacto",
+		"menu.delete":     "Eliminar contacto",
+		"menu.generate":   "Generar datos de prueba",
+		"menu.companies":  "Vista por empresa",
+		"menu.company":    "Listar contactos de una empresa",
+		"menu.upcoming":   "Próximos eventos",
+		"menu.link":       "Vincular contactos",
+		"menu.unlink":     "Desvincular contactos",
+		"menu.reports":    "Mostrar cadena de mando",
+		"menu.note":       "Añadir nota o interacción",
+		"menu.notes":      "Listar notas",
+		"menu.stale":      "Buscar contactos sin contacto reciente",
+		"menu.duplicates": "Buscar duplicados",
+		"menu.merge":      "Fusionar contactos",
+		"menu.edit":       "Editar contacto en el editor",
+		"menu.bulk":       "Cambio masivo por búsqueda",
+		"menu.exit":       "Salir",
+
+		"history.error": "Aviso: no se puede leer el historial: %v",
+		"save.error":    "Error al guardar la libreta de direcciones: %v",
+		"find.error":    "Error al buscar el contacto: %v",
+		"contact.id":    "Introduzca el ID del contacto: ",
+		"days.invalid":  "Número de días no válido.",
+
+		"add.first":   "Introduzca el nombre: ",
+		"add.last":    "Introduzca los apellidos: ",
+		"add.email":   "Introduzca el correo electrónico: ",
+		"add.phone":   "Introduzca el teléfono: ",
+		"add.address": "Introduzca la dirección: ",
+		"add.org":     "Introduzca la organización: ",
+		"add.title":   "Introduzca el cargo: ",
+		"add.dept":    "Introduzca el departamento: ",
+		"add.tags":    "Introduzca las etiquetas (separadas por comas): ",
+		"add.custom":  "Introduzca %s (%s): ",
+		"add.error":   "Error al añadir el contacto: %v",
+		"add.done":    "¡Contacto añadido correctamente!",
+
+		"dates.yearly":      "Introduzca %s (AAAA-MM-DD o MM-DD): ",
+		"dates.yearlyKeep":  "Introduzca %s (AAAA-MM-DD o MM-DD, o pulse Intro para mantener el actual): ",
+		"dates.other":       "Introduzca otras fechas (etiqueta=AAAA-MM-DD, separadas por ';'): ",
+		"dates.otherKeep":   "Introduzca otras fechas (etiqueta=AAAA-MM-DD, separadas por ';', o pulse Intro para mantener las actuales): ",
+		"dates.error":       "Error al leer la fecha: %v",
+		"label.birthday":    "cumpleaños",
+		"label.anniversary": "aniversario",
+
+		"list.none":  "No se encontraron contactos.",
+		"list.title": "Contactos:",
+
+		"search.help":    "Introduzca palabras que buscar, ~palabras para tolerar erratas en los nombres,",
+		"search.prompt":  "o una consulta como last:smith -city:dallas OR tag:vendor: ",
+		"search.none":    "Ningún contacto coincide con la búsqueda.",
+		"search.fuzzy":   "Empiece la consulta con ~ para encontrar también nombres mal escritos o que suenan parecido.",
+		"search.title":   "Resultados de la búsqueda:",
+		"search.invalid": "Consulta no válida en %v\n  %s",
+		"search.error":   "Error al buscar contactos: %v",
+
+		"sort.prompt":    "Ordenar por (p. ej. last,first o -created; Intro para %s): ",
+		"sort.name":      "apellidos, nombre",
+		"sort.relevance": "más relevantes primero",
+		"sort.invalid":   "Orden no válido: %v",
+		"sort.error":     "Error al ordenar los contactos: %v",
+
+		"page.more":  "-- %d-%d de %d. Intro para ver más, q para terminar: ",
+		"page.error": "Error al paginar los contactos: %v",
+
+		"update.id":      "Introduzca el ID del contacto que modificar: ",
+		"update.current": "Contacto actual: %s",
+		"update.clear":   "(Para vaciar un campo, use %s.)",
+		"update.first":   "Introduzca el nuevo nombre (o pulse Intro para mantener el actual): ",
+		"update.last":    "Introduzca los nuevos apellidos (o pulse Intro para mantener los actuales): ",
+		"update.email":   "Introduzca el nuevo correo electrónico (o pulse Intro para mantener el actual): ",
+		"update.phone":   "Introduzca el nuevo teléfono (o pulse Intro para mantener el actual): ",
+		"update.address": "Introduzca la nueva dirección (o pulse Intro para mantener la actual): ",
+		"update.org":     "Introduzca la nueva organización (o pulse Intro para mantener la actual): ",
+		"update.title":   "Introduzca el nuevo cargo (o pulse Intro para mantener el actual): ",
+		"update.dept":    "Introduzca el nuevo departamento (o pulse Intro para mantener el actual): ",
+		"update.tags":    "Introduzca las nuevas etiquetas (separadas por comas, o pulse Intro para mantener las actuales): ",
+		"update.custom":  "Introduzca el nuevo valor de %s (%s, o pulse Intro para mantener el actual): ",
+		"update.error":   "Error al modificar el contacto: %v",
+		"update.done":    "¡Contacto modificado correctamente!",
+
+		"edit.id":        "Introduzca el ID del contacto que editar: ",
+		"edit.cancelled": "Edición cancelada.",
+		"edit.unchanged": "Sin cambios.",
+		"edit.error":     "Error al editar el contacto: %v",
+
+		"delete.id":        "Introduzca el ID del contacto que eliminar: ",
+		"delete.confirm":   "¿Eliminar a %s (%s)? [s/N]: ",
+		"delete.cancelled": "Cancelado; no se ha eliminado nada.",
+		"delete.error":     "Error al eliminar el contacto: %v",
+		"delete.done":      "¡Contacto eliminado correctamente!",
+
+		"bulk.search":               "Introduzca una búsqueda de los contactos que cambiar: ",
+		"bulk.none":                 "Ningún contacto coincide.",
+		"bulk.matched.one":          "%d contacto coincide:",
+		"bulk.matched.other":        "%d contactos coinciden:",
+		"bulk.action":               "Introduzca la acción (update/delete/tag/untag): ",
+		"bulk.field":                "Introduzca el campo que cambiar (first, last, email, phone, address, org, title, dept, tags, birthday, anniversary o un campo personalizado): ",
+		"bulk.value":                "Introduzca el nuevo valor (o pulse Intro para vaciarlo): ",
+		"bulk.unknownField":         "Campo desconocido %q.",
+		"bulk.noTags":               "No se indicaron etiquetas.",
+		"bulk.invalidAction":        "Acción no válida.",
+		"bulk.confirm.update.one":   "¿Modificar %d contacto? [s/N]: ",
+		"bulk.confirm.update.other": "¿Modificar %d contactos? [s/N]: ",
+		"bulk.confirm.delete.one":   "¿Eliminar %d contacto? [s/N]: ",
+		"bulk.confirm.delete.other": "¿Eliminar %d contactos? [s/N]: ",
+		"bulk.confirm.tag.one":      "¿Etiquetar %d contacto? [s/N]: ",
+		"bulk.confirm.tag.other":    "¿Etiquetar %d contactos? [s/N]: ",
+		"bulk.confirm.untag.one":    "¿Quitar etiquetas a %d contacto? [s/N]: ",
+		"bulk.confirm.untag.other":  "¿Quitar etiquetas a %d contactos? [s/N]: ",
+		"bulk.cancelled":            "Cancelado; no se ha cambiado nada.",
+		"bulk.deleteError":          "Error al eliminar los contactos: %v",
+		"bulk.deleted.one":          "%d contacto eliminado.",
+		"bulk.deleted.other":        "%d contactos eliminados.",
+		"bulk.updateError":          "Error al modificar los contactos: %v",
+		"bulk.updated.one":          "%d contacto modificado (%d ya estaban al día).",
+		"bulk.updated.other":        "%d contactos modificados (%d ya estaban al día).",
+		"preview.more.one":          "... y %d más",
+		"preview.more.other":        "... y %d más",
+
+		"generate.error":      "Error al añadir los contactos de prueba: %v",
+		"generate.done.one":   "¡%d contacto de prueba generado correctamente!",
+		"generate.done.other": "¡%d contactos de prueba generados correctamente!",
+
+		"upcoming.days":        "Introduzca cuántos días mirar hacia delante (30 por defecto): ",
+		"upcoming.none.one":    "No hay eventos en el próximo %d día.",
+		"upcoming.none.other":  "No hay eventos en los próximos %d días.",
+		"upcoming.title.one":   "Eventos del próximo %d día:",
+		"upcoming.title.other": "Eventos de los próximos %d días:",
+		"upcoming.turns.one":   "(cumple %d año)",
+		"upcoming.turns.other": "(cumple %d años)",
+		"upcoming.years.one":   "(%d año)",
+		"upcoming.years.other": "(%d años)",
+
+		"link.relation":      "Introduzca la relación (%s): ",
+		"link.relationError": "Error al leer la relación: %v",
+		"link.to":            "Introduzca el ID del contacto relacionado (%s): ",
+		"link.error":         "Error al vincular los contactos: %v",
+		"link.done":          "¡Contactos vinculados correctamente!",
+		"unlink.error":       "Error al desvincular los contactos: %v",
+		"unlink.done":        "¡Contactos desvinculados correctamente!",
+
+		"relation.spouse":              "Cónyuge",
+		"relation.spouse.inverse":      "Cónyuge",
+		"relation.manager":             "Responsable",
+		"relation.manager.inverse":     "Subordinado directo",
+		"relation.assistant":           "Asistente",
+		"relation.assistant.inverse":   "Asistente de",
+		"relation.referred-by":         "Recomendado por",
+		"relation.referred-by.inverse": "Recomendó a",
+
+		"reports.id":    "Introduzca el ID del responsable: ",
+		"reports.error": "Error al buscar los subordinados: %v",
+		"reports.none":  "Nadie depende de este contacto.",
+		"reports.title": "Todos los que dependen de %s:",
+
+		"note.kind":      "Introduzca el tipo (%s, note por defecto): ",
+		"note.kindError": "Error al leer el tipo: %v",
+		"note.summary":   "Introduzca el resumen: ",
+		"note.error":     "Error al añadir la nota: %v",
+		"note.done":      "¡Nota añadida correctamente!",
+		"notes.none":     "No se encontraron notas.",
+		"notes.title":    "Notas de %s:",
+
+		"stale.days":        "Introduzca el número de días: ",
+		"stale.none.one":    "Se ha contactado con todos en el último %d día.",
+		"stale.none.other":  "Se ha contactado con todos en los últimos %d días.",
+		"stale.title.one":   "Sin contacto en el último %d día:",
+		"stale.title.other": "Sin contacto en los últimos %d días:",
+		"stale.never":       "nunca",
+		"stale.contact":     "%s (%s), último contacto: %s",
+
+		"duplicates.none":        "No se encontraron posibles duplicados.",
+		"duplicates.title.one":   "Se encontró %d grupo de posibles duplicados:",
+		"duplicates.title.other": "Se encontraron %d grupos de posibles duplicados:",
+		"duplicates.group":       "%d. Confianza del %.0f%% (%s)",
+		"reason.same-email":      "mismo correo",
+		"reason.same-phone":      "mismo teléfono",
+		"reason.same-name":       "mismo nombre",
+		"reason.similar-name":    "nombre parecido",
+		"reason.same-company":    "misma empresa",
+
+		"merge.ids":         "Introduzca los ID de los contactos que fusionar (separados por espacios): ",
+		"merge.policy":      "Introduzca la política (%s, interactive por defecto): ",
+		"merge.policyError": "Error al leer la política: %v",
+		"merge.error":       "Error al fusionar los contactos: %v",
+		"merge.done":        "¡Contactos fusionados correctamente!",
+		"merge.conflict":    "Los contactos no coinciden en %s:",
+		"merge.keep":        "¿Qué valor se conserva? (1-%d, 1 por defecto): ",
+		"merge.cancelled":   "fusión cancelada",
+
+		"companies.none":  "No se encontraron empresas.",
+		"companies.title": "Empresas:",
+		"company.name":    "Introduzca el nombre de la empresa: ",
+		"company.none":    "No se encontraron contactos en esa empresa.",
+		"company.title":   "Contactos de %s:",
+
+		"show.id":            "ID: %s (corto: %s)",
+		"show.name":          "Nombre: %s",
+		"show.email":         "Correo: %s",
+		"show.phone":         "Teléfono: %s",
+		"show.address":       "Dirección: %s",
+		"show.otherEmail":    "Otro correo: %s",
+		"show.otherPhone":    "Otro teléfono: %s",
+		"show.otherAddress":  "Otra dirección: %s",
+		"show.org":           "Organización: %s",
+		"show.title":         "Cargo: %s",
+		"show.dept":          "Departamento: %s",
+		"show.tags":          "Etiquetas: %s",
+		"show.lastContacted": "Último contacto: %s",
+		"show.notes.one":     "Notas: %d entrada",
+		"show.notes.other":   "Notas: %d entradas",
+		"show.aliases":       "Fusionado de: %s",
+		"show.created":       "Creado: %s",
+		"show.updated":       "Modificado: %s",
+	},
+}
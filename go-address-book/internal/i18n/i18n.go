@@ -0,0 +1,223 @@
+// Package i18n translates the interactive menu's prompts and messages and
+// formats dates and names the way a locale expects.
+//
+// Messages are looked up by key, such as "menu.add", in the catalog of the
+// selected locale and formatted with fmt verbs; explicit argument indexes
+// such as %[2]s let a translation reorder its arguments. A key missing from
+// a catalog falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// locale is the message catalog and date conventions of one language
+type locale struct {
+	tag      language.Tag
+	messages map[string]string
+	plural   func(n int) string // the plural form of n: "one" or "other"
+	months   [12]string         // abbreviated month names, for "Jan" in layouts
+	days     [7]string          // abbreviated weekday names from Sunday, for "Mon"
+
+	// Date layouts in the time package's notation
+	date, dateTime, monthDay, shortDate string
+}
+
+// locales are the locales with a catalog. The first is the default.
+var locales = []*locale{english, spanish}
+
+var matcher = language.NewMatcher(func() []language.Tag {
+	tags := make([]language.Tag, len(locales))
+	for i, loc := range locales {
+		tags[i] = loc.tag
+	}
+	return tags
+}())
+
+// familyNameFirst are the languages that write the family name before the
+// given name
+var familyNameFirst = map[string]bool{"hu": true, "ja": true, "ko": true, "vi": true, "zh": true}
+
+// Locales lists the locales that have a message catalog
+func Locales() []string {
+	names := make([]string, len(locales))
+	for i, loc := range locales {
+		names[i] = loc.tag.String()
+	}
+	return names
+}
+
+// Parse reads a locale name as either a BCP 47 tag such as "es-MX" or a
+// POSIX locale such as "es_MX.UTF-8". "C" and "POSIX" mean English.
+func Parse(name string) (language.Tag, error) {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	if name == "" || name == "C" || name == "POSIX" {
+		return language.English, nil
+	}
+	return language.Parse(strings.ReplaceAll(name, "_", "-"))
+}
+
+// EnvLocale returns the locale the environment sets for a category such as
+// LC_MESSAGES, looked up the way the C library does: LC_ALL, then the
+// category, then LANG. "en_US.UTF-8" becomes the BCP 47 tag "en-US"; "" means
+// none is set, or "C" or "POSIX" is.
+func EnvLocale(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return strings.ReplaceAll(value, "_", "-")
+	}
+	return ""
+}
+
+// Printer translates messages and formats dates and names for one locale
+type Printer struct {
+	tag language.Tag
+	loc *locale
+}
+
+// New returns a Printer for the named locale, or for the locale of the
+// environment's LC_MESSAGES when name is empty. Messages of a language
+// without a catalog are printed in English, but names are still ordered
+// the way the language expects.
+func New(name string) *Printer {
+	if name == "" {
+		name = EnvLocale("LC_MESSAGES")
+	}
+	tag, err := Parse(name)
+	if err != nil {
+		tag = language.English
+	}
+	_, index, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		index = 0
+	}
+	return &Printer{tag: tag, loc: locales[index]}
+}
+
+// Locale returns the BCP 47 tag of the catalog in use
+func (p *Printer) Locale() string {
+	return p.loc.tag.String()
+}
+
+// Has reports whether key is in the catalog of the Printer's locale
+func (p *Printer) Has(key string) bool {
+	_, ok := p.loc.messages[key]
+	return ok
+}
+
+// T returns the message for key, formatted with args. An unknown key is
+// returned as is.
+func (p *Printer) T(key string, args ...any) string {
+	msg, ok := p.loc.messages[key]
+	if !ok {
+		if msg, ok = english.messages[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N returns the plural form of the message for key that suits n, such as
+// key.one or key.other, formatted with n followed by args
+func (p *Printer) N(key string, n int, args ...any) string {
+	return p.T(key+"."+p.loc.plural(n), append([]any{n}, args...)...)
+}
+
+// Yes reports whether an answer to a yes/no question is yes
+func (p *Printer) Yes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, yes := range strings.Split(p.T("answer.yes"), ",") {
+		if answer == yes {
+			return true
+		}
+	}
+	return false
+}
+
+// Date formats the day of t, as in "Jan 2, 2006"
+func (p *Printer) Date(t time.Time) string {
+	return p.format(t, p.loc.date)
+}
+
+// DateTime formats t to the minute, as in "Jan 2, 2006 3:04 PM"
+func (p *Printer) DateTime(t time.Time) string {
+	return p.format(t, p.loc.dateTime)
+}
+
+// MonthDay formats the month and day of t, as in "Jan 2", for yearly dates
+// whose year is not known
+func (p *Printer) MonthDay(t time.Time) string {
+	return p.format(t, p.loc.monthDay)
+}
+
+// ShortDate formats the weekday, month and day of t, as in "Mon Jan 02"
+func (p *Printer) ShortDate(t time.Time) string {
+	return p.format(t, p.loc.shortDate)
+}
+
+// format formats t with a layout, writing the locale's names for "Jan" and
+// "Mon"; the rest of the layout is left to the time package
+func (p *Printer) format(t time.Time, layout string) string {
+	var b strings.Builder
+	for layout != "" {
+		month, day := strings.Index(layout, "Jan"), strings.Index(layout, "Mon")
+		next, name := month, p.loc.months[t.Month()-1]
+		if next < 0 || day >= 0 && day < next {
+			next, name = day, p.loc.days[t.Weekday()]
+		}
+		if next < 0 {
+			b.WriteString(t.Format(layout))
+			break
+		}
+		if next > 0 {
+			b.WriteString(t.Format(layout[:next]))
+		}
+		b.WriteString(name)
+		layout = layout[next+3:]
+	}
+	return b.String()
+}
+
+// Name joins a given name and a family name in the order of the locale's
+// language. Names written in Chinese, Japanese or Korean characters are
+// joined without a space.
+func (p *Printer) Name(first, last string) string {
+	if first == "" || last == "" {
+		return first + last
+	}
+	if base, _ := p.tag.Base(); familyNameFirst[base.String()] {
+		first, last = last, first
+	}
+	if ideographic(first) && ideographic(last) {
+		return first + last
+	}
+	return first + " " + last
+}
+
+// ideographic reports whether s is written entirely in Han, Hangul or kana
+func ideographic(s string) bool {
+	for _, r := range s {
+		if !unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) {
+			return false
+		}
+	}
+	return true
+}
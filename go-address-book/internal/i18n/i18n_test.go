@@ -0,0 +1,193 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// verbs matches the fmt verbs of a message
+var verbs = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+func verbsOf(msg string) []string {
+	var found []string
+	for _, m := range verbs.FindAllStringSubmatch(msg, -1) {
+		found = append(found, m[1])
+	}
+	slices.Sort(found)
+	return found
+}
+
+func TestEveryKeyTranslated(t *testing.T) {
+	for _, loc := range locales[1:] {
+		for key, msg := range english.messages {
+			translated, ok := loc.messages[key]
+			if !ok || translated == "" {
+				t.Errorf("%s: %q is not translated", loc.tag, key)
+				continue
+			}
+			if want, got := verbsOf(msg), verbsOf(translated); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v", loc.tag, key, got, want)
+			}
+		}
+		for key := range loc.messages {
+			if _, ok := english.messages[key]; !ok {
+				t.Errorf("%s: %q is not an English key", loc.tag, key)
+			}
+		}
+		for i, name := range loc.months {
+			if name == "" {
+				t.Errorf("%s: month %d has no name", loc.tag, i+1)
+			}
+		}
+		for i, name := range loc.days {
+			if name == "" {
+				t.Errorf("%s: weekday %d has no name", loc.tag, i)
+			}
+		}
+	}
+}
+
+// uses matches the keys the menu passes to T and N
+var uses = regexp.MustCompile(`(?:tr|notices)\.([TN])\("([^"]+)"`)
+
+func TestKeysUsedExist(t *testing.T) {
+	files, err := filepath.Glob("../../cmd/*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no sources found: %v", err)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range uses.FindAllStringSubmatch(string(src), -1) {
+			keys := []string{m[2]}
+			if m[1] == "N" {
+				keys = []string{m[2] + ".one", m[2] + ".other"}
+			}
+			for _, key := range keys {
+				if _, ok := english.messages[key]; !ok {
+					t.Errorf("%s uses %q, which is not in the catalog", filepath.Base(file), key)
+				}
+			}
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+	tests := []struct {
+		name, lang, want string
+	}{
+		{"es", "", "es"},
+		{"es-MX", "", "es"},
+		{"es_AR.UTF-8", "", "es"},
+		{"fr", "", "en"},
+		{"C", "es_ES.UTF-8", "en"},
+		{"not a locale", "", "en"},
+		{"", "", "en"},
+		{"", "es_ES.UTF-8", "es"},
+		{"", "POSIX", "en"},
+	}
+	for _, tt := range tests {
+		t.Setenv("LANG", tt.lang)
+		if got := New(tt.name).Locale(); got != tt.want {
+			t.Errorf("New(%q) with LANG=%q uses %s, want %s", tt.name, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestHas(t *testing.T) {
+	if !New("es").Has("reason.same-company") {
+		t.Error("Expected the Spanish catalog to have reason.same-company")
+	}
+	partial := &Printer{tag: language.Spanish, loc: &locale{messages: map[string]string{"menu.add": "Añadir"}}}
+	if !partial.Has("menu.add") || partial.Has("menu.list") {
+		t.Error("Expected Has to look only in the Printer's own catalog")
+	}
+}
+
+func TestEnvLocale(t *testing.T) {
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("LC_MESSAGES", "es_ES.UTF-8")
+	t.Setenv("LC_ALL", "")
+	if got := EnvLocale("LC_MESSAGES"); got != "es-ES" {
+		t.Errorf("EnvLocale(LC_MESSAGES) = %q, want es-ES", got)
+	}
+	if got := EnvLocale("LC_COLLATE"); got != "en-US" {
+		t.Errorf("EnvLocale(LC_COLLATE) = %q, want en-US", got)
+	}
+	t.Setenv("LC_ALL", "C")
+	if got := EnvLocale("LC_MESSAGES"); got != "" {
+		t.Errorf("EnvLocale with LC_ALL=C = %q, want none", got)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	en, es := New("en"), New("es")
+	if got := en.T("menu.prompt", 20); got != "Enter your choice (1-20): " {
+		t.Errorf("T = %q", got)
+	}
+	if got := es.N("bulk.deleted", 1); got != "1 contacto eliminado." {
+		t.Errorf("N(1) = %q", got)
+	}
+	if got := es.N("bulk.updated", 3, 2); got != "3 contactos modificados (2 ya estaban al día)." {
+		t.Errorf("N(3) = %q", got)
+	}
+	if got := es.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("T(unknown) = %q", got)
+	}
+	for answer, want := range map[string]bool{"s": true, "Sí": true, "yes": true, "n": false, "": false} {
+		if got := es.Yes(answer); got != want {
+			t.Errorf("es Yes(%q) = %v, want %v", answer, got, want)
+		}
+	}
+	if en.Yes("s") {
+		t.Error(`en Yes("s") = true`)
+	}
+}
+
+func TestDates(t *testing.T) {
+	at := time.Date(2024, time.September, 3, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		locale                              string
+		date, dateTime, monthDay, shortDate string
+	}{
+		{"en", "Sep 3, 2024", "Sep 3, 2024 2:05 PM", "Sep 3", "Tue Sep 03"},
+		{"es", "3 sept 2024", "3 sept 2024, 14:05", "3 sept", "mar 03 sept"},
+	}
+	for _, tt := range tests {
+		p := New(tt.locale)
+		got := []string{p.Date(at), p.DateTime(at), p.MonthDay(at), p.ShortDate(at)}
+		want := []string{tt.date, tt.dateTime, tt.monthDay, tt.shortDate}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s dates = %q, want %q", tt.locale, got, want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		locale, first, last, want string
+	}{
+		{"en", "Ada", "Lovelace", "Ada Lovelace"},
+		{"es", "Ada", "", "Ada"},
+		{"hu", "Ferenc", "Molnár", "Molnár Ferenc"},
+		{"ja", "太郎", "山田", "山田太郎"},
+		{"ja-JP", "Taro", "Yamada", "Yamada Taro"},
+		{"en", "太郎", "山田", "太郎山田"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.locale).Name(tt.first, tt.last); got != tt.want {
+			t.Errorf("%s Name(%q, %q) = %q, want %q", tt.locale, tt.first, tt.last, got, tt.want)
+		}
+	}
+}
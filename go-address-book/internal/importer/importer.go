@@ -0,0 +1,48 @@
+// Package importer holds what the vCard and CSV importers share: reading
+// the text of an exported file and choosing a contact's primary email,
+// phone and address from several ranked values.
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+// DecodeText turns a file into UTF-8, decoding UTF-16 files and dropping a
+// byte-order mark. Other text is returned as it is.
+func DecodeText(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading UTF-16: %w", err)
+		}
+		return decoded, nil
+	}
+	return bytes.TrimPrefix(data, []byte("\uFEFF")), nil
+}
+
+// Ranked is an email, phone or address with its preference, lower first
+type Ranked struct {
+	Value string
+	Rank  int
+}
+
+// Primary splits values into the most preferred and the others, in the
+// order they were given, dropping empty values and repeats
+func Primary(values []Ranked) (string, []string) {
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Rank < values[j].Rank })
+	var all []string
+	for _, v := range values {
+		if v.Value != "" && !slices.Contains(all, v.Value) {
+			all = append(all, v.Value)
+		}
+	}
+	if len(all) == 0 {
+		return "", nil
+	}
+	return all[0], all[1:]
+}
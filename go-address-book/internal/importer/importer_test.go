@@ -0,0 +1,29 @@
+package importer
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDecodeText(t *testing.T) {
+	text := "José Müller\r\n"
+	utf16, _ := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().String(text)
+	for name, data := range map[string]string{"UTF-8": text, "UTF-8 with BOM": "\uFEFF" + text, "UTF-16": utf16} {
+		got, err := DecodeText([]byte(data))
+		if err != nil || string(got) != text {
+			t.Errorf("%s: DecodeText = %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestPrimary(t *testing.T) {
+	first, others := Primary([]Ranked{{"b@x", 2}, {"", 0}, {"a@x", 1}, {"c@x", 2}, {"a@x", 3}})
+	if first != "a@x" || !slices.Equal(others, []string{"b@x", "c@x"}) {
+		t.Errorf("Primary = %q, %q", first, others)
+	}
+	if first, others := Primary(nil); first != "" || others != nil {
+		t.Errorf("Primary(nil) = %q, %q", first, others)
+	}
+}
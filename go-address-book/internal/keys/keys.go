@@ -0,0 +1,100 @@
+// Package keys decodes what a terminal in raw mode sends into key presses.
+package keys
+
+import "unicode/utf8"
+
+// Key is one key press. Printable characters have an empty name.
+type Key struct {
+	Name string
+	Rune rune
+}
+
+// Named keys
+const (
+	Up        = "up"
+	Down      = "down"
+	Left      = "left"
+	Right     = "right"
+	Home      = "home"
+	End       = "end"
+	PageUp    = "pgup"
+	PageDown  = "pgdn"
+	Enter     = "enter"
+	Esc       = "esc"
+	Tab       = "tab"
+	ShiftTab  = "shift-tab"
+	Backspace = "backspace"
+	Delete    = "delete"
+	CtrlA     = "ctrl-a"
+	CtrlC     = "ctrl-c"
+	CtrlD     = "ctrl-d"
+	CtrlE     = "ctrl-e"
+	CtrlK     = "ctrl-k"
+	CtrlL     = "ctrl-l"
+	CtrlS     = "ctrl-s"
+	CtrlU     = "ctrl-u"
+	CtrlW     = "ctrl-w"
+)
+
+// escapes maps the escape sequences terminals send for named keys
+var escapes = map[string]string{
+	"[A": Up, "[B": Down, "[C": Right, "[D": Left,
+	"OA": Up, "OB": Down, "OC": Right, "OD": Left,
+	"[H": Home, "[F": End, "OH": Home, "OF": End,
+	"[1~": Home, "[4~": End, "[7~": Home, "[8~": End,
+	"[5~": PageUp, "[6~": PageDown, "[3~": Delete, "[Z": ShiftTab,
+}
+
+// controls maps control characters to the keys that send them
+var controls = map[byte]string{
+	'\r': Enter, '\n': Enter, '\t': Tab, 0x7f: Backspace, 0x08: Backspace,
+	0x01: CtrlA, 0x03: CtrlC, 0x04: CtrlD, 0x05: CtrlE, 0x0b: CtrlK,
+	0x0c: CtrlL, 0x13: CtrlS, 0x15: CtrlU, 0x17: CtrlW,
+}
+
+// Parse splits what one read from the terminal returned into keys.
+// An escape that starts no known sequence is the Esc key itself.
+func Parse(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			name, n := parseEscape(b[1:])
+			if name != "" {
+				keys = append(keys, Key{Name: name})
+			}
+			b = b[1+n:]
+			continue
+		case c < 0x20 || c == 0x7f:
+			// other control keys are ignored
+			if name, ok := controls[c]; ok {
+				keys = append(keys, Key{Name: name})
+			}
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// parseEscape recognizes the sequence after an escape byte, returning the
+// key name and how many bytes it used
+func parseEscape(b []byte) (string, int) {
+	if len(b) == 0 || (b[0] != '[' && b[0] != 'O') {
+		return Esc, 0
+	}
+	// A CSI sequence ends at the first byte in the range @ to ~
+	for i := 1; i < len(b); i++ {
+		if b[i] >= '@' && b[i] <= '~' {
+			if name, ok := escapes[string(b[:i+1])]; ok {
+				return name, i + 1
+			}
+			return "", i + 1 // unknown sequence, dropped
+		}
+	}
+	return Esc, 0
+}
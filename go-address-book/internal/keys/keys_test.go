@@ -0,0 +1,21 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var got []string
+	for _, k := range Parse([]byte("a\x1b[A\x1b[6~é\r\x1b\x7f\x13\x01\x17\x1b[99q")) {
+		if k.Name == "" {
+			got = append(got, string(k.Rune))
+		} else {
+			got = append(got, k.Name)
+		}
+	}
+	want := "a up pgdn é enter esc backspace ctrl-s ctrl-a ctrl-w"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Parse = %v, want %s", got, want)
+	}
+}
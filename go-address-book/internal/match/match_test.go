@@ -0,0 +1,125 @@
+package match
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"smith", "smith", 0},
+		{"smith", "smyth", 1},
+		{"jon", "john", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"zoë", "zoe", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDamerau(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"jhon", "john", 1},
+		{"smith", "smyth", 1},
+		{"ca", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"", "ab", 2},
+		{"same", "same", 0},
+	}
+	for _, tt := range tests {
+		if got := Damerau(tt.a, tt.b); got != tt.want {
+			t.Errorf("Damerau(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"same", "same", 1},
+		{"abc", "xyz", 0},
+	}
+	for _, tt := range tests {
+		if got := JaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("JaroWinkler(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	emails := map[string]string{
+		"John.Doe+work@Gmail.com": "johndoe@gmail.com",
+		"j.doe@googlemail.com":    "jdoe@gmail.com",
+		"John.Doe+work@acme.com":  "john.doe@acme.com",
+		" someone@Example.COM ":   "someone@example.com",
+		"not-an-email":            "not-an-email",
+	}
+	for in, want := range emails {
+		if got := NormalizeEmail(in); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	phones := map[string]string{
+		"+1 (555) 010-2000": "5550102000",
+		"555.010.2000":      "5550102000",
+		"+44 20 7946 0958":  "442079460958",
+		"12-34":             "",
+	}
+	for in, want := range phones {
+		if got := NormalizePhone(in); got != want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := CanonicalFirstName("Bob"); got != "robert" {
+		t.Errorf("CanonicalFirstName(Bob) = %q, want robert", got)
+	}
+	if got := CanonicalFirstName("O'Neil"); got != "oneil" {
+		t.Errorf("CanonicalFirstName(O'Neil) = %q, want oneil", got)
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"Smith", "SM0"},
+		{"Smyth", "SM0"},
+		{"John", "JN"},
+		{"Jon", "JN"},
+		{"Jhon", "JHN"},
+		{"Catherine", "K0RN"},
+		{"Kathryn", "K0RN"},
+		{"Knight", "NT"},
+		{"Philip", "FLP"},
+		{"Schmidt", "SKMTT"},
+		{"Thompson", "0MPSN"},
+		{"Xavier", "SFR"},
+		{"Whitney", "WTN"},
+		{"Ashley", "AXL"},
+		{"McDonald", "MKTNLT"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Metaphone(tt.word); got != tt.want {
+			t.Errorf("Metaphone(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package match
+
+// nicknames maps common English nicknames to the formal name they stand for
+var nicknames = map[string]string{
+	"abby": "abigail", "al": "albert", "alex": "alexander", "andy": "andrew", "ben": "benjamin",
+	"beth": "elizabeth", "betty": "elizabeth", "bill": "william", "billy": "william", "bob": "robert",
+	"bobby": "robert", "cathy": "catherine", "charlie": "charles", "chris": "christopher", "chuck": "charles",
+	"dan": "daniel", "danny": "daniel", "dave": "david", "deb": "deborah", "debbie": "deborah",
+	"dick": "richard", "ed": "edward", "eddie": "edward", "greg": "gregory", "jack": "john",
+	"jake": "jacob", "jim": "james", "jimmy": "james", "jen": "jennifer", "jenny": "jennifer",
+	"joe": "joseph", "joey": "joseph", "johnny": "john", "jon": "john", "kate": "katherine",
+	"katie": "katherine", "kathy": "katherine", "larry": "lawrence", "liz": "elizabeth", "maggie": "margaret",
+	"matt": "matthew", "meg": "margaret", "mike": "michael", "mick": "michael", "nate": "nathan",
+	"nick": "nicholas", "pat": "patricia", "peggy": "margaret", "pete": "peter", "rich": "richard",
+	"rick": "richard", "rob": "robert", "ron": "ronald", "sam": "samuel", "sandy": "sandra",
+	"steve": "steven", "sue": "susan", "ted": "edward", "tom": "thomas", "tommy": "thomas",
+	"tony": "anthony", "vicky": "victoria", "will": "william", "zach": "zachary", "liv": "olivia",
+	"sophie": "sophia", "bella": "isabella", "izzy": "isabella", "em": "emma", "emmy": "emma",
+}
+
+// CanonicalFirstName maps a first name to its formal form, so "Bob" and
+// "Robert" compare equal. The result is normalized with NormalizeName.
+func CanonicalFirstName(name string) string {
+	name = NormalizeName(name)
+	if formal, ok := nicknames[name]; ok {
+		return formal
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package match
+
+import "strings"
+
+// Metaphone returns the Metaphone code of a word: an approximation of how
+// it sounds in English, so "Smith" and "Smyth" both encode to "SM0" and
+// "Jon" and "John" to "JN". "0" stands for "th" and "X" for "sh". Letters
+// other than A to Z are ignored.
+func Metaphone(word string) string {
+	var letters []byte
+	for _, r := range strings.ToUpper(word) {
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, byte(r))
+		}
+	}
+	if len(letters) == 0 {
+		return ""
+	}
+
+	// Silent or shifted starts
+	switch {
+	case hasPrefix(letters, "KN"), hasPrefix(letters, "GN"), hasPrefix(letters, "PN"),
+		hasPrefix(letters, "AE"), hasPrefix(letters, "WR"):
+		letters = letters[1:]
+	case letters[0] == 'X':
+		letters[0] = 'S'
+	case hasPrefix(letters, "WH"):
+		letters = append([]byte{'W'}, letters[2:]...)
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(letters) {
+			return 0
+		}
+		return letters[i]
+	}
+	isVowel := func(c byte) bool { return strings.IndexByte("AEIOU", c) >= 0 }
+	frontVowel := func(c byte) bool { return c == 'E' || c == 'I' || c == 'Y' }
+
+	var code strings.Builder
+	for i, c := range letters {
+		prev, next, after := at(i-1), at(i+1), at(i+2)
+		if c == prev && c != 'C' {
+			continue
+		}
+
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				code.WriteByte(c)
+			}
+		case 'B':
+			if !(prev == 'M' && i == len(letters)-1) {
+				code.WriteByte('B')
+			}
+		case 'C':
+			switch {
+			case next == 'I' && after == 'A', next == 'H' && prev != 'S':
+				code.WriteByte('X')
+			case frontVowel(next):
+				if prev != 'S' {
+					code.WriteByte('S')
+				}
+			default:
+				code.WriteByte('K')
+			}
+		case 'D':
+			if next == 'G' && frontVowel(after) {
+				code.WriteByte('J')
+			} else {
+				code.WriteByte('T')
+			}
+		case 'G':
+			switch {
+			case next == 'H' && i+2 < len(letters) && !isVowel(after):
+				// silent, as in "night"
+			case next == 'N' && (i+2 == len(letters) || (after == 'E' && at(i+3) == 'D' && i+4 == len(letters))):
+				// silent, as in "sign" and "signed"
+			case frontVowel(next) && prev != 'G':
+				code.WriteByte('J')
+			default:
+				code.WriteByte('K')
+			}
+		case 'H':
+			if isVowel(next) && strings.IndexByte("CSPTG", prev) < 0 {
+				code.WriteByte('H')
+			}
+		case 'K':
+			if prev != 'C' {
+				code.WriteByte('K')
+			}
+		case 'P':
+			if next == 'H' {
+				code.WriteByte('F')
+			} else {
+				code.WriteByte('P')
+			}
+		case 'Q':
+			code.WriteByte('K')
+		case 'S':
+			if next == 'H' || (next == 'I' && (after == 'O' || after == 'A')) {
+				code.WriteByte('X')
+			} else {
+				code.WriteByte('S')
+			}
+		case 'T':
+			switch {
+			case next == 'I' && (after == 'O' || after == 'A'):
+				code.WriteByte('X')
+			case next == 'H':
+				code.WriteByte('0')
+			case next == 'C' && after == 'H':
+				// silent, as in "witch"
+			default:
+				code.WriteByte('T')
+			}
+		case 'V':
+			code.WriteByte('F')
+		case 'W', 'Y':
+			if isVowel(next) {
+				code.WriteByte(c)
+			}
+		case 'X':
+			code.WriteString("KS")
+		case 'Z':
+			code.WriteByte('S')
+		default: // F, J, L, M, N, R
+			code.WriteByte(c)
+		}
+	}
+	return code.String()
+}
+
+func hasPrefix(letters []byte, prefix string) bool {
+	return strings.HasPrefix(string(letters), prefix)
+}
@@ -2,104 +2,161 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
-	"time"
 )
 
 // AddressBook represents a collection of contacts
 type AddressBook struct {
 	contacts map[string]*Contact
+	links    map[string][]Link
+	aliases  map[string]string // merged-away ID -> surviving ID
+	schema   Schema
+	index    *searchIndex
 	mu       sync.RWMutex
+
+	subscribers []*Subscription
+	seq         uint64
+	batch       uint64
+}
+
+// ConflictError is returned when a contact is updated from a stale copy
+type ConflictError struct {
+	ID       string
+	Expected int
+	Actual   int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("contact %s was changed by someone else (version %d, now %d); reload and try again", e.ID, e.Expected, e.Actual)
 }
 
 // NewAddressBook creates a new empty address book
 func NewAddressBook() *AddressBook {
 	return &AddressBook{
 		contacts: make(map[string]*Contact),
+		links:    make(map[string][]Link),
+		aliases:  make(map[string]string),
+		index:    newSearchIndex(),
 	}
 }
 
-// AddContact adds a new contact to the address book
-func (ab *AddressBook) AddContact(contact *Contact) error {
+// SetSchema sets the custom fields that contacts may carry
+func (ab *AddressBook) SetSchema(schema Schema) error {
+	if err := schema.Validate(); err != nil {
+		return err
+	}
+
 	ab.mu.Lock()
 	defer ab.mu.Unlock()
 
-	if _, exists := ab.contacts[contact.ID]; exists {
-		return errors.New("contact with this ID already exists")
-	}
+	ab.schema = schema
+	return nil
+}
 
-	ab.contacts[contact.ID] = contact
+// Schema returns the custom fields contacts may carry
+func (ab *AddressBook) Schema() Schema {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	return slices.Clone(ab.schema)
+}
+
+// AddContact adds a new contact to the address book. The address book keeps
+// its own copy, so later changes to contact have no effect until UpdateContact.
+func (ab *AddressBook) AddContact(contact *Contact) error {
+	return ab.Apply([]Op{AddOp(contact)})
+}
+
+// validate checks the contact's custom values and dates
+func (ab *AddressBook) validate(contact *Contact) error {
+	if err := ab.schema.Check(contact.Custom); err != nil {
+		return err
+	}
+	for _, date := range contact.Dates {
+		if err := date.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetContact retrieves a contact by ID
+// GetContact retrieves a copy of the contact with the given ID. The ID of a
+// contact that was merged into another resolves to the merged contact.
 func (ab *AddressBook) GetContact(id string) (*Contact, error) {
 	ab.mu.RLock()
 	defer ab.mu.RUnlock()
 
 	contact, exists := ab.contacts[id]
+	if !exists {
+		contact, exists = ab.contacts[ab.aliases[id]]
+	}
 	if !exists {
 		return nil, errors.New("contact not found")
 	}
 
-	return contact, nil
+	return contact.Clone(), nil
 }
 
-// UpdateContact updates an existing contact
+// UpdateContact replaces an existing contact. It fails with a *ConflictError
+// unless contact.Version matches the stored version, so an update made from a
+// stale copy never silently overwrites someone else's change. On success the
+// version of both contact and the stored copy is incremented.
 func (ab *AddressBook) UpdateContact(contact *Contact) error {
-	ab.mu.Lock()
-	defer ab.mu.Unlock()
-
-	if _, exists := ab.contacts[contact.ID]; !exists {
-		return errors.New("contact not found")
-	}
-
-	contact.UpdatedAt = time.Now()
-	ab.contacts[contact.ID] = contact
-	return nil
+	return ab.Apply([]Op{UpdateOp(contact)})
 }
 
-// DeleteContact removes a contact by ID
+// DeleteContact removes a contact by ID along with its relationships
 func (ab *AddressBook) DeleteContact(id string) error {
-	ab.mu.Lock()
-	defer ab.mu.Unlock()
-
-	if _, exists := ab.contacts[id]; !exists {
-		return errors.New("contact not found")
-	}
-
-	delete(ab.contacts, id)
-	return nil
+	return ab.Apply([]Op{DeleteOp(id)})
 }
 
-// GetAllContacts returns all contacts in the address book
+// GetAllContacts returns copies of all contacts in the address book, in the
+// order they were created. Use List for other orders and for paging.
 func (ab *AddressBook) GetAllContacts() []*Contact {
 	ab.mu.RLock()
 	defer ab.mu.RUnlock()
 
 	contacts := make([]*Contact, 0, len(ab.contacts))
+	keys := make(map[*Contact]string, len(ab.contacts))
 	for _, contact := range ab.contacts {
-		contacts = append(contacts, contact)
+		clone := contact.Clone()
+		contacts = append(contacts, clone)
+		keys[clone] = creationKey(clone.ID)
 	}
+	sort.Slice(contacts, func(i, j int) bool { return keys[contacts[i]] < keys[contacts[j]] })
 	return contacts
 }
 
-// SearchContacts searches for contacts by name or email
-func (ab *AddressBook) SearchContacts(query string) []*Contact {
+// Find returns copies of the contacts the predicate accepts. The predicate
+// sees the stored contacts and must not modify them.
+func (ab *AddressBook) Find(match func(c *Contact) bool) []*Contact {
 	ab.mu.RLock()
 	defer ab.mu.RUnlock()
 
-	query = strings.ToLower(query)
 	var results []*Contact
-
 	for _, contact := range ab.contacts {
-		if strings.Contains(strings.ToLower(contact.FirstName), query) ||
-			strings.Contains(strings.ToLower(contact.LastName), query) ||
-			strings.Contains(strings.ToLower(contact.Email), query) {
-			results = append(results, contact)
+		if match(contact) {
+			results = append(results, contact.Clone())
 		}
 	}
-
 	return results
 }
+
+// SearchContacts returns the contacts matching every word of the query in
+// any field, best matches first. See Search for how matches are ranked. An
+// empty query returns every contact, in the order they were created.
+func (ab *AddressBook) SearchContacts(query string) []*Contact {
+	if strings.TrimSpace(query) == "" {
+		return ab.GetAllContacts()
+	}
+	results := ab.Search(query, 0)
+	contacts := make([]*Contact, len(results))
+	for i, result := range results {
+		contacts[i] = result.Contact
+	}
+	return contacts
+}
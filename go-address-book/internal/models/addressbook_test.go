@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"sync"
 	"testing"
 )
@@ -131,3 +132,66 @@ func TestConcurrentOperations(t *testing.T) {
 		t.Errorf("Expected 100 contacts, got %d", len(contacts))
 	}
 }
+
+func TestOptimisticConcurrency(t *testing.T) {
+	ab := NewAddressBook()
+	contact := NewContact("John", "Doe", "john@example.com", "1234567890", "123 Main St")
+	if err := ab.AddContact(contact); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	if contact.Version != 1 {
+		t.Errorf("Expected version 1 after add, got %d", contact.Version)
+	}
+
+	// Two editors load the same contact
+	first, _ := ab.GetContact(contact.ID)
+	second, _ := ab.GetContact(contact.ID)
+
+	first.Email = "first@example.com"
+	if err := ab.UpdateContact(first); err != nil {
+		t.Fatalf("Failed to update contact: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", first.Version)
+	}
+
+	second.Email = "second@example.com"
+	err := ab.UpdateContact(second)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected ConflictError for stale update, got %v", err)
+	}
+	if conflict.Expected != 1 || conflict.Actual != 2 {
+		t.Errorf("Unexpected conflict versions %d/%d", conflict.Expected, conflict.Actual)
+	}
+
+	stored, _ := ab.GetContact(contact.ID)
+	if stored.Email != "first@example.com" {
+		t.Errorf("Expected first update to win, got %s", stored.Email)
+	}
+}
+
+func TestReadsReturnCopies(t *testing.T) {
+	ab := NewAddressBook()
+	if err := ab.SetSchema(Schema{{Name: "Team", Type: FieldString}}); err != nil {
+		t.Fatalf("Failed to set schema: %v", err)
+	}
+	contact := NewContact("John", "Doe", "john@example.com", "1234567890", "123 Main St")
+	contact.SetCustom("Team", "Blue")
+	if err := ab.AddContact(contact); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+
+	contact.FirstName = "Changed"
+	retrieved, _ := ab.GetContact(contact.ID)
+	retrieved.LastName = "Changed"
+	retrieved.Custom["Team"] = "Red"
+	ab.GetAllContacts()[0].Email = "changed@example.com"
+	ab.SearchContacts("john")[0].Phone = "0"
+
+	stored, _ := ab.GetContact(contact.ID)
+	if stored.FirstName != "John" || stored.LastName != "Doe" || stored.Email != "john@example.com" ||
+		stored.Phone != "1234567890" || stored.Custom["Team"] != "Blue" {
+		t.Errorf("Expected stored contact to be unaffected by changes to copies, got %+v", stored)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "reflect"
+
+// UpdateAll applies edit to a copy of each contact and updates the ones it
+// changed in a single transaction, so either all of them are updated or
+// none are. It returns the updated contacts; contacts the edit leaves as
+// they were are skipped.
+func (ab *AddressBook) UpdateAll(ids []string, edit func(c *Contact) error) ([]*Contact, error) {
+	var changed []*Contact
+	var ops []Op
+	for _, id := range ids {
+		original, err := ab.GetContact(id)
+		if err != nil {
+			return nil, err
+		}
+		contact := original.Clone()
+		if err := edit(contact); err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(original, contact) {
+			changed = append(changed, contact)
+			ops = append(ops, UpdateOp(contact))
+		}
+	}
+	if err := ab.Apply(ops); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// DeleteAll deletes the contacts in a single transaction
+func (ab *AddressBook) DeleteAll(ids []string) error {
+	ops := make([]Op, len(ids))
+	for i, id := range ids {
+		ops[i] = DeleteOp(id)
+	}
+	return ab.Apply(ops)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	c := NewContact("Ada", "Lovelace", "", "", "")
+	c.Tags = []string{"math"}
+	if !c.AddTags("VIP", " math ", "") || strings.Join(c.Tags, ",") != "math,vip" {
+		t.Errorf("Expected vip added once, got %v", c.Tags)
+	}
+	if c.AddTags("Math") {
+		t.Error("Expected adding a tag the contact has to change nothing")
+	}
+	if !c.RemoveTags("MATH", "other") || strings.Join(c.Tags, ",") != "vip" {
+		t.Errorf("Expected math removed, got %v", c.Tags)
+	}
+	if c.RemoveTags("math") {
+		t.Error("Expected removing a missing tag to change nothing")
+	}
+}
+
+func TestUpdateAll(t *testing.T) {
+	ab := NewAddressBook()
+	if err := ab.SetSchema(Schema{{Name: "Seats", Type: FieldNumber}}); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, name := range []string{"Ada", "Grace", "Alan"} {
+		c := NewContact(name, "Test", "", "", "")
+		if name == "Grace" {
+			c.Tags = []string{"vip"}
+		}
+		if err := ab.AddContact(c); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, c.ID)
+	}
+
+	changed, err := ab.UpdateAll(ids, func(c *Contact) error {
+		c.AddTags("vip")
+		return nil
+	})
+	if err != nil || len(changed) != 2 {
+		t.Fatalf("Expected 2 contacts tagged, got %d (%v)", len(changed), err)
+	}
+	grace, _ := ab.GetContact(ids[1])
+	if grace.Version != 1 {
+		t.Error("Expected a contact the edit didn't change to be left alone")
+	}
+
+	// One invalid contact fails the whole batch
+	_, err = ab.UpdateAll(ids, func(c *Contact) error {
+		c.Title = "Changed"
+		if c.FirstName == "Alan" {
+			c.SetCustom("Seats", "many")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected the invalid custom field to fail the update")
+	}
+	for _, id := range ids {
+		if c, _ := ab.GetContact(id); c.Title != "" {
+			t.Errorf("Expected no contact updated, got %s with title %q", c.FirstName, c.Title)
+		}
+	}
+}
+
+func TestDeleteAll(t *testing.T) {
+	ab := NewAddressBook()
+	a := NewContact("Ada", "Lovelace", "", "", "")
+	b := NewContact("Grace", "Hopper", "", "", "")
+	if err := ab.Apply([]Op{AddOp(a), AddOp(b)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.DeleteAll([]string{a.ID, "missing"}); err == nil {
+		t.Fatal("Expected an unknown ID to fail the delete")
+	}
+	if len(ab.GetAllContacts()) != 2 {
+		t.Fatal("Expected nothing deleted")
+	}
+	if err := ab.DeleteAll([]string{a.ID, b.ID}); err != nil || len(ab.GetAllContacts()) != 0 {
+		t.Errorf("Expected both deleted, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// freeMailDomains are email providers that say nothing about where a person works
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "yahoo.com": true, "hotmail.com": true,
+	"outlook.com": true, "live.com": true, "msn.com": true, "icloud.com": true,
+	"me.com": true, "aol.com": true, "protonmail.com": true, "proton.me": true,
+	"gmx.com": true, "mail.com": true, "yandex.com": true, "zoho.com": true,
+}
+
+// secondLevelDomains are common registry suffixes such as the "co" in "acme.co.uk"
+var secondLevelDomains = map[string]bool{
+	"co": true, "com": true, "ac": true, "org": true, "net": true, "gov": true, "edu": true,
+}
+
+// Company groups the contacts that work at one organization
+type Company struct {
+	Name     string
+	Contacts []*Contact
+}
+
+// Company returns the contact's organization, falling back to a name
+// inferred from the email domain. It returns "" for free-mail addresses.
+func (c *Contact) Company() string {
+	if org := strings.TrimSpace(c.Organization); org != "" {
+		return org
+	}
+	return companyFromEmail(c.Email)
+}
+
+// companyFromEmail turns "jane@mail.acme.co.uk" into "Acme"
+func companyFromEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	if domain == "" || freeMailDomains[domain] {
+		return ""
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	name := labels[len(labels)-2]
+	if len(labels) >= 3 && secondLevelDomains[name] && len(labels[len(labels)-1]) == 2 {
+		name = labels[len(labels)-3]
+	}
+	if name == "" {
+		return ""
+	}
+	// Internationalized domains may start with a letter of several bytes
+	first, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// Companies groups contacts by company, sorted by company name.
+// Contacts without a known company are left out.
+func (ab *AddressBook) Companies() []Company {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	byKey := make(map[string]*Company)
+	for _, contact := range ab.contacts {
+		name := contact.Company()
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		company, exists := byKey[key]
+		if !exists {
+			company = &Company{Name: name}
+			byKey[key] = company
+		}
+		company.Contacts = append(company.Contacts, contact.Clone())
+	}
+
+	companies := make([]Company, 0, len(byKey))
+	for _, company := range byKey {
+		sortByName(company.Contacts)
+		companies = append(companies, *company)
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return strings.ToLower(companies[i].Name) < strings.ToLower(companies[j].Name)
+	})
+	return companies
+}
+
+// ContactsAtCompany returns every contact working at the named company.
+// The match is case-insensitive.
+func (ab *AddressBook) ContactsAtCompany(name string) []*Contact {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	var results []*Contact
+	for _, contact := range ab.contacts {
+		if strings.ToLower(contact.Company()) == name {
+			results = append(results, contact.Clone())
+		}
+	}
+	sortByName(results)
+	return results
+}
+
+// sortByName orders contacts by last name, then first name
+func sortByName(contacts []*Contact) {
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if !strings.EqualFold(a.LastName, b.LastName) {
+			return strings.ToLower(a.LastName) < strings.ToLower(b.LastName)
+		}
+		return strings.ToLower(a.FirstName) < strings.ToLower(b.FirstName)
+	})
+}
@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestCompanyFromEmail(t *testing.T) {
+	tests := map[string]string{
+		"jane@acme.com":          "Acme",
+		"jane@mail.globex.co.uk": "Globex",
+		"jane@émile.fr":          "Émile",
+		"jane@gmail.com":         "",
+		"jane@localhost":         "",
+		"not-an-email":           "",
+	}
+	for email, want := range tests {
+		if got := companyFromEmail(email); got != want {
+			t.Errorf("companyFromEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestCompanies(t *testing.T) {
+	ab := NewAddressBook()
+
+	withOrg := NewContact("Jane", "Smith", "jane@gmail.com", "1", "A")
+	withOrg.Organization = "Acme"
+	inferred := NewContact("John", "Doe", "john@acme.com", "2", "B")
+	other := NewContact("Ann", "Lee", "ann@initech.com", "3", "C")
+	unknown := NewContact("Bob", "Ray", "bob@yahoo.com", "4", "D")
+
+	for _, c := range []*Contact{withOrg, inferred, other, unknown} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	companies := ab.Companies()
+	if len(companies) != 2 {
+		t.Fatalf("Expected 2 companies, got %d", len(companies))
+	}
+	if companies[0].Name != "Acme" || len(companies[0].Contacts) != 2 {
+		t.Errorf("Expected Acme with 2 contacts, got %s with %d", companies[0].Name, len(companies[0].Contacts))
+	}
+	if companies[0].Contacts[0].LastName != "Doe" {
+		t.Error("Expected company contacts sorted by last name")
+	}
+
+	if got := ab.ContactsAtCompany("acme"); len(got) != 2 {
+		t.Errorf("Expected 2 contacts at acme, got %d", len(got))
+	}
+	if got := ab.ContactsAtCompany("Yahoo"); len(got) != 0 {
+		t.Errorf("Expected free-mail domains to be ignored, got %d", len(got))
+	}
+}
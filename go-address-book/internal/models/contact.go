@@ -1,8 +1,6 @@
 package models
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"time"
 )
@@ -17,6 +15,33 @@ type Contact struct {
 	Address   string    `json:"address"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version is incremented on every update and guards against lost updates
+	Version int `json:"version"`
+
+	Organization string `json:"organization,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Department   string `json:"department,omitempty"`
+
+	// Tags are lowercase labels such as "vendor" or "family"
+	Tags []string `json:"tags,omitempty"`
+
+	// Additional emails, phone numbers and addresses beyond the primary ones
+	OtherEmails    []string `json:"otherEmails,omitempty"`
+	OtherPhones    []string `json:"otherPhones,omitempty"`
+	OtherAddresses []string `json:"otherAddresses,omitempty"`
+
+	// Dates holds birthdays, anniversaries and other yearly dates
+	Dates []ImportantDate `json:"dates,omitempty"`
+
+	// Interactions is the contact's notes and call, meeting and email log, oldest first
+	Interactions []Interaction `json:"interactions,omitempty"`
+
+	// Aliases are the IDs of contacts that were merged into this one
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Custom holds values for the user-defined fields in the address book schema
+	Custom map[string]string `json:"custom,omitempty"`
 }
 
 // NewContact creates a new contact with the given information
@@ -34,6 +59,37 @@ func NewContact(firstName, lastName, email, phone, address string) *Contact {
 	}
 }
 
+// Clone returns a deep copy of the contact
+func (c *Contact) Clone() *Contact {
+	clone := *c
+	if c.Custom != nil {
+		clone.Custom = make(map[string]string, len(c.Custom))
+		for name, value := range c.Custom {
+			clone.Custom[name] = value
+		}
+	}
+	clone.Tags = append([]string(nil), c.Tags...)
+	clone.OtherEmails = append([]string(nil), c.OtherEmails...)
+	clone.OtherPhones = append([]string(nil), c.OtherPhones...)
+	clone.OtherAddresses = append([]string(nil), c.OtherAddresses...)
+	clone.Dates = append([]ImportantDate(nil), c.Dates...)
+	clone.Interactions = append([]Interaction(nil), c.Interactions...)
+	clone.Aliases = append([]string(nil), c.Aliases...)
+	return &clone
+}
+
+// SetCustom sets a custom field value, removing the field when value is empty
+func (c *Contact) SetCustom(name, value string) {
+	if value == "" {
+		delete(c.Custom, name)
+		return
+	}
+	if c.Custom == nil {
+		c.Custom = make(map[string]string)
+	}
+	c.Custom[name] = value
+}
+
 // ToJSON converts the contact to a JSON string
 func (c *Contact) ToJSON() (string, error) {
 	bytes, err := json.Marshal(c)
@@ -52,11 +108,3 @@ func FromJSON(jsonStr string) (*Contact, error) {
 	}
 	return &contact, nil
 }
-
-// generateID creates a unique ID for the contact
-func generateID() string {
-	timestamp := time.Now().Format("20060102150405")
-	randomBytes := make([]byte, 8)
-	rand.Read(randomBytes)
-	return timestamp + "-" + hex.EncodeToString(randomBytes)
-}
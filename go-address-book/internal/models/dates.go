@@ -0,0 +1,236 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known labels for important dates; any other label is allowed too
+const (
+	LabelBirthday    = "birthday"
+	LabelAnniversary = "anniversary"
+)
+
+// ImportantDate is a yearly recurring date such as a birthday.
+// Year is 0 when it is not known.
+type ImportantDate struct {
+	Label string     `json:"label"`
+	Year  int        `json:"year,omitempty"`
+	Month time.Month `json:"month"`
+	Day   int        `json:"day"`
+}
+
+// ParseImportantDate parses "YYYY-MM-DD", or "MM-DD" / "--MM-DD" for dates without a year
+func ParseImportantDate(label, value string) (ImportantDate, error) {
+	label = strings.TrimSpace(label)
+	if err := checkLabel(label); err != nil {
+		return ImportantDate{}, err
+	}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "--")
+	parts := strings.Split(value, "-")
+	var year, month, day int
+	var err error
+	switch len(parts) {
+	case 3:
+		if year, err = strconv.Atoi(parts[0]); err != nil || len(parts[0]) != 4 {
+			return ImportantDate{}, fmt.Errorf("%s: invalid year in %q", label, value)
+		}
+		parts = parts[1:]
+	case 2:
+	default:
+		return ImportantDate{}, fmt.Errorf("%s: %q is not a date (expected YYYY-MM-DD or MM-DD)", label, value)
+	}
+	if month, err = strconv.Atoi(parts[0]); err != nil {
+		return ImportantDate{}, fmt.Errorf("%s: invalid month in %q", label, value)
+	}
+	if day, err = strconv.Atoi(parts[1]); err != nil {
+		return ImportantDate{}, fmt.Errorf("%s: invalid day in %q", label, value)
+	}
+
+	date := ImportantDate{Label: label, Year: year, Month: time.Month(month), Day: day}
+	if err := date.Validate(); err != nil {
+		return ImportantDate{}, err
+	}
+	return date, nil
+}
+
+// Validate checks that the date has a label and exists. February 29 is accepted without a
+// year, but only in leap years when the year is known.
+func (d ImportantDate) Validate() error {
+	if err := checkLabel(d.Label); err != nil {
+		return err
+	}
+	if d.Month < time.January || d.Month > time.December {
+		return fmt.Errorf("%s: month %d is out of range", d.Label, d.Month)
+	}
+	year := d.Year
+	if year == 0 {
+		year = 2000 // a leap year, so --02-29 is valid
+	}
+	if d.Day < 1 || d.Day > daysIn(year, d.Month) {
+		return fmt.Errorf("%s: %s has no day %d", d.Label, d.yearString(), d.Day)
+	}
+	return nil
+}
+
+// checkLabel checks that a date label is given and can be stored
+func checkLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("date label is required")
+	}
+	if strings.ContainsAny(label, "=;") {
+		return fmt.Errorf("date label %q must not contain '=' or ';'", label)
+	}
+	return nil
+}
+
+// String formats the date as "YYYY-MM-DD", or "--MM-DD" when the year is unknown
+func (d ImportantDate) String() string {
+	if d.Year == 0 {
+		return fmt.Sprintf("--%02d-%02d", d.Month, d.Day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Next returns the next occurrence on or after the day of from. In years
+// without a February 29, that date is observed on February 28.
+func (d ImportantDate) Next(from time.Time) time.Time {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	next := d.occurrence(from.Year(), from.Location())
+	if next.Before(from) {
+		next = d.occurrence(from.Year()+1, from.Location())
+	}
+	return next
+}
+
+// YearsOn returns how many years will have passed at the occurrence on t,
+// such as the age a person turns on their birthday
+func (d ImportantDate) YearsOn(t time.Time) (int, bool) {
+	if d.Year == 0 || t.Year() < d.Year {
+		return 0, false
+	}
+	return t.Year() - d.Year, true
+}
+
+func (d ImportantDate) occurrence(year int, loc *time.Location) time.Time {
+	day := d.Day
+	if max := daysIn(year, d.Month); day > max {
+		day = max
+	}
+	return time.Date(year, d.Month, day, 0, 0, 0, 0, loc)
+}
+
+func (d ImportantDate) yearString() string {
+	if d.Year == 0 {
+		return d.Month.String()
+	}
+	return fmt.Sprintf("%s %d", d.Month, d.Year)
+}
+
+// daysIn returns the number of days in the month of the given year
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// ParseDates parses "label=date;label=date" as written by FormatDates
+func ParseDates(value string) ([]ImportantDate, error) {
+	var dates []ImportantDate
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		label, date, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("date %q must be written as label=date", entry)
+		}
+		parsed, err := ParseImportantDate(label, date)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, parsed)
+	}
+	return dates, nil
+}
+
+// FormatDates formats dates as "label=date;label=date"
+func FormatDates(dates []ImportantDate) string {
+	entries := make([]string, len(dates))
+	for i, date := range dates {
+		entries[i] = date.Label + "=" + date.String()
+	}
+	return strings.Join(entries, ";")
+}
+
+// Date returns the contact's date with the given label
+func (c *Contact) Date(label string) (ImportantDate, bool) {
+	for _, date := range c.Dates {
+		if strings.EqualFold(date.Label, label) {
+			return date, true
+		}
+	}
+	return ImportantDate{}, false
+}
+
+// SetDate replaces the contact's date with the same label, or adds it
+func (c *Contact) SetDate(date ImportantDate) {
+	for i, existing := range c.Dates {
+		if strings.EqualFold(existing.Label, date.Label) {
+			c.Dates[i] = date
+			return
+		}
+	}
+	c.Dates = append(c.Dates, date)
+}
+
+// RemoveDate removes the contact's date with the given label, reporting
+// whether there was one
+func (c *Contact) RemoveDate(label string) bool {
+	for i, existing := range c.Dates {
+		if strings.EqualFold(existing.Label, label) {
+			c.Dates = append(c.Dates[:i:i], c.Dates[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// UpcomingEvent is the next occurrence of one of a contact's dates
+type UpcomingEvent struct {
+	Contact *Contact
+	Date    ImportantDate
+	On      time.Time
+}
+
+// Years returns the age or number of years being celebrated, if the year is known
+func (e UpcomingEvent) Years() (int, bool) {
+	return e.Date.YearsOn(e.On)
+}
+
+// Upcoming returns every date occurring within days of from, soonest first
+func (ab *AddressBook) Upcoming(from time.Time, days int) []UpcomingEvent {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := start.AddDate(0, 0, days)
+
+	var events []UpcomingEvent
+	for _, contact := range ab.contacts {
+		for _, date := range contact.Dates {
+			if next := date.Next(start); !next.After(end) {
+				events = append(events, UpcomingEvent{Contact: contact.Clone(), Date: date, On: next})
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].On.Equal(events[j].On) {
+			return events[i].On.Before(events[j].On)
+		}
+		return events[i].Contact.LastName < events[j].Contact.LastName
+	})
+	return events
+}
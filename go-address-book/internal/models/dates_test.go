@@ -0,0 +1,140 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseImportantDate(t *testing.T) {
+	valid := map[string]ImportantDate{
+		"1990-06-15": {Label: "birthday", Year: 1990, Month: time.June, Day: 15},
+		"06-15":      {Label: "birthday", Month: time.June, Day: 15},
+		"--02-29":    {Label: "birthday", Month: time.February, Day: 29},
+		"2000-02-29": {Label: "birthday", Year: 2000, Month: time.February, Day: 29},
+	}
+	for value, want := range valid {
+		got, err := ParseImportantDate("birthday", value)
+		if err != nil {
+			t.Errorf("ParseImportantDate(%q): unexpected error: %v", value, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseImportantDate(%q) = %+v, want %+v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"1999-02-29", "13-01", "04-31", "1990/06/15", "90-06-15"} {
+		if _, err := ParseImportantDate("birthday", value); err == nil {
+			t.Errorf("ParseImportantDate(%q): expected error", value)
+		}
+	}
+	if _, err := ParseImportantDate("a=b", "06-15"); err == nil {
+		t.Error("Expected error for label containing '='")
+	}
+	if err := (ImportantDate{Month: time.June, Day: 15}).Validate(); err == nil {
+		t.Error("Expected error for a date without a label")
+	}
+	contact := NewContact("John", "Doe", "john@example.com", "", "")
+	contact.Dates = []ImportantDate{{Label: " ", Month: time.June, Day: 15}}
+	if err := NewAddressBook().AddContact(contact); err == nil {
+		t.Error("Expected AddContact to reject a date with a blank label")
+	}
+}
+
+func TestDatesRoundTrip(t *testing.T) {
+	dates := []ImportantDate{
+		{Label: LabelBirthday, Year: 1990, Month: time.June, Day: 15},
+		{Label: "first met", Month: time.March, Day: 3},
+	}
+	formatted := FormatDates(dates)
+	if formatted != "birthday=1990-06-15;first met=--03-03" {
+		t.Errorf("Unexpected formatted dates %q", formatted)
+	}
+	parsed, err := ParseDates(formatted)
+	if err != nil {
+		t.Fatalf("ParseDates failed: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0] != dates[0] || parsed[1] != dates[1] {
+		t.Errorf("Round trip mismatch: %+v", parsed)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	leap := ImportantDate{Label: LabelBirthday, Year: 1996, Month: time.February, Day: 29}
+
+	next := leap.Next(time.Date(2025, time.January, 10, 15, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("Expected Feb 29 to be observed on %v in a common year, got %v", want, next)
+	}
+	next = leap.Next(time.Date(2028, time.January, 10, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("Expected %v in a leap year, got %v", want, next)
+	}
+	if age, ok := leap.YearsOn(next); !ok || age != 32 {
+		t.Errorf("Expected age 32, got %d (%v)", age, ok)
+	}
+
+	// Today counts as upcoming; yesterday rolls over to next year
+	birthday := ImportantDate{Label: LabelBirthday, Month: time.March, Day: 5}
+	today := time.Date(2025, time.March, 5, 23, 0, 0, 0, time.UTC)
+	if next := birthday.Next(today); next.Year() != 2025 {
+		t.Errorf("Expected today's birthday to be upcoming, got %v", next)
+	}
+	if next := birthday.Next(today.AddDate(0, 0, 1)); next.Year() != 2026 {
+		t.Errorf("Expected a passed birthday to roll over, got %v", next)
+	}
+	if _, ok := birthday.YearsOn(today); ok {
+		t.Error("Expected no age for a birthday without a year")
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	ab := NewAddressBook()
+	from := time.Date(2025, time.December, 20, 0, 0, 0, 0, time.UTC)
+
+	soon := NewContact("Jane", "Smith", "jane@example.com", "1", "A")
+	soon.SetDate(ImportantDate{Label: LabelBirthday, Year: 1980, Month: time.January, Day: 5})
+	sooner := NewContact("John", "Doe", "john@example.com", "2", "B")
+	sooner.SetDate(ImportantDate{Label: LabelAnniversary, Month: time.December, Day: 24})
+	later := NewContact("Ann", "Lee", "ann@example.com", "3", "C")
+	later.SetDate(ImportantDate{Label: LabelBirthday, Month: time.March, Day: 1})
+
+	for _, c := range []*Contact{soon, sooner, later} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	events := ab.Upcoming(from, 30)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 upcoming events, got %d", len(events))
+	}
+	if events[0].Contact.ID != sooner.ID || events[1].Contact.ID != soon.ID {
+		t.Error("Expected events ordered by date across the year boundary")
+	}
+	if years, ok := events[1].Years(); !ok || years != 46 {
+		t.Errorf("Expected next age 46, got %d (%v)", years, ok)
+	}
+
+	bad := NewContact("Bad", "Date", "bad@example.com", "4", "D")
+	bad.Dates = []ImportantDate{{Label: LabelBirthday, Year: 2001, Month: time.February, Day: 29}}
+	if err := ab.AddContact(bad); err == nil {
+		t.Error("Expected error for an invalid date")
+	}
+}
+
+func TestRemoveDate(t *testing.T) {
+	c := NewContact("Jane", "Smith", "", "", "")
+	c.SetDate(ImportantDate{Label: LabelBirthday, Month: time.May, Day: 1})
+	c.SetDate(ImportantDate{Label: "graduation", Month: time.June, Day: 2})
+
+	if !c.RemoveDate("Birthday") {
+		t.Error("Expected the birthday to be removed, ignoring case")
+	}
+	if c.RemoveDate(LabelBirthday) {
+		t.Error("Expected nothing to remove the second time")
+	}
+	if len(c.Dates) != 1 || c.Dates[0].Label != "graduation" {
+		t.Errorf("Expected only the graduation date to remain, got %v", c.Dates)
+	}
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"math"
+	"sort"
+
+	"github.com/rushi/address-book-cli/internal/match"
+)
+
+// DefaultDuplicateScore is the confidence above which two contacts are
+// reported as likely duplicates
+const DefaultDuplicateScore = 0.8
+
+// Confidence that two contacts are the same person, per matching signal.
+// A name or a company alone stays below DefaultDuplicateScore, since
+// unrelated people often share them; together they pass it.
+const (
+	scoreSameEmail   = 0.95
+	scoreSamePhone   = 0.9
+	scoreSimilarName = 0.6
+	scoreSameCompany = 0.6
+)
+
+// similarNameThreshold is the Jaro-Winkler similarity two names need to count as similar
+const similarNameThreshold = 0.9
+
+// DuplicateCluster is a group of contacts that are likely the same person
+type DuplicateCluster struct {
+	Contacts []*Contact
+	Score    float64  // average confidence of the matches that formed the cluster
+	Reasons  []string // the signals that matched, such as "same email"
+}
+
+// duplicatePair is a scored match between two contacts
+type duplicatePair struct {
+	a, b    string
+	score   float64
+	reasons []string
+}
+
+// FindDuplicates groups contacts that are likely duplicates, based on
+// normalized emails and phone numbers, on company and on name similarity
+// with nickname folding. Pairs scoring below minScore are ignored. Clusters are returned
+// most confident first.
+func (ab *AddressBook) FindDuplicates(minScore float64) []DuplicateCluster {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	var pairs []duplicatePair
+	for _, candidate := range ab.duplicateCandidates() {
+		a, b := ab.contacts[candidate[0]], ab.contacts[candidate[1]]
+		if pair := scoreDuplicate(a, b); pair.score >= minScore {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	// Union-find over matching pairs; each root becomes a cluster
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		if p, ok := parent[id]; ok && p != id {
+			root := find(p)
+			parent[id] = root
+			return root
+		}
+		parent[id] = id
+		return id
+	}
+	for _, pair := range pairs {
+		ra, rb := find(pair.a), find(pair.b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	type accumulator struct {
+		ids     map[string]bool
+		total   float64
+		count   int
+		reasons map[string]bool
+	}
+	byRoot := make(map[string]*accumulator)
+	for _, pair := range pairs {
+		root := find(pair.a)
+		acc, ok := byRoot[root]
+		if !ok {
+			acc = &accumulator{ids: make(map[string]bool), reasons: make(map[string]bool)}
+			byRoot[root] = acc
+		}
+		acc.ids[pair.a], acc.ids[pair.b] = true, true
+		acc.total += pair.score
+		acc.count++
+		for _, reason := range pair.reasons {
+			acc.reasons[reason] = true
+		}
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(byRoot))
+	for _, acc := range byRoot {
+		cluster := DuplicateCluster{Score: acc.total / float64(acc.count)}
+		for id := range acc.ids {
+			cluster.Contacts = append(cluster.Contacts, ab.contacts[id].Clone())
+		}
+		sort.Slice(cluster.Contacts, func(i, j int) bool {
+			return cluster.Contacts[i].CreatedAt.Before(cluster.Contacts[j].CreatedAt)
+		})
+		for reason := range acc.reasons {
+			cluster.Reasons = append(cluster.Reasons, reason)
+		}
+		sort.Strings(cluster.Reasons)
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].Contacts[0].ID < clusters[j].Contacts[0].ID
+	})
+	return clusters
+}
+
+// duplicateCandidates returns the ID pairs worth scoring. Contacts are only
+// compared when they share an email, a phone number or a coarse name key,
+// which keeps the work far below comparing every pair.
+func (ab *AddressBook) duplicateCandidates() [][2]string {
+	blocks := make(map[string][]string)
+	for id, contact := range ab.contacts {
+		for _, key := range blockingKeys(contact) {
+			blocks[key] = append(blocks[key], id)
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	var candidates [][2]string
+	for _, ids := range blocks {
+		sort.Strings(ids)
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				pair := [2]string{ids[i], ids[j]}
+				if !seen[pair] {
+					seen[pair] = true
+					candidates = append(candidates, pair)
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// blockingKeys returns the keys under which a contact is compared to others
+func blockingKeys(c *Contact) []string {
+	var keys []string
+	if email := match.NormalizeEmail(c.Email); email != "" {
+		keys = append(keys, "email:"+email)
+	}
+	if phone := match.NormalizePhone(c.Phone); phone != "" {
+		keys = append(keys, "phone:"+phone)
+	}
+	first, last := match.CanonicalFirstName(c.FirstName), match.NormalizeName(c.LastName)
+	if first != "" && last != "" {
+		byLast := "name:" + prefix(last, 2) + "|" + prefix(first, 1)
+		byFirst := "name:" + prefix(first, 2) + "|" + prefix(last, 1)
+		keys = append(keys, byLast)
+		if byFirst != byLast {
+			keys = append(keys, byFirst)
+		}
+	}
+	return keys
+}
+
+// scoreDuplicate combines the matching signals between two contacts. Each
+// signal is treated as independent evidence, so two weak signals together
+// score higher than either alone.
+func scoreDuplicate(a, b *Contact) duplicatePair {
+	pair := duplicatePair{a: a.ID, b: b.ID}
+	var scores []float64
+
+	if email := match.NormalizeEmail(a.Email); email != "" && email == match.NormalizeEmail(b.Email) {
+		scores = append(scores, scoreSameEmail)
+		pair.reasons = append(pair.reasons, "same email")
+	}
+	if phone := match.NormalizePhone(a.Phone); phone != "" && phone == match.NormalizePhone(b.Phone) {
+		scores = append(scores, scoreSamePhone)
+		pair.reasons = append(pair.reasons, "same phone")
+	}
+	if company := match.NormalizeName(a.Organization); company != "" && company == match.NormalizeName(b.Organization) {
+		scores = append(scores, scoreSameCompany)
+		pair.reasons = append(pair.reasons, "same company")
+	}
+	if similarity := nameSimilarity(a, b); similarity >= similarNameThreshold {
+		scores = append(scores, scoreSimilarName*similarity)
+		if similarity == 1 {
+			pair.reasons = append(pair.reasons, "same name")
+		} else {
+			pair.reasons = append(pair.reasons, "similar name")
+		}
+	}
+
+	miss := 1.0
+	for _, score := range scores {
+		miss *= 1 - score
+	}
+	pair.score = math.Round((1-miss)*1000) / 1000
+	return pair
+}
+
+// nameSimilarity compares first names after nickname folding and last names
+// as written, averaging their Jaro-Winkler similarity
+func nameSimilarity(a, b *Contact) float64 {
+	firstA, firstB := match.CanonicalFirstName(a.FirstName), match.CanonicalFirstName(b.FirstName)
+	lastA, lastB := match.NormalizeName(a.LastName), match.NormalizeName(b.LastName)
+	if firstA == "" || firstB == "" || lastA == "" || lastB == "" {
+		return 0
+	}
+	return (match.JaroWinkler(firstA, firstB) + match.JaroWinkler(lastA, lastB)) / 2
+}
+
+// prefix returns up to n leading runes of s
+func prefix(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		r = r[:n]
+	}
+	return string(r)
+}
@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestFindDuplicates(t *testing.T) {
+	ab := NewAddressBook()
+	robert := NewContact("Robert", "Smith", "robert.smith@gmail.com", "(555) 010-2000", "A")
+	bob := NewContact("Bob", "Smith", "robertsmith+news@gmail.com", "555-999-0000", "B")
+	phoneTwin := NewContact("R.", "Smithe", "rs@acme.com", "+1 555 010 2000", "C")
+	typo := NewContact("Jane", "Johnsen", "jane@a.com", "555-111-2222", "D")
+	typo.Organization = "Acme Corp"
+	jane := NewContact("Jane", "Johnson", "jane@b.com", "555-333-4444", "E")
+	jane.Organization = "ACME corp."
+	stranger := NewContact("Ann", "Lee", "ann@c.com", "555-555-5555", "F")
+	for _, c := range []*Contact{robert, bob, phoneTwin, typo, jane, stranger} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	clusters := ab.FindDuplicates(DefaultDuplicateScore)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d", len(clusters))
+	}
+
+	smiths := clusters[0]
+	if len(smiths.Contacts) != 3 {
+		t.Errorf("Expected the three Smith records in one cluster, got %d", len(smiths.Contacts))
+	}
+	if smiths.Contacts[0].ID != robert.ID {
+		t.Error("Expected cluster contacts ordered oldest first")
+	}
+	if smiths.Score < clusters[1].Score {
+		t.Error("Expected clusters ordered by confidence")
+	}
+
+	johnsons := clusters[1]
+	if len(johnsons.Contacts) != 2 || len(johnsons.Reasons) != 2 || johnsons.Reasons[0] != "same company" || johnsons.Reasons[1] != "similar name" {
+		t.Errorf("Expected Johnson/Johnsen matched on company and similar name, got %v", johnsons.Reasons)
+	}
+
+	if clusters := ab.FindDuplicates(0.9); len(clusters) != 1 {
+		t.Errorf("Expected only the strongest cluster at a high threshold, got %d", len(clusters))
+	}
+}
+
+func TestScoreDuplicateCombinesSignals(t *testing.T) {
+	a := NewContact("John", "Doe", "john@example.com", "555-010-2000", "A")
+	sameEmail := NewContact("Someone", "Else", "JOHN@example.com", "555-999-0000", "B")
+	everything := NewContact("Johnny", "Doe", "john@example.com", "5550102000", "C")
+
+	emailOnly := scoreDuplicate(a, sameEmail)
+	all := scoreDuplicate(a, everything)
+	if emailOnly.score != scoreSameEmail {
+		t.Errorf("Expected email-only score %.2f, got %.3f", scoreSameEmail, emailOnly.score)
+	}
+	if all.score <= emailOnly.score || all.score > 1 {
+		t.Errorf("Expected combined signals to score higher, got %.3f", all.score)
+	}
+	if len(all.reasons) != 3 {
+		t.Errorf("Expected 3 reasons, got %v", all.reasons)
+	}
+}
+
+func TestFindDuplicatesIgnoresSingleContacts(t *testing.T) {
+	ab := NewAddressBook()
+	// First and last name share their leading letters, so both name keys coincide
+	if err := ab.AddContact(NewContact("Joseph", "Johnson", "jj@example.com", "555-010-2000", "A")); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	if clusters := ab.FindDuplicates(DefaultDuplicateScore); len(clusters) != 0 {
+		t.Errorf("Expected no clusters for a single contact, got %d", len(clusters))
+	}
+}
+
+func TestFindDuplicatesNeedsMoreThanAName(t *testing.T) {
+	ab := NewAddressBook()
+	first := NewContact("John", "Smith", "john@acme.com", "555-010-2000", "1 Main St")
+	first.Organization = "Acme"
+	second := NewContact("John", "Smith", "jsmith@example.org", "555-777-8888", "9 Elm St")
+	second.Organization = "Globex"
+	for _, c := range []*Contact{first, second} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	if clusters := ab.FindDuplicates(DefaultDuplicateScore); len(clusters) != 0 {
+		t.Errorf("Expected two people sharing only a name not reported, got %v", clusters[0].Reasons)
+	}
+	if pair := scoreDuplicate(first, second); pair.score != scoreSimilarName {
+		t.Errorf("Expected a name-only score of %.2f, got %.3f", scoreSimilarName, pair.score)
+	}
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"sync"
+)
+
+// EventType is the kind of change an Event reports
+type EventType string
+
+const (
+	EventAdded    EventType = "added"
+	EventUpdated  EventType = "updated"
+	EventDeleted  EventType = "deleted"
+	EventLinked   EventType = "linked"
+	EventUnlinked EventType = "unlinked"
+)
+
+// Event describes one change to the address book. Before is nil for added
+// contacts and After is nil for deleted ones; link events carry Link instead.
+type Event struct {
+	Seq    uint64 // increases by one with every event
+	Batch  uint64 // shared by all events from the same Apply call
+	Final  bool   // set on the last event of its batch
+	Type   EventType
+	ID     string
+	Before *Contact
+	After  *Contact
+	Link   *Link
+}
+
+// clone gives each subscriber its own copy of the contacts in the event
+func (e Event) clone() Event {
+	if e.Before != nil {
+		e.Before = e.Before.Clone()
+	}
+	if e.After != nil {
+		e.After = e.After.Clone()
+	}
+	if e.Link != nil {
+		link := *e.Link
+		e.Link = &link
+	}
+	return e
+}
+
+// maxQueued is how many undelivered events a subscriber may fall behind
+// before it is disconnected
+var maxQueued = 10000
+
+// ErrSlowSubscriber is reported by Subscription.Err when the subscriber fell
+// too far behind and was disconnected
+var ErrSlowSubscriber = errors.New("subscriber fell too far behind and was disconnected")
+
+// Subscription receives address book events in the order they happened.
+// Events are queued per subscriber, so a slow reader never blocks changes
+// to the address book. A subscriber that falls more than maxQueued events
+// behind is disconnected rather than left to grow its queue: it receives
+// the whole batches queued so far, then its channel is closed and Err
+// returns ErrSlowSubscriber, so it knows to reload instead of missing
+// events unnoticed.
+type Subscription struct {
+	ab     *AddressBook
+	events chan Event
+
+	mu    sync.Mutex
+	queue []Event
+	err   error // set when disconnected for falling behind
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Subscribe starts delivering events for every change made after it returns.
+// Call Close when done to release the subscription.
+func (ab *AddressBook) Subscribe() *Subscription {
+	sub := &Subscription{
+		ab:     ab,
+		events: make(chan Event),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	ab.mu.Lock()
+	ab.subscribers = append(ab.subscribers, sub)
+	ab.mu.Unlock()
+
+	go sub.run()
+	return sub
+}
+
+// Events returns the channel events are delivered on. It is closed after
+// Close, or once the events queued before a disconnect are delivered.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns ErrSlowSubscriber once the subscriber has been disconnected
+// for falling behind, and nil otherwise
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops delivery and discards undelivered events
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.ab.mu.Lock()
+		for i, sub := range s.ab.subscribers {
+			if sub == s {
+				s.ab.subscribers = append(s.ab.subscribers[:i], s.ab.subscribers[i+1:]...)
+				break
+			}
+		}
+		s.ab.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// push queues events without waiting for the subscriber. It reports false,
+// queuing none of the events, when they would put the subscriber more than
+// maxQueued events behind.
+func (s *Subscription) push(events []Event) bool {
+	s.mu.Lock()
+	ok := len(s.queue)+len(events) <= maxQueued
+	if ok {
+		s.queue = append(s.queue, events...)
+	} else {
+		s.err = ErrSlowSubscriber
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return ok
+}
+
+// run delivers queued events one at a time until Close is called or the
+// queue of a disconnected subscriber runs out
+func (s *Subscription) run() {
+	defer close(s.events)
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			disconnected := s.err != nil
+			s.mu.Unlock()
+			if disconnected {
+				return
+			}
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		// The event stays queued until delivered, so it counts toward maxQueued
+		event := s.queue[0]
+		s.mu.Unlock()
+
+		select {
+		case s.events <- event.clone():
+		case <-s.done:
+			return
+		}
+		s.mu.Lock()
+		s.queue[0] = Event{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+	}
+}
+
+// publish numbers events as one batch and hands them to every subscriber.
+// It must be called with ab.mu held so events are queued in commit order.
+func (ab *AddressBook) publish(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	ab.batch++
+	for i := range events {
+		ab.seq++
+		events[i].Seq = ab.seq
+		events[i].Batch = ab.batch
+	}
+	events[len(events)-1].Final = true
+
+	kept := ab.subscribers[:0]
+	for _, sub := range ab.subscribers {
+		if sub.push(events) {
+			kept = append(kept, sub)
+		}
+	}
+	clear(ab.subscribers[len(kept):])
+	ab.subscribers = kept
+}
@@ -0,0 +1,223 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// nextEvent reads one event or fails the test after a timeout
+func nextEvent(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatal("Subscription closed unexpectedly")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	ab := NewAddressBook()
+	sub := ab.Subscribe()
+	defer sub.Close()
+
+	contact := NewContact("John", "Doe", "john@example.com", "1", "A")
+	other := NewContact("Jane", "Doe", "jane@example.com", "2", "B")
+	if err := ab.AddContact(contact); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	contact.Email = "new@example.com"
+	if err := ab.Apply([]Op{UpdateOp(contact), AddOp(other)}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := ab.DeleteContact(other.ID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+
+	added := nextEvent(t, sub)
+	if added.Type != EventAdded || added.Before != nil || added.After.ID != contact.ID || !added.Final {
+		t.Errorf("Unexpected add event %+v", added)
+	}
+
+	updated := nextEvent(t, sub)
+	if updated.Type != EventUpdated || updated.Before.Email != "john@example.com" || updated.After.Email != "new@example.com" {
+		t.Errorf("Unexpected update event %+v", updated)
+	}
+	batchAdd := nextEvent(t, sub)
+	if batchAdd.Batch != updated.Batch || updated.Final || !batchAdd.Final {
+		t.Error("Expected both ops of one Apply to share a batch, with only the last marked final")
+	}
+
+	deleted := nextEvent(t, sub)
+	if deleted.Type != EventDeleted || deleted.After != nil || deleted.Before.ID != other.ID {
+		t.Errorf("Unexpected delete event %+v", deleted)
+	}
+	if deleted.Seq != added.Seq+3 || deleted.Batch != added.Batch+2 {
+		t.Errorf("Expected consecutive sequence numbers, got %d after %d", deleted.Seq, added.Seq)
+	}
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	ab := NewAddressBook()
+	slow := ab.Subscribe()
+	defer slow.Close()
+
+	// Nobody reads from slow while the changes are made
+	const n = 500
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			if err := ab.AddContact(NewContact("Test", "User", "t@example.com", "1", "A")); err != nil {
+				t.Errorf("Failed to add contact: %v", err)
+			}
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Changes blocked on a subscriber that is not reading")
+	}
+
+	var last uint64
+	for i := 0; i < n; i++ {
+		event := nextEvent(t, slow)
+		if event.Seq <= last {
+			t.Fatalf("Events out of order: %d after %d", event.Seq, last)
+		}
+		last = event.Seq
+	}
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	defer func(limit int) { maxQueued = limit }(maxQueued)
+	maxQueued = 3
+
+	ab := NewAddressBook()
+	slow := ab.Subscribe()
+	defer slow.Close()
+	reader := ab.Subscribe()
+	defer reader.Close()
+
+	// Nobody reads from slow while two batches of two fill its queue
+	for i := 0; i < 2; i++ {
+		ops := []Op{AddOp(NewContact("Test", "User", "", "", "")), AddOp(NewContact("Test", "User", "", "", ""))}
+		if err := ab.Apply(ops); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		nextEvent(t, reader)
+		nextEvent(t, reader)
+	}
+	if err := ab.AddContact(NewContact("Test", "User", "", "", "")); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+
+	// It gets the first batch whole, then its channel closes
+	for i := 0; i < 2; i++ {
+		if event := nextEvent(t, slow); event.Final != (i == 1) {
+			t.Errorf("Unexpected event %+v", event)
+		}
+	}
+	select {
+	case event, ok := <-slow.Events():
+		if ok {
+			t.Errorf("Expected the channel closed, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected events channel to be closed")
+	}
+	if slow.Err() != ErrSlowSubscriber {
+		t.Errorf("Expected ErrSlowSubscriber, got %v", slow.Err())
+	}
+
+	// A subscriber keeping up is unaffected
+	if event := nextEvent(t, reader); event.Type != EventAdded || reader.Err() != nil {
+		t.Errorf("Unexpected event %+v, err %v", event, reader.Err())
+	}
+}
+
+func TestDeleteReportsUnlinks(t *testing.T) {
+	ab := NewAddressBook()
+	john := NewContact("John", "Doe", "", "", "")
+	jane := NewContact("Jane", "Doe", "", "", "")
+	boss := NewContact("Alice", "Boss", "", "", "")
+	for _, c := range []*Contact{john, jane, boss} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+	if err := ab.AddLink(john.ID, RelationSpouse, jane.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+	if err := ab.AddLink(john.ID, RelationManager, boss.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+
+	sub := ab.Subscribe()
+	defer sub.Close()
+	if err := ab.DeleteContact(john.ID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+	if deleted := nextEvent(t, sub); deleted.Type != EventDeleted || deleted.Final {
+		t.Errorf("Unexpected delete event %+v", deleted)
+	}
+	// The spouse link is stored both ways but reported once
+	var unlinked []Link
+	for {
+		event := nextEvent(t, sub)
+		if event.Type != EventUnlinked {
+			t.Fatalf("Expected an unlinked event, got %+v", event)
+		}
+		unlinked = append(unlinked, *event.Link)
+		if event.Final {
+			break
+		}
+	}
+	if len(unlinked) != 2 || unlinked[0].Type != RelationManager || unlinked[1].Type != RelationSpouse {
+		t.Errorf("Expected an unlinked event per link, got %v", unlinked)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	ab := NewAddressBook()
+	sub := ab.Subscribe()
+	sub.Close()
+	sub.Close()
+
+	if err := ab.AddContact(NewContact("John", "Doe", "j@example.com", "1", "A")); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	select {
+	case _, ok := <-sub.Events():
+		if ok {
+			t.Error("Expected no events after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected events channel to be closed")
+	}
+}
+
+func TestSubscriberGetsCopies(t *testing.T) {
+	ab := NewAddressBook()
+	first := ab.Subscribe()
+	defer first.Close()
+	second := ab.Subscribe()
+	defer second.Close()
+
+	if err := ab.AddContact(NewContact("John", "Doe", "j@example.com", "1", "A")); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	a := nextEvent(t, first)
+	a.After.FirstName = "Changed"
+	b := nextEvent(t, second)
+	if b.After.FirstName != "John" {
+		t.Error("Expected each subscriber to receive its own copy")
+	}
+	if stored, _ := ab.GetContact(a.ID); stored.FirstName != "John" {
+		t.Error("Expected stored contact unaffected by event changes")
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType identifies the kind of value a custom field holds
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldDate   FieldType = "date"
+	FieldURL    FieldType = "url"
+	FieldEnum   FieldType = "enum"
+)
+
+// DateLayout is the layout used for date-typed custom field values
+const DateLayout = "2006-01-02"
+
+// FieldDef describes a user-defined field stored alongside the core contact data
+type FieldDef struct {
+	Name    string    `json:"name"`
+	Type    FieldType `json:"type"`
+	Options []string  `json:"options,omitempty"`
+}
+
+// Hint describes the values the field accepts, for prompts
+func (f FieldDef) Hint() string {
+	switch f.Type {
+	case FieldDate:
+		return "YYYY-MM-DD"
+	case FieldEnum:
+		return strings.Join(f.Options, "/")
+	default:
+		return string(f.Type)
+	}
+}
+
+// Check validates a single value against the field definition
+func (f FieldDef) Check(value string) error {
+	switch f.Type {
+	case FieldString:
+		return nil
+	case FieldNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: %q is not a number", f.Name, value)
+		}
+	case FieldDate:
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			return fmt.Errorf("%s: %q is not a date (expected YYYY-MM-DD)", f.Name, value)
+		}
+	case FieldURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s: %q is not an absolute URL", f.Name, value)
+		}
+	case FieldEnum:
+		for _, option := range f.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %q is not one of %s", f.Name, value, strings.Join(f.Options, ", "))
+	default:
+		return fmt.Errorf("%s: unknown field type %q", f.Name, f.Type)
+	}
+	return nil
+}
+
+// Schema is the ordered set of custom fields contacts may carry
+type Schema []FieldDef
+
+// Lookup returns the definition of the named field
+func (s Schema) Lookup(name string) (FieldDef, bool) {
+	for _, field := range s {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return FieldDef{}, false
+}
+
+// Validate checks the field definitions themselves
+func (s Schema) Validate() error {
+	seen := make(map[string]bool, len(s))
+	for _, field := range s {
+		if strings.TrimSpace(field.Name) == "" {
+			return fmt.Errorf("custom field name is required")
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("custom field %q is defined more than once", field.Name)
+		}
+		seen[field.Name] = true
+
+		switch field.Type {
+		case FieldString, FieldNumber, FieldDate, FieldURL:
+		case FieldEnum:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("enum field %q needs at least one option", field.Name)
+			}
+		default:
+			return fmt.Errorf("custom field %q has unknown type %q", field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+// Check validates a contact's custom values against the schema.
+// Empty values are allowed; unknown field names are not.
+func (s Schema) Check(values map[string]string) error {
+	for name, value := range values {
+		field, ok := s.Lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown custom field %q", name)
+		}
+		if value == "" {
+			continue
+		}
+		if err := field.Check(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import "testing"
+
+func TestFieldDefCheck(t *testing.T) {
+	tests := []struct {
+		field FieldDef
+		value string
+		valid bool
+	}{
+		{FieldDef{Name: "Slack", Type: FieldString}, "@jane", true},
+		{FieldDef{Name: "Seats", Type: FieldNumber}, "12.5", true},
+		{FieldDef{Name: "Seats", Type: FieldNumber}, "twelve", false},
+		{FieldDef{Name: "Renewal", Type: FieldDate}, "2024-02-29", true},
+		{FieldDef{Name: "Renewal", Type: FieldDate}, "02/29/2024", false},
+		{FieldDef{Name: "Site", Type: FieldURL}, "https://example.com/a", true},
+		{FieldDef{Name: "Site", Type: FieldURL}, "example.com", false},
+		{FieldDef{Name: "Tier", Type: FieldEnum, Options: []string{"gold", "silver"}}, "gold", true},
+		{FieldDef{Name: "Tier", Type: FieldEnum, Options: []string{"gold", "silver"}}, "bronze", false},
+	}
+
+	for _, tt := range tests {
+		err := tt.field.Check(tt.value)
+		if tt.valid && err != nil {
+			t.Errorf("%s(%q): unexpected error: %v", tt.field.Name, tt.value, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s(%q): expected error", tt.field.Name, tt.value)
+		}
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	valid := Schema{
+		{Name: "Account ID", Type: FieldString},
+		{Name: "Tier", Type: FieldEnum, Options: []string{"gold"}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid schema, got %v", err)
+	}
+
+	invalid := []Schema{
+		{{Name: "", Type: FieldString}},
+		{{Name: "A", Type: FieldString}, {Name: "A", Type: FieldNumber}},
+		{{Name: "Tier", Type: FieldEnum}},
+		{{Name: "A", Type: "color"}},
+	}
+	for _, schema := range invalid {
+		if err := schema.Validate(); err == nil {
+			t.Errorf("Expected error for schema %+v", schema)
+		}
+	}
+}
+
+func TestAddressBookCustomFields(t *testing.T) {
+	ab := NewAddressBook()
+	if err := ab.SetSchema(Schema{
+		{Name: "Account ID", Type: FieldNumber},
+		{Name: "Slack handle", Type: FieldString},
+	}); err != nil {
+		t.Fatalf("Failed to set schema: %v", err)
+	}
+
+	contact := NewContact("John", "Doe", "john@example.com", "1234567890", "123 Main St")
+	contact.SetCustom("Account ID", "4711")
+	contact.SetCustom("Slack handle", "@jdoe")
+	if err := ab.AddContact(contact); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+
+	if results := ab.SearchContacts("@jdoe"); len(results) != 1 {
+		t.Errorf("Expected 1 search result for custom value, got %d", len(results))
+	}
+
+	bad := NewContact("Jane", "Doe", "jane@example.com", "1234567890", "123 Main St")
+	bad.SetCustom("Account ID", "not-a-number")
+	if err := ab.AddContact(bad); err == nil {
+		t.Error("Expected error for invalid custom value")
+	}
+
+	unknown := NewContact("Jim", "Doe", "jim@example.com", "1234567890", "123 Main St")
+	unknown.SetCustom("Favorite color", "blue")
+	if err := ab.AddContact(unknown); err == nil {
+		t.Error("Expected error for unknown custom field")
+	}
+
+	contact.SetCustom("Slack handle", "")
+	if _, ok := contact.Custom["Slack handle"]; ok {
+		t.Error("Expected empty value to remove the custom field")
+	}
+}
+
+func TestSchemaIsCopied(t *testing.T) {
+	ab := NewAddressBook()
+	if err := ab.SetSchema(Schema{{Name: "Tier", Type: FieldString}}); err != nil {
+		t.Fatal(err)
+	}
+	schema := ab.Schema()
+	schema[0].Name = "Changed"
+	if ab.Schema()[0].Name != "Tier" {
+		t.Error("changing the returned schema changed the address book's")
+	}
+}
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet used for IDs. It leaves out
+// I, L, O and U so IDs can be read aloud and typed without confusion.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// MinIDPrefix is the shortest prefix ResolveID accepts
+const MinIDPrefix = 4
+
+// idGenerator produces ULIDs: a 48-bit millisecond timestamp followed by
+// 80 random bits. IDs made within the same millisecond increment the random
+// part, so IDs always sort in creation order.
+type idGenerator struct {
+	mu     sync.Mutex
+	lastMs uint64
+	random [10]byte
+}
+
+var ids idGenerator
+
+// generateID creates a unique, time-sortable ID for a contact
+func generateID() string {
+	id, err := ids.next(time.Now())
+	if err != nil {
+		// Without a working random source no ID can be guaranteed unique
+		panic(fmt.Sprintf("models: cannot generate contact ID: %v", err))
+	}
+	return id
+}
+
+func (g *idGenerator) next(now time.Time) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(now.UnixMilli())
+	if ms <= g.lastMs {
+		// Same millisecond (or the clock stepped back): stay monotonic
+		ms = g.lastMs
+		if !increment(g.random[:]) {
+			ms++
+			if _, err := rand.Read(g.random[:]); err != nil {
+				return "", err
+			}
+		}
+	} else if _, err := rand.Read(g.random[:]); err != nil {
+		return "", err
+	}
+	g.lastMs = ms
+
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(raw[6:], g.random[:])
+	return encodeULID(raw), nil
+}
+
+// increment adds one to a big-endian number, reporting false on overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID encodes 128 bits as 26 Crockford base32 characters
+func encodeULID(raw [16]byte) string {
+	var out [26]byte
+	// 130 bits of output for 128 bits of input: the first character carries 3 bits
+	var acc uint64
+	bits := 2
+	pos := 0
+	for _, b := range raw {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockford[(acc>>uint(bits))&31]
+			pos++
+		}
+	}
+	return string(out[:])
+}
+
+// IsID reports whether s is written like a contact ID: 26 characters of
+// the Crockford alphabet
+func IsID(s string) bool {
+	return len(s) == 26 && strings.Trim(s, crockford) == ""
+}
+
+// legacyIDLayout is the local timestamp that starts the IDs contacts got
+// before IDs were ULIDs, such as "20240101120000-0a1b2c3d4e5f6071"
+const legacyIDLayout = "20060102150405"
+
+// IsLegacyID reports whether s is written like an ID from before IDs were
+// ULIDs: a timestamp, a dash and 16 hex digits
+func IsLegacyID(s string) bool {
+	stamp, random, ok := strings.Cut(s, "-")
+	if !ok || len(random) != 16 || strings.Trim(random, "0123456789abcdef") != "" {
+		return false
+	}
+	_, err := time.ParseInLocation(legacyIDLayout, stamp, time.Local)
+	return err == nil
+}
+
+// creationKey returns a key that sorts IDs in the order their contacts were
+// created. A legacy ID is keyed by the ULID timestamp of the second it was
+// made in, so it sorts among current IDs rather than after all of them.
+func creationKey(id string) string {
+	if !IsLegacyID(id) {
+		return id
+	}
+	created, _ := time.ParseInLocation(legacyIDLayout, id[:len(legacyIDLayout)], time.Local)
+	ms := uint64(created.UnixMilli())
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (40 - 8*i))
+	}
+	return encodeULID(raw)[:10] + id
+}
+
+// normalizeID uppercases user input and maps the characters Crockford base32
+// treats as look-alikes (I, L and O) to the digits they stand for
+func normalizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 128 {
+			return rune(normalizeIDByte(byte(r)))
+		}
+		return r
+	}, strings.TrimSpace(id))
+}
+
+func normalizeIDByte(b byte) byte {
+	switch b {
+	case 'i', 'I', 'l', 'L':
+		return '1'
+	case 'o', 'O':
+		return '0'
+	}
+	if b >= 'a' && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
+
+// AmbiguousIDError is returned when an ID prefix matches more than one contact
+type AmbiguousIDError struct {
+	Prefix  string
+	Matches []string
+}
+
+func (e *AmbiguousIDError) Error() string {
+	return fmt.Sprintf("ID prefix %q matches %d contacts", e.Prefix, len(e.Matches))
+}
+
+// ResolveID expands an ID or unambiguous ID prefix into a full contact ID.
+// Matching ignores case and Crockford look-alike characters, so older
+// timestamp-style IDs resolve the same way as current ones.
+func (ab *AddressBook) ResolveID(input string) (string, error) {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	input = strings.TrimSpace(input)
+	if _, exists := ab.contacts[input]; exists {
+		return input, nil
+	}
+	if target, exists := ab.aliases[input]; exists {
+		return target, nil
+	}
+
+	prefix := normalizeID(input)
+	if len(prefix) < MinIDPrefix {
+		return "", fmt.Errorf("ID prefix must be at least %d characters", MinIDPrefix)
+	}
+
+	var matches []string
+	for id := range ab.contacts {
+		if strings.HasPrefix(normalizeID(id), prefix) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("contact not found")
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", &AmbiguousIDError{Prefix: input, Matches: matches}
+}
+
+// ShortID returns the shortest prefix of the contact's ID, at least
+// MinIDPrefix characters long, that no other contact shares
+func (ab *AddressBook) ShortID(id string) string {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	length := MinIDPrefix
+	for other := range ab.contacts {
+		if other == id {
+			continue
+		}
+		common := commonPrefixLen(id, other)
+		if common+1 > length {
+			length = common + 1
+		}
+	}
+	if length > len(id) {
+		return id
+	}
+	return id[:length]
+}
+
+// ShortIDs is ShortID for several contacts at once, such as every row of a
+// listing. It sorts the IDs once, so each contact is compared only with its
+// neighbours instead of with every other contact.
+func (ab *AddressBook) ShortIDs(ids []string) []string {
+	ab.mu.RLock()
+	all := make([]string, 0, len(ab.contacts))
+	for id := range ab.contacts {
+		all = append(all, normalizeID(id))
+	}
+	ab.mu.RUnlock()
+	sort.Strings(all)
+
+	short := make([]string, len(ids))
+	for i, id := range ids {
+		normalized := normalizeID(id)
+		length := MinIDPrefix
+		// The contact sharing the longest prefix sorts right before or after it
+		j := sort.SearchStrings(all, normalized)
+		if j > 0 {
+			length = max(length, commonPrefixLen(normalized, all[j-1])+1)
+		}
+		if j < len(all) && all[j] == normalized {
+			j++
+		}
+		if j < len(all) {
+			length = max(length, commonPrefixLen(normalized, all[j])+1)
+		}
+		short[i] = id[:min(length, len(id))]
+	}
+	return short
+}
+
+// commonPrefixLen returns how many leading bytes two IDs share once normalized
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && normalizeIDByte(a[n]) == normalizeIDByte(b[n]) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateIDSortsByCreation(t *testing.T) {
+	generated := make([]string, 1000)
+	for i := range generated {
+		generated[i] = generateID()
+	}
+	if !sort.StringsAreSorted(generated) {
+		t.Error("Expected IDs generated in sequence to sort in creation order")
+	}
+	seen := make(map[string]bool)
+	for _, id := range generated {
+		if len(id) != 26 {
+			t.Fatalf("Expected 26-character ID, got %q", id)
+		}
+		if strings.Trim(id, crockford) != "" {
+			t.Fatalf("Expected only Crockford base32 characters, got %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate ID %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestIsID(t *testing.T) {
+	tests := map[string]bool{
+		generateID():                  true,
+		"01HX0000000000000000000001":  true,
+		"01hx0000000000000000000001":  false,
+		"01HX000000000000000000000U":  false,
+		"01HX000000000000000000001":   false,
+		"urn:uuid:4fbe8971-0bc3-424c": false,
+	}
+	for id, want := range tests {
+		if got := IsID(id); got != want {
+			t.Errorf("IsID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestIDGeneratorMonotonic(t *testing.T) {
+	var g idGenerator
+	now := time.UnixMilli(1700000000000)
+
+	first, err := g.next(now)
+	if err != nil {
+		t.Fatalf("next failed: %v", err)
+	}
+	second, _ := g.next(now)
+	earlier, _ := g.next(now.Add(-time.Second))
+	if !(first < second && second < earlier) {
+		t.Errorf("Expected IDs to increase within a millisecond and when the clock steps back: %s %s %s", first, second, earlier)
+	}
+	if first[:10] != "01HF7YAT00" {
+		t.Errorf("Expected timestamp prefix 01HF7YAT00, got %s", first[:10])
+	}
+}
+
+func TestResolveID(t *testing.T) {
+	ab := NewAddressBook()
+	legacy := &Contact{ID: "20240101120000-0a1b2c3d4e5f6071", FirstName: "Old"}
+	a := &Contact{ID: "01HF7YAT00ABCDEFGHJKMNPQRS", FirstName: "A"}
+	b := &Contact{ID: "01HF7YAT00ABCDEFGHJKMNPQRT", FirstName: "B"}
+	c := &Contact{ID: "01HF7ZZZZZ0000000000000000", FirstName: "C"}
+	for _, contact := range []*Contact{legacy, a, b, c} {
+		if err := ab.AddContact(contact); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	tests := map[string]string{
+		legacy.ID:                    legacy.ID,
+		"2024":                       legacy.ID,
+		"01hf7z":                     c.ID,
+		"O1HF7Z":                     c.ID, // O is read as 0
+		"01HF7YAT00ABCDEFGHJKMNPQRS": a.ID,
+		"01hf7yat00abcdefghjkmnpqrt": b.ID,
+	}
+	for input, want := range tests {
+		got, err := ab.ResolveID(input)
+		if err != nil || got != want {
+			t.Errorf("ResolveID(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	var ambiguous *AmbiguousIDError
+	if _, err := ab.ResolveID("01HF7YAT"); !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("Expected ambiguous prefix error with 2 matches, got %v", err)
+	}
+	if _, err := ab.ResolveID("01H"); err == nil {
+		t.Error("Expected error for a prefix that is too short")
+	}
+	if _, err := ab.ResolveID("ZZZZ"); err == nil {
+		t.Error("Expected error for an unknown prefix")
+	}
+
+	if got := ab.ShortID(c.ID); got != "01HF7Z" {
+		t.Errorf("ShortID = %q, want 01HF7Z", got)
+	}
+	if got := ab.ShortID(legacy.ID); got != "2024" {
+		t.Errorf("ShortID = %q, want 2024", got)
+	}
+	if got := ab.ShortID(a.ID); got != a.ID {
+		t.Errorf("ShortID = %q, want %q", got, a.ID)
+	}
+	ids := []string{legacy.ID, a.ID, b.ID, c.ID}
+	for i, short := range ab.ShortIDs(ids) {
+		if want := ab.ShortID(ids[i]); short != want {
+			t.Errorf("ShortIDs gives %q for %s, ShortID %q", short, ids[i], want)
+		}
+	}
+}
+
+func TestLegacyIDsSortByCreation(t *testing.T) {
+	if !IsLegacyID("20240101120000-0a1b2c3d4e5f6071") || IsLegacyID("20241301120000-0a1b2c3d4e5f6071") ||
+		IsLegacyID("01HF7YAT00ABCDEFGHJKMNPQRS") {
+		t.Error("IsLegacyID misjudged an ID")
+	}
+
+	ab := NewAddressBook()
+	older := &Contact{ID: "20200101120000-0a1b2c3d4e5f6071", FirstName: "Older"}
+	legacy := &Contact{ID: "20240101120000-0a1b2c3d4e5f6071", FirstName: "Legacy"}
+	var g idGenerator
+	before, _ := g.next(time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local))
+	after, _ := g.next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local))
+	for _, c := range []*Contact{{ID: after, FirstName: "New"}, legacy, {ID: before, FirstName: "Earlier"}, older} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+	var names []string
+	for _, c := range ab.GetAllContacts() {
+		names = append(names, c.FirstName)
+	}
+	if got := strings.Join(names, " "); got != "Older Earlier Legacy New" {
+		t.Errorf("Expected legacy IDs among current ones by creation time, got %s", got)
+	}
+}
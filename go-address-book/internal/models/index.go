@@ -0,0 +1,486 @@
+package models
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rushi/address-book-cli/internal/match"
+)
+
+// Weights of the fields a search term can be found in. A contact scores the
+// weight of the best field each query word matched, scaled down for prefix
+// matches, summed over the words of the query.
+const (
+	weightName         = 3
+	weightEmail        = 2
+	weightOrganization = 1.5
+	weightPhone        = 1
+	weightOther        = 1
+	weightNote         = 0.5
+)
+
+// Fuzzy matches of a query word score these shares of an exact match
+const (
+	qualityOneEdit  = 0.75
+	qualityTwoEdits = 0.5
+	qualitySounds   = 0.6
+)
+
+// minFuzzyLen is the shortest query word matched fuzzily; shorter words
+// are a typo away from too many names
+const minFuzzyLen = 3
+
+// maxPending is how many new terms wait for a merge. Each new term is
+// inserted into pending in order, so it stays small.
+const maxPending = 4096
+
+// docTerm is a term of an indexed contact, the weight it was found with and
+// where the contact sits in the term's postings
+type docTerm struct {
+	term   string
+	weight float32
+	pos    int32
+}
+
+// searchIndex is an inverted index over every field of every contact.
+// Contacts are numbered internally so postings stay small. Terms are kept
+// sorted for prefix lookups; new terms collect in a short sorted pending
+// list and are merged in batches. Terms no contact uses any more keep
+// empty postings until the next merge drops them, so a term that comes
+// back is never listed twice.
+type searchIndex struct {
+	postings map[string][]int32   // term -> contacts containing it
+	terms    []string             // sorted
+	pending  []string             // sorted terms added since the last merge
+	removed  int                  // listed terms with empty postings
+	docs     [][]docTerm          // contact number -> its terms
+	ids      []string             // contact number -> contact ID
+	numbers  map[string]int32     // contact ID -> contact number
+	free     []int32              // numbers of removed contacts, for reuse
+	names    map[string]*nameTerm // words of first and last names, for fuzzy search
+}
+
+// nameTerm is a distinct word of first or last names
+type nameTerm struct {
+	count int    // contacts using it
+	code  string // Metaphone code
+}
+
+// SearchResult is a contact matched by Search and how well it matched
+type SearchResult struct {
+	Contact *Contact
+	Score   float64
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string][]int32),
+		numbers:  make(map[string]int32),
+		names:    make(map[string]*nameTerm),
+	}
+}
+
+// add indexes a contact, replacing what was indexed for it before
+func (idx *searchIndex) add(c *Contact) {
+	idx.remove(c.ID)
+
+	weights := make(map[string]float32)
+	addText := func(text string, weight float32) {
+		for _, term := range Tokenize(text) {
+			if weight > weights[term] {
+				weights[term] = weight
+			}
+		}
+	}
+	addPhone := func(phone string, weight float32) {
+		addText(phone, weight)
+		if digits := match.NormalizePhone(phone); digits != "" && weight > weights[digits] {
+			weights[digits] = weight
+		}
+	}
+
+	addText(c.FirstName, weightName)
+	addText(c.LastName, weightName)
+	addText(c.Email, weightEmail)
+	for _, email := range c.OtherEmails {
+		addText(email, weightEmail)
+	}
+	addText(c.Organization, weightOrganization)
+	addText(c.Title, weightOther)
+	addText(c.Department, weightOther)
+	for _, tag := range c.Tags {
+		addText(tag, weightOrganization)
+	}
+	addPhone(c.Phone, weightPhone)
+	for _, phone := range c.OtherPhones {
+		addPhone(phone, weightPhone)
+	}
+	addText(c.Address, weightOther)
+	for _, address := range c.OtherAddresses {
+		addText(address, weightOther)
+	}
+	for _, value := range c.Custom {
+		addText(value, weightOther)
+	}
+	for _, interaction := range c.Interactions {
+		addText(interaction.Summary, weightNote)
+	}
+
+	var n int32
+	if len(idx.free) > 0 {
+		n = idx.free[len(idx.free)-1]
+		idx.free = idx.free[:len(idx.free)-1]
+		idx.ids[n] = c.ID
+	} else {
+		n = int32(len(idx.ids))
+		idx.ids = append(idx.ids, c.ID)
+		idx.docs = append(idx.docs, nil)
+	}
+	idx.numbers[c.ID] = n
+
+	terms := make([]docTerm, 0, len(weights))
+	for term, weight := range weights {
+		postings, exists := idx.postings[term]
+		if !exists {
+			i, _ := slices.BinarySearch(idx.pending, term)
+			idx.pending = slices.Insert(idx.pending, i, term)
+		} else if len(postings) == 0 {
+			idx.removed--
+		}
+		terms = append(terms, docTerm{term: term, weight: weight, pos: int32(len(postings))})
+		idx.postings[term] = append(postings, n)
+		if weight == weightName {
+			name, ok := idx.names[term]
+			if !ok {
+				name = &nameTerm{code: match.Metaphone(term)}
+				idx.names[term] = name
+			}
+			name.count++
+		}
+	}
+	slices.SortFunc(terms, func(a, b docTerm) int { return strings.Compare(a.term, b.term) })
+	idx.docs[n] = terms
+
+	if len(idx.pending) > maxPending {
+		idx.merge()
+	}
+}
+
+// remove drops a contact from the index
+func (idx *searchIndex) remove(id string) {
+	n, exists := idx.numbers[id]
+	if !exists {
+		return
+	}
+	for _, dt := range idx.docs[n] {
+		postings := idx.postings[dt.term]
+		last := len(postings) - 1
+		if moved := postings[last]; int(dt.pos) != last {
+			// Fill the gap with the last posting and tell its contact
+			postings[dt.pos] = moved
+			for i := range idx.docs[moved] {
+				if idx.docs[moved][i].term == dt.term {
+					idx.docs[moved][i].pos = dt.pos
+					break
+				}
+			}
+		}
+		idx.postings[dt.term] = postings[:last]
+		if last == 0 {
+			idx.removed++
+		}
+		if name, ok := idx.names[dt.term]; ok && dt.weight == weightName {
+			if name.count--; name.count == 0 {
+				delete(idx.names, dt.term)
+			}
+		}
+	}
+	idx.docs[n] = nil
+	idx.ids[n] = ""
+	delete(idx.numbers, id)
+	idx.free = append(idx.free, n)
+
+	if idx.removed > max(1024, len(idx.terms)/4) {
+		idx.merge()
+	}
+}
+
+// merge sorts pending terms into terms and drops unused ones
+func (idx *searchIndex) merge() {
+	terms := make([]string, 0, len(idx.terms)+len(idx.pending))
+	keep := func(term string) {
+		if idx.removed == 0 || len(idx.postings[term]) > 0 {
+			terms = append(terms, term)
+		} else {
+			delete(idx.postings, term)
+		}
+	}
+	i, j := 0, 0
+	for i < len(idx.terms) || j < len(idx.pending) {
+		if j == len(idx.pending) || (i < len(idx.terms) && idx.terms[i] < idx.pending[j]) {
+			keep(idx.terms[i])
+			i++
+		} else {
+			keep(idx.pending[j])
+			j++
+		}
+	}
+	idx.terms = terms
+	idx.pending = nil
+	idx.removed = 0
+}
+
+// search returns the IDs of contacts matching every word of the query, as
+// a whole term or a prefix of one, with their scores
+func (idx *searchIndex) search(query string) ([]string, []float64) {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	// Candidates come from the word with the fewest postings; the other
+	// words are checked against each candidate's own terms. Longer words
+	// tend to be rarer, so they are counted first and the rest can stop
+	// counting as soon as they pass the best so far.
+	byLength := slices.Clone(words)
+	sort.SliceStable(byLength, func(i, j int) bool { return len(byLength[i]) > len(byLength[j]) })
+	var rarest []string
+	rarestCount := -1
+	for _, word := range byLength {
+		terms, count := idx.expand(word, rarestCount)
+		if count == 0 {
+			return nil, nil
+		}
+		if rarestCount < 0 || count < rarestCount {
+			rarest, rarestCount = terms, count
+		}
+	}
+
+	seen := make(map[int32]bool, rarestCount)
+	var ids []string
+	var scores []float64
+	for _, term := range rarest {
+		for _, n := range idx.postings[term] {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			if score, ok := scoreDoc(idx.docs[n], byLength); ok {
+				ids = append(ids, idx.ids[n])
+				scores = append(scores, score)
+			}
+		}
+	}
+	return ids, scores
+}
+
+// searchFuzzy is search with typo-tolerant and phonetic matching of names
+func (idx *searchIndex) searchFuzzy(query string) ([]string, []float64) {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	qualities := make([]map[string]float64, len(words))
+	rarest, rarestCount := -1, 0
+	for i, word := range words {
+		qualities[i] = idx.fuzzyTerms(word)
+		count := 0
+		for term := range qualities[i] {
+			count += len(idx.postings[term])
+		}
+		if count == 0 {
+			return nil, nil
+		}
+		if rarest < 0 || count < rarestCount {
+			rarest, rarestCount = i, count
+		}
+	}
+
+	seen := make(map[int32]bool, rarestCount)
+	var ids []string
+	var scores []float64
+	for term := range qualities[rarest] {
+		for _, n := range idx.postings[term] {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			if score, ok := scoreFuzzy(idx.docs[n], qualities); ok {
+				ids = append(ids, idx.ids[n])
+				scores = append(scores, score)
+			}
+		}
+	}
+	return ids, scores
+}
+
+// fuzzyTerms returns the terms a query word may stand for, with how close
+// each is from 0 to 1: words in any field that start with it, and names
+// within one or two typos of it or that sound the same. Swapped letters
+// count as one typo.
+func (idx *searchIndex) fuzzyTerms(word string) map[string]float64 {
+	terms := make(map[string]float64)
+	prefixed, _ := idx.expand(word, -1)
+	for _, term := range prefixed {
+		terms[term] = float64(len(word)) / float64(len(term))
+	}
+
+	length := utf8.RuneCountInString(word)
+	if length < minFuzzyLen {
+		return terms
+	}
+	maxEdits := 1
+	if length > minFuzzyLen {
+		maxEdits = 2
+	}
+	code := match.Metaphone(word)
+	for term, name := range idx.names {
+		quality := 0.0
+		if code != "" && name.code == code {
+			quality = qualitySounds
+		}
+		if diff := utf8.RuneCountInString(term) - length; diff <= maxEdits && diff >= -maxEdits {
+			switch distance := match.Damerau(word, term); {
+			case distance == 1:
+				quality = max(quality, qualityOneEdit)
+			case distance == 2 && maxEdits == 2:
+				quality = max(quality, qualityTwoEdits)
+			}
+		}
+		if quality > terms[term] {
+			terms[term] = quality
+		}
+	}
+	return terms
+}
+
+// scoreFuzzy scores a contact's terms against the close terms of each
+// query word. It reports false unless every word matches.
+func scoreFuzzy(terms []docTerm, qualities []map[string]float64) (float64, bool) {
+	total := 0.0
+	for _, near := range qualities {
+		best := 0.0
+		for _, dt := range terms {
+			if quality, ok := near[dt.term]; ok {
+				best = max(best, float64(dt.weight)*quality)
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// expand returns the indexed terms that start with word and how many
+// postings they have. Once the count passes limit, when limit is not
+// negative, it gives up and returns what it has.
+func (idx *searchIndex) expand(word string, limit int) ([]string, int) {
+	var terms []string
+	count := 0
+	visit := func(term string) bool {
+		if postings := idx.postings[term]; len(postings) > 0 {
+			terms = append(terms, term)
+			count += len(postings)
+		}
+		return limit < 0 || count <= limit
+	}
+
+	for _, list := range [][]string{idx.terms, idx.pending} {
+		for i, _ := slices.BinarySearch(list, word); i < len(list); i++ {
+			if !strings.HasPrefix(list[i], word) || !visit(list[i]) {
+				break
+			}
+		}
+	}
+	return terms, count
+}
+
+// scoreDoc scores a contact's sorted terms against the query words. It
+// reports false unless every word matches.
+func scoreDoc(terms []docTerm, words []string) (float64, bool) {
+	total := 0.0
+	for _, word := range words {
+		best := 0.0
+		i, _ := slices.BinarySearchFunc(terms, word, func(dt docTerm, w string) int { return strings.Compare(dt.term, w) })
+		for ; i < len(terms) && strings.HasPrefix(terms[i].term, word); i++ {
+			// Exact matches score the full weight, prefixes a share of it
+			score := float64(terms[i].weight) * float64(len(word)) / float64(len(terms[i].term))
+			best = max(best, score)
+		}
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// Tokenize lowercases text and splits it into words of letters and digits,
+// the words Search matches query words against
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Search finds contacts containing every word of the query in any field,
+// either as a whole word or as the start of one, best matches first. Names
+// weigh more than emails, which weigh more than other fields. A limit above
+// zero caps the number of results.
+func (ab *AddressBook) Search(query string, limit int) []SearchResult {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	ids, scores := ab.index.search(query)
+	return ab.rank(ids, scores, limit)
+}
+
+// FuzzySearch is Search that also forgives typos and spelling variants in
+// names: a query word of three or more letters matches names up to one edit
+// away (two for longer words) and names that sound alike, so "Jon Smyth"
+// finds "John Smith". Closer matches rank higher.
+func (ab *AddressBook) FuzzySearch(query string, limit int) []SearchResult {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	ids, scores := ab.index.searchFuzzy(query)
+	return ab.rank(ids, scores, limit)
+}
+
+// rank orders matches best first, then by name, and copies out up to
+// limit of them. It must be called with ab.mu held.
+func (ab *AddressBook) rank(ids []string, scores []float64, limit int) []SearchResult {
+	order := make([]int, len(ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		ca, cb := ab.contacts[ids[a]], ab.contacts[ids[b]]
+		if ca.LastName != cb.LastName {
+			return ca.LastName < cb.LastName
+		}
+		if ca.FirstName != cb.FirstName {
+			return ca.FirstName < cb.FirstName
+		}
+		return ca.ID < cb.ID
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+
+	results := make([]SearchResult, len(order))
+	for i, k := range order {
+		results[i] = SearchResult{Contact: ab.contacts[ids[k]].Clone(), Score: scores[k]}
+	}
+	return results
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	ab := NewAddressBook()
+	john := NewContact("John", "Smith", "john.smith@acme.com", "(555) 010-2000", "12 Baker Street, London")
+	johanna := NewContact("Johanna", "Brown", "jb@example.com", "555-777-8888", "1 Main St")
+	johanna.Organization = "Smithson Ltd"
+	other := NewContact("Alice", "Jones", "alice@example.com", "555-123-4567", "99 Elm Road")
+	for _, c := range []*Contact{john, johanna, other} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"joh", []string{john.ID, johanna.ID}},
+		{"jo sm", []string{john.ID, johanna.ID}},
+		{"smith", []string{john.ID, johanna.ID}},
+		{"baker street", []string{john.ID}},
+		{"5550102000", []string{john.ID}},
+		{"555 777", []string{johanna.ID}},
+		{"acme", []string{john.ID}},
+		{"elm", []string{other.ID}},
+		{"john elm", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		results := ab.Search(tt.query, 0)
+		if len(results) != len(tt.want) {
+			t.Errorf("Search(%q): expected %d results, got %d", tt.query, len(tt.want), len(results))
+			continue
+		}
+		for i, id := range tt.want {
+			if results[i].Contact.ID != id {
+				t.Errorf("Search(%q): expected %s at rank %d, got %s %s", tt.query, id, i,
+					results[i].Contact.FirstName, results[i].Contact.LastName)
+			}
+		}
+	}
+
+	if results := ab.Search("joh", 1); len(results) != 1 {
+		t.Errorf("Expected limit to cap results, got %d", len(results))
+	}
+	if results := ab.SearchContacts(" "); len(results) != 3 || results[0].ID != john.ID {
+		t.Errorf("Expected an empty query to return every contact, got %d", len(results))
+	}
+}
+
+func TestSearchIndexFollowsChanges(t *testing.T) {
+	ab := NewAddressBook()
+	contact := NewContact("John", "Smith", "john@example.com", "", "")
+	if err := ab.AddContact(contact); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+
+	contact.LastName = "Doe"
+	if err := ab.UpdateContact(contact); err != nil {
+		t.Fatalf("Failed to update contact: %v", err)
+	}
+	if results := ab.SearchContacts("smith"); len(results) != 0 {
+		t.Error("Expected old last name removed from the index")
+	}
+	if results := ab.SearchContacts("doe"); len(results) != 1 {
+		t.Error("Expected new last name indexed")
+	}
+
+	if err := ab.AddInteraction(contact.ID, Interaction{Kind: InteractionNote, Summary: "Loves sailing"}); err != nil {
+		t.Fatalf("Failed to add note: %v", err)
+	}
+	if results := ab.SearchContacts("sailing"); len(results) != 1 {
+		t.Error("Expected notes indexed")
+	}
+
+	if err := ab.DeleteContact(contact.ID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+	if results := ab.SearchContacts("doe"); len(results) != 0 {
+		t.Error("Expected deleted contact removed from the index")
+	}
+	ab.index.merge()
+	if len(ab.index.postings) != 0 || len(ab.index.terms) != 0 {
+		t.Error("Expected an empty index after deleting every contact")
+	}
+}
+
+func TestSearchIndexMerge(t *testing.T) {
+	ab := NewAddressBook()
+	var ops []Op
+	for i := 0; i < 3*maxPending; i++ {
+		ops = append(ops, AddOp(benchContact(i)))
+	}
+	if err := ab.Apply(ops); err != nil {
+		t.Fatalf("Failed to add contacts: %v", err)
+	}
+	for i := 0; i < len(ops); i += 2 {
+		if err := ab.DeleteContact(ops[i].Contact.ID); err != nil {
+			t.Fatalf("Failed to delete contact: %v", err)
+		}
+	}
+
+	for _, i := range []int{1, 2, 3*maxPending - 1} {
+		want := i % 2 // even contacts were deleted
+		results := ab.Search(benchContact(i).Email, 0)
+		if len(results) != want {
+			t.Errorf("Expected %d results for contact %d, got %d", want, i, len(results))
+		}
+	}
+	if !slices.IsSorted(ab.index.terms) {
+		t.Error("Expected index terms sorted")
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	ab := NewAddressBook()
+	john := NewContact("John", "Smith", "js@example.com", "", "")
+	jon := NewContact("Jon", "Smyth", "jon@example.com", "", "")
+	catherine := NewContact("Catherine", "Jones", "cj@example.com", "", "")
+	other := NewContact("Alice", "Brown", "smyth.fan@example.com", "", "")
+	for _, c := range []*Contact{john, jon, catherine, other} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	if results := ab.Search("jon smyth", 0); len(results) != 1 {
+		t.Errorf("Expected exact search to find only Jon Smyth, got %d", len(results))
+	}
+
+	results := ab.FuzzySearch("jon smyth", 0)
+	if len(results) != 2 || results[0].Contact.ID != jon.ID || results[1].Contact.ID != john.ID {
+		t.Fatalf("Expected Jon Smyth then John Smith, got %d results", len(results))
+	}
+	if results[0].Score <= results[1].Score {
+		t.Error("Expected the exact match to score higher")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"jhon smith", john.ID},   // swapped letters
+		{"kathryn", catherine.ID}, // sounds alike
+		{"catherin jnoes", catherine.ID},
+	}
+	for _, tt := range tests {
+		results := ab.FuzzySearch(tt.query, 0)
+		if len(results) == 0 || results[0].Contact.ID != tt.want {
+			t.Errorf("FuzzySearch(%q): expected %s first, got %d results", tt.query, tt.want, len(results))
+		}
+	}
+
+	if results := ab.FuzzySearch("jo", 0); len(results) != 3 {
+		t.Errorf("Expected short words to match prefixes only, got %d results", len(results))
+	}
+	if results := ab.FuzzySearch("example", 0); len(results) != 4 {
+		t.Errorf("Expected other fields to still match by prefix, got %d", len(results))
+	}
+
+	if err := ab.DeleteContact(jon.ID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+	if _, ok := ab.index.names["jon"]; ok {
+		t.Error("Expected deleted names dropped from the fuzzy index")
+	}
+}
+
+var (
+	benchOnce sync.Once
+	benchBook *AddressBook
+)
+
+// syllables are combined into synthetic names for the benchmarks
+var syllables = []string{
+	"an", "bel", "cor", "dan", "el", "fer", "gar", "hal", "is", "jor",
+	"kel", "lan", "mar", "nor", "ol", "per", "quin", "ros", "sam", "tor",
+	"ul", "ver", "wil", "xan", "yor", "zel", "ber", "cal", "dor", "fen",
+}
+
+// benchContact returns the synthetic contact number i. Names repeat across
+// contacts, while emails and phone numbers are unique.
+func benchContact(i int) *Contact {
+	n := len(syllables)
+	first := syllables[i%n] + syllables[(i/n)%n]
+	last := syllables[(i/7)%n] + syllables[(i/11)%n] + syllables[(i/13)%n]
+	email := fmt.Sprintf("%s.%s%d@example%d.com", first, last, i, i%100)
+	phone := fmt.Sprintf("555-%03d-%04d", i/10000, i%10000)
+	address := fmt.Sprintf("%d %s Street", i%500, syllables[(i/3)%n])
+	return NewContact(first, last, email, phone, address)
+}
+
+// benchAddressBook returns a shared address book of a million synthetic contacts
+func benchAddressBook(b *testing.B) *AddressBook {
+	b.Helper()
+	benchOnce.Do(func() {
+		benchBook = NewAddressBook()
+		const total, batch = 1_000_000, 10_000
+		for start := 0; start < total; start += batch {
+			ops := make([]Op, 0, batch)
+			for i := start; i < start+batch; i++ {
+				ops = append(ops, AddOp(benchContact(i)))
+			}
+			if err := benchBook.Apply(ops); err != nil {
+				b.Fatalf("Failed to add contacts: %v", err)
+			}
+		}
+	})
+	return benchBook
+}
+
+func benchmarkSearch(b *testing.B, query string) {
+	ab := benchAddressBook(b)
+	if len(ab.Search(query, 10)) == 0 {
+		b.Fatalf("Expected results for %q", query)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ab.Search(query, 10)
+	}
+}
+
+func BenchmarkSearchFullName(b *testing.B) {
+	c := benchContact(123451)
+	benchmarkSearch(b, c.FirstName+" "+c.LastName)
+}
+
+func BenchmarkSearchNamePrefix(b *testing.B) {
+	c := benchContact(123451)
+	benchmarkSearch(b, c.FirstName+" "+c.LastName[:4])
+}
+
+func BenchmarkSearchEmail(b *testing.B) {
+	benchmarkSearch(b, benchContact(123451).Email)
+}
+
+func BenchmarkSearchEmailPrefix(b *testing.B) {
+	benchmarkSearch(b, benchContact(123451).Email[:12])
+}
+
+func BenchmarkSearchPhone(b *testing.B) {
+	benchmarkSearch(b, benchContact(123451).Phone)
+}
+
+func BenchmarkFuzzySearchName(b *testing.B) {
+	ab := benchAddressBook(b)
+	c := benchContact(123451)
+	query := c.FirstName[1:] + " " + c.LastName[:len(c.LastName)-1] + "x"
+	if len(ab.FuzzySearch(query, 10)) == 0 {
+		b.Fatalf("Expected results for %q", query)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ab.FuzzySearch(query, 10)
+	}
+}
+
+func BenchmarkIndexUpdate(b *testing.B) {
+	ab := benchAddressBook(b)
+	contact := ab.GetAllContacts()[0]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		contact.Address = fmt.Sprintf("%d Benchmark Road", i)
+		if err := ab.UpdateContact(contact); err != nil {
+			b.Fatalf("Failed to update contact: %v", err)
+		}
+	}
+}
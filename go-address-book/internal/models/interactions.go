@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InteractionKind is the type of an entry in a contact's log
+type InteractionKind string
+
+const (
+	InteractionNote    InteractionKind = "note"
+	InteractionCall    InteractionKind = "call"
+	InteractionMeeting InteractionKind = "meeting"
+	InteractionEmail   InteractionKind = "email"
+)
+
+// InteractionKinds lists the supported interaction kinds
+var InteractionKinds = []InteractionKind{InteractionNote, InteractionCall, InteractionMeeting, InteractionEmail}
+
+// ParseInteractionKind parses an interaction kind name
+func ParseInteractionKind(name string) (InteractionKind, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, kind := range InteractionKinds {
+		if string(kind) == name {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unknown interaction kind %q", name)
+}
+
+// Interaction is a timestamped note or record of contact with a person
+type Interaction struct {
+	At      time.Time       `json:"at"`
+	Kind    InteractionKind `json:"kind"`
+	Summary string          `json:"summary"`
+}
+
+// LastContacted returns when the contact was last called, met or emailed.
+// Plain notes do not count as contact.
+func (c *Contact) LastContacted() (time.Time, bool) {
+	var last time.Time
+	for _, interaction := range c.Interactions {
+		if interaction.Kind != InteractionNote && interaction.At.After(last) {
+			last = interaction.At
+		}
+	}
+	return last, !last.IsZero()
+}
+
+// AddInteraction appends an entry to the contact's log, keeping it in time order
+func (ab *AddressBook) AddInteraction(id string, interaction Interaction) error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	contact, exists := ab.contacts[id]
+	if !exists {
+		return errors.New("contact not found")
+	}
+	if _, err := ParseInteractionKind(string(interaction.Kind)); err != nil {
+		return err
+	}
+	if strings.TrimSpace(interaction.Summary) == "" {
+		return errors.New("summary is required")
+	}
+	if interaction.At.IsZero() {
+		interaction.At = time.Now()
+	}
+
+	updated := contact.Clone()
+	updated.Interactions = append(updated.Interactions, interaction)
+	sort.SliceStable(updated.Interactions, func(i, j int) bool {
+		return updated.Interactions[i].At.Before(updated.Interactions[j].At)
+	})
+	updated.UpdatedAt = time.Now()
+	updated.Version++
+
+	ab.contacts[id] = updated
+	ab.index.add(updated)
+	ab.publish([]Event{{Type: EventUpdated, ID: id, Before: contact, After: updated}})
+	return nil
+}
+
+// NotContactedSince returns contacts with no call, meeting or email after cutoff,
+// least recently contacted first. Contacts never contacted come first of all.
+func (ab *AddressBook) NotContactedSince(cutoff time.Time) []*Contact {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	var results []*Contact
+	for _, contact := range ab.contacts {
+		if last, ok := contact.LastContacted(); !ok || last.Before(cutoff) {
+			results = append(results, contact.Clone())
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, _ := results[i].LastContacted()
+		b, _ := results[j].LastContacted()
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return results[i].LastName < results[j].LastName
+	})
+	return results
+}
+
+// FormatInteractions encodes an interaction log as JSON for storage
+func FormatInteractions(interactions []Interaction) (string, error) {
+	if len(interactions) == 0 {
+		return "", nil
+	}
+	bytes, err := json.Marshal(interactions)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// ParseInteractions decodes an interaction log written by FormatInteractions
+func ParseInteractions(value string) ([]Interaction, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal([]byte(value), &interactions); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
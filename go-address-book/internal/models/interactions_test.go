@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInteractions(t *testing.T) {
+	ab := NewAddressBook()
+	now := time.Now()
+
+	recent := NewContact("Jane", "Smith", "jane@example.com", "1", "A")
+	stale := NewContact("John", "Doe", "john@example.com", "2", "B")
+	never := NewContact("Ann", "Lee", "ann@example.com", "3", "C")
+	for _, c := range []*Contact{recent, stale, never} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	add := func(c *Contact, at time.Time, kind InteractionKind) {
+		t.Helper()
+		if err := ab.AddInteraction(c.ID, Interaction{At: at, Kind: kind, Summary: "hello"}); err != nil {
+			t.Fatalf("Failed to add interaction: %v", err)
+		}
+	}
+	add(recent, now.AddDate(0, 0, -2), InteractionCall)
+	add(recent, now.AddDate(0, 0, -5), InteractionMeeting)
+	add(stale, now.AddDate(0, 0, -40), InteractionEmail)
+	add(stale, now.AddDate(0, 0, -1), InteractionNote)
+
+	recent, _ = ab.GetContact(recent.ID)
+	if len(recent.Interactions) != 2 || !recent.Interactions[0].At.Before(recent.Interactions[1].At) {
+		t.Error("Expected interactions kept in time order")
+	}
+	if last, ok := recent.LastContacted(); !ok || !last.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("Unexpected last contacted time %v", last)
+	}
+	if _, ok := never.LastContacted(); ok {
+		t.Error("Expected no last contacted time")
+	}
+
+	results := ab.NotContactedSince(now.AddDate(0, 0, -30))
+	if len(results) != 2 || results[0].ID != never.ID || results[1].ID != stale.ID {
+		t.Errorf("Expected never-contacted then stale contact, got %d results", len(results))
+	}
+
+	if err := ab.AddInteraction(recent.ID, Interaction{Kind: "fax", Summary: "x"}); err == nil {
+		t.Error("Expected error for unknown kind")
+	}
+	if err := ab.AddInteraction(recent.ID, Interaction{Kind: InteractionNote}); err == nil {
+		t.Error("Expected error for empty summary")
+	}
+	if err := ab.AddInteraction("missing", Interaction{Kind: InteractionNote, Summary: "x"}); err == nil {
+		t.Error("Expected error for missing contact")
+	}
+
+	encoded, err := FormatInteractions(recent.Interactions)
+	if err != nil {
+		t.Fatalf("FormatInteractions failed: %v", err)
+	}
+	decoded, err := ParseInteractions(encoded)
+	if err != nil {
+		t.Fatalf("ParseInteractions failed: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].Kind != InteractionCall || !decoded[1].At.Equal(recent.Interactions[1].At) {
+		t.Errorf("Round trip mismatch: %+v", decoded)
+	}
+}
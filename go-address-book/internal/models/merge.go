@@ -0,0 +1,359 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergePolicy decides which value wins when merged contacts disagree on a field
+type MergePolicy string
+
+const (
+	// MergeNonEmpty keeps the oldest contact's value unless it is empty
+	MergeNonEmpty MergePolicy = "non-empty"
+	// MergeNewest keeps the value from the most recently updated contact that has one
+	MergeNewest MergePolicy = "newest"
+	// MergeKeepBoth works like MergeNonEmpty but keeps the losing emails,
+	// phone numbers and addresses as additional values
+	MergeKeepBoth MergePolicy = "keep-both"
+)
+
+// MergePolicies lists the supported merge policies
+var MergePolicies = []MergePolicy{MergeNonEmpty, MergeNewest, MergeKeepBoth}
+
+// ParseMergePolicy parses a merge policy name
+func ParseMergePolicy(name string) (MergePolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, policy := range MergePolicies {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown merge policy %q", name)
+}
+
+// FieldConflict is a field on which the merged contacts disagree
+type FieldConflict struct {
+	Field  string
+	Values []string // distinct non-empty values, oldest contact first
+}
+
+// MergeResolver picks the value of a conflicting field. It may return one of
+// the offered values or any other value.
+type MergeResolver func(conflict FieldConflict) (string, error)
+
+// MergeOptions controls how Merge resolves conflicts
+type MergeOptions struct {
+	Policy MergePolicy
+	// Resolve, when set, is asked about every conflicting field instead of the policy
+	Resolve MergeResolver
+}
+
+// mergeField is a single-valued field considered by Merge. Fields with an
+// others list keep losing values there under MergeKeepBoth.
+type mergeField struct {
+	name   string
+	value  func(c *Contact) *string
+	others func(c *Contact) *[]string
+}
+
+var mergeFields = []mergeField{
+	{"first name", func(c *Contact) *string { return &c.FirstName }, nil},
+	{"last name", func(c *Contact) *string { return &c.LastName }, nil},
+	{"email", func(c *Contact) *string { return &c.Email }, func(c *Contact) *[]string { return &c.OtherEmails }},
+	{"phone", func(c *Contact) *string { return &c.Phone }, func(c *Contact) *[]string { return &c.OtherPhones }},
+	{"address", func(c *Contact) *string { return &c.Address }, func(c *Contact) *[]string { return &c.OtherAddresses }},
+	{"organization", func(c *Contact) *string { return &c.Organization }, nil},
+	{"title", func(c *Contact) *string { return &c.Title }, nil},
+	{"department", func(c *Contact) *string { return &c.Department }, nil},
+}
+
+// Merge combines two or more contacts into the oldest of them, which keeps
+// its ID and CreatedAt. The other contacts are deleted and their IDs become
+// aliases, so GetContact and ResolveID still find the merged contact through
+// them. Notes, dates, custom fields and relationships are carried over.
+// Conflicts are resolved by opts.Resolve if set, otherwise by opts.Policy.
+// Merge fails with a *ConflictError if any contact changes while it runs.
+func (ab *AddressBook) Merge(ids []string, opts MergeOptions) (*Contact, error) {
+	if opts.Policy == "" {
+		opts.Policy = MergeNonEmpty
+	}
+	if _, err := ParseMergePolicy(string(opts.Policy)); err != nil {
+		return nil, err
+	}
+
+	sources, err := ab.mergeSources(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conflicts are resolved without holding the lock, since a resolver may
+	// wait on a person; the version check below catches concurrent changes
+	merged, err := mergeContacts(sources, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	for _, source := range sources[1:] {
+		current, exists := ab.contacts[source.ID]
+		if !exists {
+			return nil, errors.New("contact not found")
+		}
+		if current.Version != source.Version {
+			return nil, &ConflictError{ID: source.ID, Expected: source.Version, Actual: current.Version}
+		}
+	}
+
+	ops := []Op{UpdateOp(merged)}
+	mergedAway := make(map[string]bool)
+	for _, source := range sources[1:] {
+		ops = append(ops, DeleteOp(source.ID))
+		mergedAway[source.ID] = true
+	}
+	tx := ab.begin()
+	if err := tx.applyAll(ops); err != nil {
+		return nil, err
+	}
+	tx.events = append(tx.events, ab.repointLinks(mergedAway, merged.ID)...)
+	tx.commit()
+	return merged.Clone(), nil
+}
+
+// mergeSources returns copies of the contacts to merge, oldest first
+func (ab *AddressBook) mergeSources(ids []string) ([]*Contact, error) {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var sources []*Contact
+	for _, id := range ids {
+		contact, exists := ab.contacts[id]
+		if !exists {
+			contact, exists = ab.contacts[ab.aliases[id]]
+		}
+		if !exists {
+			return nil, fmt.Errorf("contact %s not found", id)
+		}
+		if !seen[contact.ID] {
+			seen[contact.ID] = true
+			sources = append(sources, contact.Clone())
+		}
+	}
+	if len(sources) < 2 {
+		return nil, errors.New("merge needs at least two different contacts")
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].CreatedAt.Before(sources[j].CreatedAt)
+	})
+	return sources, nil
+}
+
+// mergeContacts builds the merged contact from sources, oldest first
+func mergeContacts(sources []*Contact, opts MergeOptions) (*Contact, error) {
+	merged := sources[0].Clone()
+
+	for _, field := range mergeFields {
+		values := make([]string, len(sources))
+		for i, source := range sources {
+			values[i] = *field.value(source)
+		}
+		chosen, err := resolveField(field.name, values, sources, opts)
+		if err != nil {
+			return nil, err
+		}
+		*field.value(merged) = chosen
+
+		if field.others != nil {
+			var others []string
+			for _, source := range sources {
+				others = append(others, *field.others(source)...)
+			}
+			if opts.Policy == MergeKeepBoth {
+				others = append(others, values...)
+			}
+			*field.others(merged) = withoutValue(distinct(others), chosen)
+		}
+	}
+
+	customNames := make(map[string]bool)
+	for _, source := range sources {
+		for name := range source.Custom {
+			customNames[name] = true
+		}
+	}
+	names := make([]string, 0, len(customNames))
+	for name := range customNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := make([]string, len(sources))
+		for i, source := range sources {
+			values[i] = source.Custom[name]
+		}
+		chosen, err := resolveField(name, values, sources, opts)
+		if err != nil {
+			return nil, err
+		}
+		merged.SetCustom(name, chosen)
+	}
+
+	if err := mergeDates(merged, sources, opts); err != nil {
+		return nil, err
+	}
+
+	merged.Interactions = nil
+	seenInteractions := make(map[Interaction]bool)
+	for _, source := range sources {
+		for _, interaction := range source.Interactions {
+			if !seenInteractions[interaction] {
+				seenInteractions[interaction] = true
+				merged.Interactions = append(merged.Interactions, interaction)
+			}
+		}
+	}
+	sort.SliceStable(merged.Interactions, func(i, j int) bool {
+		return merged.Interactions[i].At.Before(merged.Interactions[j].At)
+	})
+
+	var tags []string
+	for _, source := range sources {
+		tags = append(tags, source.Tags...)
+	}
+	merged.Tags = distinct(tags)
+
+	var aliases []string
+	for i, source := range sources {
+		if i > 0 {
+			aliases = append(aliases, source.ID)
+		}
+		aliases = append(aliases, source.Aliases...)
+	}
+	merged.Aliases = distinct(aliases)
+	return merged, nil
+}
+
+// mergeDates combines dates by label, resolving labels with different dates
+func mergeDates(merged *Contact, sources []*Contact, opts MergeOptions) error {
+	var labels []string
+	byLabel := make(map[string][]string)
+	for _, source := range sources {
+		for _, date := range source.Dates {
+			key := strings.ToLower(date.Label)
+			if _, ok := byLabel[key]; !ok {
+				labels = append(labels, date.Label)
+				byLabel[key] = make([]string, len(sources))
+			}
+		}
+	}
+	for i, source := range sources {
+		for _, date := range source.Dates {
+			byLabel[strings.ToLower(date.Label)][i] = date.String()
+		}
+	}
+
+	merged.Dates = nil
+	for _, label := range labels {
+		chosen, err := resolveField(label, byLabel[strings.ToLower(label)], sources, opts)
+		if err != nil {
+			return err
+		}
+		if chosen == "" {
+			continue
+		}
+		date, err := ParseImportantDate(label, chosen)
+		if err != nil {
+			return err
+		}
+		merged.Dates = append(merged.Dates, date)
+	}
+	return nil
+}
+
+// resolveField picks one of values, which line up with sources
+func resolveField(name string, values []string, sources []*Contact, opts MergeOptions) (string, error) {
+	options := distinct(values)
+	switch len(options) {
+	case 0:
+		return "", nil
+	case 1:
+		return options[0], nil
+	}
+
+	if opts.Resolve != nil {
+		return opts.Resolve(FieldConflict{Field: name, Values: options})
+	}
+	if opts.Policy == MergeNewest {
+		newest := -1
+		for i, value := range values {
+			if value != "" && (newest < 0 || sources[i].UpdatedAt.After(sources[newest].UpdatedAt)) {
+				newest = i
+			}
+		}
+		return values[newest], nil
+	}
+	return options[0], nil
+}
+
+// repointLinks moves links of merged-away contacts onto the survivor. Links
+// that would point at itself, duplicate an existing one or fail the checks of
+// AddLink, such as a second spouse or a reporting cycle, are dropped; a spouse
+// link is kept or dropped together with its reverse. It returns an unlink
+// event for each link moved and a link event for each link added. It must be
+// called with ab.mu held.
+func (ab *AddressBook) repointLinks(mergedAway map[string]bool, survivor string) []Event {
+	moved := ab.removeLinks(func(l Link) bool {
+		return mergedAway[l.From] || mergedAway[l.To]
+	})
+
+	var added []Link
+	seen := make(map[Link]bool)
+	for _, link := range moved {
+		if mergedAway[link.From] {
+			link.From = survivor
+		}
+		if mergedAway[link.To] {
+			link.To = survivor
+		}
+		if link.Type == RelationSpouse && seen[Link{From: link.To, Type: link.Type, To: link.From}] {
+			continue
+		}
+		seen[link] = true
+		if link.From == link.To || ab.hasLink(link) || ab.checkLink(link) != nil {
+			continue
+		}
+		ab.insertLink(link)
+		added = append(added, link)
+	}
+	return append(linkEvents(EventUnlinked, moved), linkEvents(EventLinked, added)...)
+}
+
+// distinct returns the non-empty values in order, without repeats
+func distinct(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// withoutValue returns values with every occurrence of value removed
+func withoutValue(values []string, value string) []string {
+	var result []string
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
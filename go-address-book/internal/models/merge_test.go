@@ -0,0 +1,240 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// mergeFixture adds an older and a newer record of the same person
+func mergeFixture(t *testing.T) (*AddressBook, *Contact, *Contact) {
+	t.Helper()
+	ab := NewAddressBook()
+	old := NewContact("Robert", "Smith", "rob@old.com", "", "1 Old Road")
+	old.CreatedAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	old.UpdatedAt = old.CreatedAt
+	old.Organization = "Acme"
+	newer := NewContact("Bob", "Smith", "bob@new.com", "555-010-2000", "")
+	newer.CreatedAt = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer.UpdatedAt = newer.CreatedAt
+	newer.Dates = []ImportantDate{{Label: LabelBirthday, Year: 1980, Month: 5, Day: 1}}
+	newer.Interactions = []Interaction{{At: newer.CreatedAt, Kind: InteractionCall, Summary: "Catch up"}}
+	for _, c := range []*Contact{newer, old} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+	return ab, old, newer
+}
+
+func TestMergeKeepsOldest(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+
+	merged, err := ab.Merge([]string{newer.ID, old.ID}, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.ID != old.ID || !merged.CreatedAt.Equal(old.CreatedAt) {
+		t.Error("Expected the oldest contact to survive")
+	}
+	if merged.FirstName != "Robert" || merged.Email != "rob@old.com" {
+		t.Errorf("Expected the oldest values to win, got %s <%s>", merged.FirstName, merged.Email)
+	}
+	if merged.Phone != "555-010-2000" || merged.Organization != "Acme" {
+		t.Error("Expected empty fields filled from the other contact")
+	}
+	if len(merged.Dates) != 1 || len(merged.Interactions) != 1 {
+		t.Error("Expected dates and notes carried over")
+	}
+	if len(merged.OtherEmails) != 0 {
+		t.Errorf("Expected losing emails dropped, got %v", merged.OtherEmails)
+	}
+	if len(ab.GetAllContacts()) != 1 {
+		t.Errorf("Expected 1 contact after merge, got %d", len(ab.GetAllContacts()))
+	}
+}
+
+func TestMergeAliases(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	if _, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{}); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	contact, err := ab.GetContact(newer.ID)
+	if err != nil || contact.ID != old.ID {
+		t.Fatalf("Expected merged-away ID to find the survivor, got %v", err)
+	}
+	if id, err := ab.ResolveID(newer.ID); err != nil || id != old.ID {
+		t.Errorf("Expected ResolveID to follow the alias, got %q, %v", id, err)
+	}
+
+	third := NewContact("Rob", "Smith", "", "", "")
+	third.CreatedAt = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := ab.AddContact(third); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	merged, err := ab.Merge([]string{newer.ID, third.ID}, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.ID != third.ID || len(merged.Aliases) != 2 {
+		t.Errorf("Expected aliases carried to the new survivor, got %v", merged.Aliases)
+	}
+	if contact, err := ab.GetContact(newer.ID); err != nil || contact.ID != third.ID {
+		t.Error("Expected an alias of an alias to resolve")
+	}
+}
+
+func TestMergePolicies(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	merged, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{Policy: MergeNewest})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.FirstName != "Bob" || merged.Email != "bob@new.com" || merged.Address != "1 Old Road" {
+		t.Errorf("Expected newest non-empty values, got %s <%s> %s", merged.FirstName, merged.Email, merged.Address)
+	}
+
+	ab, old, newer = mergeFixture(t)
+	merged, err = ab.Merge([]string{old.ID, newer.ID}, MergeOptions{Policy: MergeKeepBoth})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.Email != "rob@old.com" || len(merged.OtherEmails) != 1 || merged.OtherEmails[0] != "bob@new.com" {
+		t.Errorf("Expected the other email kept, got %s %v", merged.Email, merged.OtherEmails)
+	}
+
+	if _, err := ab.Merge([]string{old.ID}, MergeOptions{Policy: "bogus"}); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestMergeResolver(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	var fields []string
+	resolve := func(conflict FieldConflict) (string, error) {
+		fields = append(fields, conflict.Field)
+		return conflict.Values[len(conflict.Values)-1], nil
+	}
+	merged, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{Resolve: resolve})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if len(fields) != 2 || fields[0] != "first name" || fields[1] != "email" {
+		t.Errorf("Expected conflicts on first name and email only, got %v", fields)
+	}
+	if merged.FirstName != "Bob" || merged.Email != "bob@new.com" {
+		t.Error("Expected resolver choices applied")
+	}
+}
+
+func TestMergeRepointsLinks(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	boss := NewContact("Alice", "Boss", "", "", "")
+	report := NewContact("Carl", "Report", "", "", "")
+	for _, c := range []*Contact{boss, report} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+	if err := ab.AddLink(newer.ID, RelationManager, boss.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+	if err := ab.AddLink(report.ID, RelationManager, newer.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+	if err := ab.AddLink(old.ID, RelationReferredBy, newer.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+
+	sub := ab.Subscribe()
+	defer sub.Close()
+	if _, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{}); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	links := ab.Links(old.ID)
+	if len(links) != 1 || links[0].To != boss.ID {
+		t.Errorf("Expected the manager link moved and the self-link dropped, got %v", links)
+	}
+	if links := ab.Links(report.ID); len(links) != 1 || links[0].To != old.ID {
+		t.Errorf("Expected incoming links repointed, got %v", links)
+	}
+
+	// Subscribers see each link moved as an unlink and a link
+	var unlinked, linked []Link
+	for event := nextEvent(t, sub); ; event = nextEvent(t, sub) {
+		switch event.Type {
+		case EventUnlinked:
+			unlinked = append(unlinked, *event.Link)
+		case EventLinked:
+			linked = append(linked, *event.Link)
+		}
+		if event.Final {
+			break
+		}
+	}
+	if len(unlinked) != 3 {
+		t.Errorf("Expected an unlink event for each of the 3 links, got %v", unlinked)
+	}
+	want := map[Link]bool{
+		{From: old.ID, Type: RelationManager, To: boss.ID}:   true,
+		{From: report.ID, Type: RelationManager, To: old.ID}: true,
+	}
+	if len(linked) != 2 || !want[linked[0]] || !want[linked[1]] {
+		t.Errorf("Expected link events for the repointed links, got %v", linked)
+	}
+}
+
+func TestMergeDropsConflictingSpouse(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	wife := NewContact("Ann", "Smith", "", "", "")
+	other := NewContact("Eve", "Jones", "", "", "")
+	for _, c := range []*Contact{wife, other} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+	if err := ab.AddLink(old.ID, RelationSpouse, wife.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+	if err := ab.AddLink(newer.ID, RelationSpouse, other.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+
+	if _, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{}); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if links := ab.Links(old.ID); len(links) != 1 || links[0].To != wife.ID {
+		t.Errorf("Expected the survivor's spouse kept, got %v", links)
+	}
+	if links := ab.Links(wife.ID); len(links) != 1 || links[0].To != old.ID {
+		t.Errorf("Expected the spouse link kept in both directions, got %v", links)
+	}
+	if links := ab.Links(other.ID); len(links) != 0 {
+		t.Errorf("Expected the conflicting spouse link dropped in both directions, got %v", links)
+	}
+}
+
+func TestMergeDropsReportingCycle(t *testing.T) {
+	ab, old, newer := mergeFixture(t)
+	lead := NewContact("Carl", "Lead", "", "", "")
+	if err := ab.AddContact(lead); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+	// old reports to lead, who reports to newer: merged, the chain loops
+	if err := ab.AddLink(old.ID, RelationManager, lead.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+	if err := ab.AddLink(lead.ID, RelationManager, newer.ID); err != nil {
+		t.Fatalf("Failed to link: %v", err)
+	}
+
+	if _, err := ab.Merge([]string{old.ID, newer.ID}, MergeOptions{}); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if links := ab.Links(old.ID); len(links) != 1 || links[0].To != lead.ID {
+		t.Errorf("Expected the survivor's manager kept, got %v", links)
+	}
+	if links := ab.Links(lead.ID); len(links) != 0 {
+		t.Errorf("Expected the link closing a reporting cycle dropped, got %v", links)
+	}
+}
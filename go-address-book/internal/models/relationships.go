@@ -0,0 +1,334 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RelationType is the kind of link between two contacts
+type RelationType string
+
+const (
+	RelationSpouse     RelationType = "spouse"
+	RelationManager    RelationType = "manager"
+	RelationAssistant  RelationType = "assistant"
+	RelationReferredBy RelationType = "referred-by"
+)
+
+// RelationTypes lists the supported relation types
+var RelationTypes = []RelationType{RelationSpouse, RelationManager, RelationAssistant, RelationReferredBy}
+
+// ParseRelationType parses a relation type name
+func ParseRelationType(name string) (RelationType, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, rel := range RelationTypes {
+		if string(rel) == name {
+			return rel, nil
+		}
+	}
+	return "", fmt.Errorf("unknown relation %q", name)
+}
+
+// Label describes the linked contact from the point of view of the link's owner
+func (r RelationType) Label() string {
+	switch r {
+	case RelationSpouse:
+		return "Spouse"
+	case RelationManager:
+		return "Manager"
+	case RelationAssistant:
+		return "Assistant"
+	case RelationReferredBy:
+		return "Referred by"
+	}
+	return string(r)
+}
+
+// InverseLabel describes the link's owner from the point of view of the linked contact
+func (r RelationType) InverseLabel() string {
+	switch r {
+	case RelationSpouse:
+		return "Spouse"
+	case RelationManager:
+		return "Direct report"
+	case RelationAssistant:
+		return "Assistant to"
+	case RelationReferredBy:
+		return "Referred"
+	}
+	return string(r)
+}
+
+// singleValued reports whether a contact may have at most one link of this type
+func (r RelationType) singleValued() bool {
+	return r != RelationAssistant
+}
+
+// Link says that contact To is the From contact's Type, e.g. To is From's manager
+type Link struct {
+	From string
+	Type RelationType
+	To   string
+}
+
+// Relative is a contact reached while walking the relationship graph
+type Relative struct {
+	Contact *Contact
+	Depth   int
+}
+
+// AddLink records that toID is fromID's rel. Spouse links are added in both directions.
+func (ab *AddressBook) AddLink(fromID string, rel RelationType, toID string) error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	if _, err := ParseRelationType(string(rel)); err != nil {
+		return err
+	}
+	if fromID == toID {
+		return errors.New("a contact cannot be related to itself")
+	}
+	if _, exists := ab.contacts[fromID]; !exists {
+		return errors.New("contact not found")
+	}
+	if _, exists := ab.contacts[toID]; !exists {
+		return errors.New("related contact not found")
+	}
+
+	link := Link{From: fromID, Type: rel, To: toID}
+	if ab.hasLink(link) {
+		return nil
+	}
+	if err := ab.checkLink(link); err != nil {
+		return err
+	}
+
+	ab.insertLink(link)
+	ab.publish([]Event{{Type: EventLinked, ID: fromID, Link: &link}})
+	return nil
+}
+
+// checkLink reports why a new link between two existing contacts would
+// conflict with the links already recorded
+func (ab *AddressBook) checkLink(link Link) error {
+	if link.Type.singleValued() && len(ab.linksOfType(link.From, link.Type)) > 0 {
+		return fmt.Errorf("contact already has a %s; remove it first", link.Type)
+	}
+	if link.Type == RelationSpouse && len(ab.linksOfType(link.To, link.Type)) > 0 {
+		return errors.New("related contact already has a spouse; remove it first")
+	}
+	if link.Type == RelationManager && ab.reportsUpTo(link.To, link.From) {
+		return errors.New("link would create a reporting cycle")
+	}
+	return nil
+}
+
+// insertLink records a link checked with checkLink, and its reverse for spouses
+func (ab *AddressBook) insertLink(link Link) {
+	ab.links[link.From] = append(ab.links[link.From], link)
+	if link.Type == RelationSpouse {
+		ab.links[link.To] = append(ab.links[link.To], Link{From: link.To, Type: link.Type, To: link.From})
+	}
+}
+
+// RemoveLink removes a link added with AddLink
+func (ab *AddressBook) RemoveLink(fromID string, rel RelationType, toID string) error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	link := Link{From: fromID, Type: rel, To: toID}
+	if !ab.hasLink(link) {
+		return errors.New("link not found")
+	}
+	ab.removeLinks(func(l Link) bool {
+		return l.Type == rel && ((l.From == fromID && l.To == toID) ||
+			(rel == RelationSpouse && l.From == toID && l.To == fromID))
+	})
+	ab.publish([]Event{{Type: EventUnlinked, ID: fromID, Link: &link}})
+	return nil
+}
+
+// Links returns the links owned by the contact
+func (ab *AddressBook) Links(id string) []Link {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	return append([]Link(nil), ab.links[id]...)
+}
+
+// LinksTo returns the links that point at the contact
+func (ab *AddressBook) LinksTo(id string) []Link {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	var results []Link
+	for _, links := range ab.links {
+		for _, link := range links {
+			if link.To == id {
+				results = append(results, link)
+			}
+		}
+	}
+	return results
+}
+
+// Reports returns everyone reporting to the contact, directly or through
+// other managers. Each report is followed by their own reports, so the
+// result reads as an org chart when indented by Depth.
+func (ab *AddressBook) Reports(managerID string) ([]Relative, error) {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	if _, exists := ab.contacts[managerID]; !exists {
+		return nil, errors.New("contact not found")
+	}
+
+	// Index manager links by manager so each level is a single lookup
+	reportsOf := make(map[string][]*Contact)
+	for from, links := range ab.links {
+		for _, link := range links {
+			if link.Type == RelationManager {
+				reportsOf[link.To] = append(reportsOf[link.To], ab.contacts[from])
+			}
+		}
+	}
+
+	var results []Relative
+	visited := map[string]bool{managerID: true}
+	var walk func(id string, depth int)
+	walk = func(id string, depth int) {
+		reports := reportsOf[id]
+		sortByName(reports)
+		for _, report := range reports {
+			if visited[report.ID] {
+				continue
+			}
+			visited[report.ID] = true
+			results = append(results, Relative{Contact: report.Clone(), Depth: depth})
+			walk(report.ID, depth+1)
+		}
+	}
+	walk(managerID, 1)
+	return results, nil
+}
+
+// hasLink reports whether the exact link exists
+func (ab *AddressBook) hasLink(link Link) bool {
+	for _, existing := range ab.links[link.From] {
+		if existing == link {
+			return true
+		}
+	}
+	return false
+}
+
+// linksOfType returns the contact's outgoing links of one type
+func (ab *AddressBook) linksOfType(id string, rel RelationType) []Link {
+	var results []Link
+	for _, link := range ab.links[id] {
+		if link.Type == rel {
+			results = append(results, link)
+		}
+	}
+	return results
+}
+
+// reportsUpTo reports whether following manager links upwards from start reaches target
+func (ab *AddressBook) reportsUpTo(start, target string) bool {
+	visited := make(map[string]bool)
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, link := range ab.linksOfType(current, RelationManager) {
+			queue = append(queue, link.To)
+		}
+	}
+	return false
+}
+
+// removeLinks drops every link matching the predicate and returns them,
+// ordered by owner, type and target
+func (ab *AddressBook) removeLinks(match func(Link) bool) []Link {
+	var removed []Link
+	for from, links := range ab.links {
+		kept := links[:0]
+		for _, link := range links {
+			if match(link) {
+				removed = append(removed, link)
+			} else {
+				kept = append(kept, link)
+			}
+		}
+		if len(kept) == 0 {
+			delete(ab.links, from)
+		} else {
+			ab.links[from] = kept
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		a, b := removed[i], removed[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.To < b.To
+	})
+	return removed
+}
+
+// linkEvents returns an event of the given type for each link. A spouse
+// link is stored in both directions but reported once, as AddLink and
+// RemoveLink do.
+func linkEvents(typ EventType, links []Link) []Event {
+	var events []Event
+	reported := make(map[Link]bool)
+	for _, link := range links {
+		if link.Type == RelationSpouse && reported[Link{From: link.To, Type: link.Type, To: link.From}] {
+			continue
+		}
+		reported[link] = true
+		events = append(events, Event{Type: typ, ID: link.From, Link: &link})
+	}
+	return events
+}
+
+// FormatLinks formats a contact's links as "type=id;type=id"
+func FormatLinks(links []Link) string {
+	entries := make([]string, len(links))
+	for i, link := range links {
+		entries[i] = string(link.Type) + "=" + link.To
+	}
+	return strings.Join(entries, ";")
+}
+
+// ParseLinks parses links written by FormatLinks for the contact with the given ID
+func ParseLinks(fromID, value string) ([]Link, error) {
+	var links []Link
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, to, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("relationship %q must be written as type=id", entry)
+		}
+		rel, err := ParseRelationType(name)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, Link{From: fromID, Type: rel, To: strings.TrimSpace(to)})
+	}
+	return links, nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestRelationships(t *testing.T) {
+	ab := NewAddressBook()
+	ceo := NewContact("Ada", "Boss", "ada@example.com", "1", "A")
+	vp := NewContact("Bob", "Vice", "bob@example.com", "2", "B")
+	dev := NewContact("Cy", "Dev", "cy@example.com", "3", "C")
+	ops := NewContact("Di", "Ops", "di@example.com", "4", "D")
+	spouse := NewContact("Ed", "Boss", "ed@example.com", "5", "E")
+	for _, c := range []*Contact{ceo, vp, dev, ops, spouse} {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatalf("Failed to add contact: %v", err)
+		}
+	}
+
+	mustLink := func(from *Contact, rel RelationType, to *Contact) {
+		t.Helper()
+		if err := ab.AddLink(from.ID, rel, to.ID); err != nil {
+			t.Fatalf("Failed to link %s -> %s: %v", from.FirstName, to.FirstName, err)
+		}
+	}
+	mustLink(vp, RelationManager, ceo)
+	mustLink(ops, RelationManager, ceo)
+	mustLink(dev, RelationManager, vp)
+	mustLink(ceo, RelationSpouse, spouse)
+
+	reports, err := ab.Reports(ceo.ID)
+	if err != nil {
+		t.Fatalf("Reports failed: %v", err)
+	}
+	want := []struct {
+		contact *Contact
+		depth   int
+	}{{ops, 1}, {vp, 1}, {dev, 2}}
+	if len(reports) != len(want) {
+		t.Fatalf("Expected %d reports, got %d", len(want), len(reports))
+	}
+	for i, w := range want {
+		if reports[i].Contact.ID != w.contact.ID || reports[i].Depth != w.depth {
+			t.Errorf("Report %d: got %s at depth %d, want %s at depth %d",
+				i, reports[i].Contact.FirstName, reports[i].Depth, w.contact.FirstName, w.depth)
+		}
+	}
+
+	if links := ab.Links(spouse.ID); len(links) != 1 || links[0].To != ceo.ID {
+		t.Error("Expected spouse link in both directions")
+	}
+	if err := ab.AddLink(ceo.ID, RelationManager, dev.ID); err == nil {
+		t.Error("Expected error for a reporting cycle")
+	}
+	if err := ab.AddLink(dev.ID, RelationManager, ceo.ID); err == nil {
+		t.Error("Expected error for a second manager")
+	}
+	if err := ab.AddLink(dev.ID, RelationManager, dev.ID); err == nil {
+		t.Error("Expected error for a self link")
+	}
+
+	// Deleting a contact drops every link that mentions it
+	if err := ab.DeleteContact(vp.ID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+	if links := ab.Links(dev.ID); len(links) != 0 {
+		t.Errorf("Expected dangling manager link to be removed, got %v", links)
+	}
+	if links := ab.LinksTo(vp.ID); len(links) != 0 {
+		t.Errorf("Expected no links to deleted contact, got %v", links)
+	}
+
+	if err := ab.RemoveLink(spouse.ID, RelationSpouse, ceo.ID); err != nil {
+		t.Fatalf("Failed to remove link: %v", err)
+	}
+	if links := ab.Links(ceo.ID); len(links) != 0 {
+		t.Errorf("Expected spouse link removed in both directions, got %v", links)
+	}
+}
+
+func TestLinksRoundTrip(t *testing.T) {
+	links := []Link{
+		{From: "a", Type: RelationManager, To: "b"},
+		{From: "a", Type: RelationReferredBy, To: "c"},
+	}
+	parsed, err := ParseLinks("a", FormatLinks(links))
+	if err != nil {
+		t.Fatalf("ParseLinks failed: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0] != links[0] || parsed[1] != links[1] {
+		t.Errorf("Round trip mismatch: %v", parsed)
+	}
+	if _, err := ParseLinks("a", "boss=b"); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+}
@@ -0,0 +1,404 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// SortKey orders contacts by one field
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + k.Field
+	}
+	return k.Field
+}
+
+// DefaultSort orders contacts by last name, then first name
+var DefaultSort = []SortKey{{Field: "last"}, {Field: "first"}}
+
+// sortKind decides how a field's values are compared
+type sortKind int
+
+const (
+	sortText   sortKind = iota // collated for the locale, ignoring case
+	sortNumber                 // parsed as floats
+	sortRaw                    // compared byte by byte, for IDs and fixed-width times
+)
+
+// sortField is something contacts can be ordered by
+type sortField struct {
+	name  string
+	kind  sortKind
+	value func(c *Contact) string
+}
+
+// sortTimeLayout formats times at a fixed width so they compare as strings
+const sortTimeLayout = "2006-01-02T15:04:05.000000000"
+
+func sortTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(sortTimeLayout)
+}
+
+// sortFields are the builtin fields contacts can be sorted by
+var sortFields = []*sortField{
+	{"first", sortText, func(c *Contact) string { return c.FirstName }},
+	{"last", sortText, func(c *Contact) string { return c.LastName }},
+	{"name", sortText, func(c *Contact) string { return strings.TrimSpace(c.FirstName + " " + c.LastName) }},
+	{"email", sortText, func(c *Contact) string { return c.Email }},
+	{"phone", sortText, func(c *Contact) string { return c.Phone }},
+	{"city", sortText, func(c *Contact) string { return c.City() }},
+	{"org", sortText, func(c *Contact) string { return c.Organization }},
+	{"company", sortText, func(c *Contact) string { return c.Company() }},
+	{"title", sortText, func(c *Contact) string { return c.Title }},
+	{"dept", sortText, func(c *Contact) string { return c.Department }},
+	{"created", sortRaw, func(c *Contact) string { return sortTime(c.CreatedAt) }},
+	{"updated", sortRaw, func(c *Contact) string { return sortTime(c.UpdatedAt) }},
+	{"contacted", sortRaw, func(c *Contact) string {
+		at, _ := c.LastContacted()
+		return sortTime(at)
+	}},
+	{"id", sortRaw, func(c *Contact) string { return c.ID }},
+}
+
+// sortAliases are other accepted spellings of sort field names
+var sortAliases = map[string]string{
+	"firstname": "first", "lastname": "last", "organization": "org", "department": "dept",
+}
+
+// SortFields lists the builtin field names contacts can be sorted by
+func SortFields() []string {
+	names := make([]string, len(sortFields))
+	for i, f := range sortFields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// lookupSortField finds a builtin or custom field by name, ignoring case
+func lookupSortField(name string, schema Schema) (*sortField, bool) {
+	lower := strings.ToLower(name)
+	if canonical, ok := sortAliases[lower]; ok {
+		lower = canonical
+	}
+	for _, f := range sortFields {
+		if f.name == lower {
+			return f, true
+		}
+	}
+	for _, def := range schema {
+		if strings.EqualFold(def.Name, name) {
+			custom := def.Name
+			f := &sortField{name: custom, kind: sortText, value: func(c *Contact) string { return c.Custom[custom] }}
+			switch def.Type {
+			case FieldNumber:
+				f.kind = sortNumber
+			case FieldDate:
+				f.kind = sortRaw
+			}
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// ParseSort parses a comma-separated list of fields such as
+// "last,first,-created", where a leading "-" sorts that field in descending
+// order. Fields are those listed by SortFields plus the custom fields in
+// schema.
+func ParseSort(spec string, schema Schema) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key.Desc, part = true, strings.TrimSpace(name)
+		} else {
+			part = strings.TrimSpace(strings.TrimPrefix(part, "+"))
+		}
+		f, ok := lookupSortField(part, schema)
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q; fields are %s and custom fields",
+				part, strings.Join(SortFields(), ", "))
+		}
+		key.Field = f.name
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("sort needs at least one field")
+	}
+	return keys, nil
+}
+
+// sorter orders contacts by a list of keys, breaking ties by ID so the
+// order is always the same
+type sorter struct {
+	keys     []SortKey
+	fields   []*sortField
+	collator *collate.Collator
+	buf      collate.Buffer
+}
+
+func newSorter(keys []SortKey, locale string, schema Schema) (*sorter, error) {
+	tag := language.Und
+	if locale != "" {
+		var err error
+		if tag, err = language.Parse(locale); err != nil {
+			return nil, fmt.Errorf("invalid locale %q: %w", locale, err)
+		}
+	}
+	s := &sorter{keys: keys, collator: collate.New(tag, collate.IgnoreCase)}
+	for _, key := range keys {
+		f, ok := lookupSortField(key.Field, schema)
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", key.Field)
+		}
+		s.fields = append(s.fields, f)
+	}
+	return s, nil
+}
+
+// sortRow is a contact with its precomputed sort values
+type sortRow struct {
+	contact *Contact
+	values  []string // raw values, kept for cursors
+	keys    []sortValue
+}
+
+type sortValue struct {
+	text  string // collation key for text, the raw value otherwise
+	num   float64
+	empty bool
+}
+
+// row builds a row from a contact's raw field values
+func (s *sorter) row(c *Contact) sortRow {
+	values := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		values[i] = f.value(c)
+	}
+	return sortRow{contact: c, values: values, keys: s.keysOf(values)}
+}
+
+// keysOf turns raw values into values that compare directly
+func (s *sorter) keysOf(values []string) []sortValue {
+	keys := make([]sortValue, len(values))
+	for i, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			keys[i].empty = true
+			continue
+		}
+		switch s.fields[i].kind {
+		case sortText:
+			keys[i].text = string(s.collator.KeyFromString(&s.buf, value))
+			s.buf.Reset()
+		case sortNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			keys[i].num, keys[i].empty = n, err != nil
+		default:
+			keys[i].text = value
+		}
+	}
+	return keys
+}
+
+// compare orders two rows. Empty values go last whichever the direction.
+func (s *sorter) compare(a []sortValue, aID string, b []sortValue, bID string) int {
+	for i, key := range s.keys {
+		x, y := a[i], b[i]
+		if x.empty || y.empty {
+			switch {
+			case x.empty && y.empty:
+				continue
+			case x.empty:
+				return 1
+			default:
+				return -1
+			}
+		}
+		var c int
+		if s.fields[i].kind == sortNumber {
+			switch {
+			case x.num < y.num:
+				c = -1
+			case x.num > y.num:
+				c = 1
+			}
+		} else {
+			c = strings.Compare(x.text, y.text)
+		}
+		if key.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(aID, bID)
+}
+
+// SortContacts sorts contacts in place by keys, collating text for the
+// given BCP 47 locale such as "de" or "sv-SE" ("" uses the Unicode root
+// collation). Ties are broken by ID, so the order is stable across runs.
+func SortContacts(contacts []*Contact, keys []SortKey, locale string, schema Schema) error {
+	s, err := newSorter(keys, locale, schema)
+	if err != nil {
+		return err
+	}
+	s.sort(contacts)
+	return nil
+}
+
+func (s *sorter) sort(contacts []*Contact) []sortRow {
+	rows := make([]sortRow, len(contacts))
+	for i, c := range contacts {
+		rows[i] = s.row(c)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return s.compare(rows[i].keys, rows[i].contact.ID, rows[j].keys, rows[j].contact.ID) < 0
+	})
+	for i, row := range rows {
+		contacts[i] = row.contact
+	}
+	return rows
+}
+
+// ListOptions controls the order of List results and which page is returned
+type ListOptions struct {
+	Sort   []SortKey // defaults to DefaultSort for List; nil keeps the given order in Paginate
+	Locale string    // BCP 47 tag used to collate text, "" for the Unicode root collation
+	Offset int       // contacts to skip, ignored when After is set
+	Limit  int       // page size, 0 for no limit
+	After  string    // Page.Next of the previous page
+}
+
+// Page is one page of contacts
+type Page struct {
+	Contacts []*Contact
+	Total    int    // contacts across all pages
+	Offset   int    // position of the first contact on this page
+	Next     string // cursor for the following page, "" on the last page
+}
+
+// cursor marks the last contact of a page. It holds that contact's sort
+// values rather than its position, so contacts added or deleted before it
+// do not shift the next page.
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v,omitempty"`
+	ID     string   `json:"id,omitempty"`
+	Offset int      `json:"o,omitempty"` // used when there are no sort keys
+}
+
+func sortSpec(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(text string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(text)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return c, errors.New("invalid page cursor")
+	}
+	return c, nil
+}
+
+// List returns a page of copies of all contacts, sorted by opts.Sort
+// (DefaultSort when empty). Pages are requested either by Offset or by
+// passing the previous page's Next cursor as After.
+func (ab *AddressBook) List(opts ListOptions) (*Page, error) {
+	if len(opts.Sort) == 0 {
+		opts.Sort = DefaultSort
+	}
+	return Paginate(ab.GetAllContacts(), opts, ab.Schema())
+}
+
+// Paginate sorts contacts in place by opts.Sort, keeping their order when
+// it is empty, and returns the page opts selects
+func Paginate(contacts []*Contact, opts ListOptions, schema Schema) (*Page, error) {
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return nil, errors.New("offset and limit must not be negative")
+	}
+	spec := sortSpec(opts.Sort)
+
+	var rows []sortRow
+	var s *sorter
+	if len(opts.Sort) > 0 {
+		var err error
+		if s, err = newSorter(opts.Sort, opts.Locale, schema); err != nil {
+			return nil, err
+		}
+		rows = s.sort(contacts)
+	}
+
+	start := opts.Offset
+	if opts.After != "" {
+		after, err := decodeCursor(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		if after.Sort != spec {
+			return nil, fmt.Errorf("page cursor is for sort order %q, not %q", after.Sort, spec)
+		}
+		if s == nil {
+			start = after.Offset
+		} else {
+			if len(after.Values) != len(opts.Sort) {
+				return nil, errors.New("invalid page cursor")
+			}
+			keys := s.keysOf(after.Values)
+			start = sort.Search(len(rows), func(i int) bool {
+				return s.compare(rows[i].keys, rows[i].contact.ID, keys, after.ID) > 0
+			})
+		}
+	}
+	start = min(start, len(contacts))
+	end := len(contacts)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, end)
+	}
+
+	page := &Page{Contacts: contacts[start:end], Total: len(contacts), Offset: start}
+	if end < len(contacts) && end > start {
+		next := cursor{Sort: spec}
+		if s == nil {
+			next.Offset = end
+		} else {
+			next.Values, next.ID = rows[end-1].values, rows[end-1].contact.ID
+		}
+		page.Next = next.encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func sortFixture(t *testing.T) *AddressBook {
+	ab := NewAddressBook()
+	if err := ab.SetSchema(Schema{{Name: "Score", Type: FieldNumber}}); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	people := []struct{ first, last, score string }{
+		{"Zoë", "Ångström", "10"},
+		{"anna", "smith", "9"},
+		{"Émile", "Zola", ""},
+		{"Bob", "Smith", "100"},
+		{"Eric", "Adams", "-1"},
+	}
+	for i, p := range people {
+		c := NewContact(p.first, p.last, "", "", "")
+		c.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		if p.score != "" {
+			c.Custom = map[string]string{"Score": p.score}
+		}
+		if err := ab.AddContact(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ab
+}
+
+func firstNames(contacts []*Contact) string {
+	names := make([]string, len(contacts))
+	for i, c := range contacts {
+		names[i] = c.FirstName
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSortContacts(t *testing.T) {
+	ab := sortFixture(t)
+	tests := []struct {
+		spec   string
+		locale string
+		want   string
+	}{
+		{"last,first", "", "Eric Zoë anna Bob Émile"},
+		{"last,first", "sv", "Eric anna Bob Émile Zoë"}, // Swedish puts Å after Z
+		{"-last,first", "", "Émile anna Bob Zoë Eric"},
+		{"first", "", "anna Bob Émile Eric Zoë"},
+		{"-created", "", "Eric Bob Émile anna Zoë"},
+		{"score", "", "Eric anna Zoë Bob Émile"}, // numbers compare as numbers, empty last
+		{"-score", "", "Bob Zoë anna Eric Émile"},
+		{"lastname,-firstname", "", "Eric Zoë Bob anna Émile"},
+	}
+	for _, tt := range tests {
+		keys, err := ParseSort(tt.spec, ab.Schema())
+		if err != nil {
+			t.Errorf("ParseSort(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		contacts := ab.GetAllContacts()
+		if err := SortContacts(contacts, keys, tt.locale, ab.Schema()); err != nil {
+			t.Errorf("SortContacts(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if got := firstNames(contacts); got != tt.want {
+			t.Errorf("Sorting by %q in %q gave %s, want %s", tt.spec, tt.locale, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"bogus", "", " , "} {
+		if _, err := ParseSort(spec, nil); err == nil {
+			t.Errorf("Expected ParseSort(%q) to fail", spec)
+		}
+	}
+	if err := SortContacts(nil, DefaultSort, "not a locale!", nil); err == nil {
+		t.Error("Expected an invalid locale to fail")
+	}
+}
+
+func TestGetAllContactsOrder(t *testing.T) {
+	ab := sortFixture(t)
+	for i := 0; i < 5; i++ {
+		if got := firstNames(ab.GetAllContacts()); got != "Zoë anna Émile Bob Eric" {
+			t.Fatalf("Expected contacts in creation order, got %s", got)
+		}
+	}
+}
+
+func TestListPages(t *testing.T) {
+	ab := sortFixture(t)
+
+	page, err := ab.List(ListOptions{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := firstNames(page.Contacts); got != "Zoë anna" || page.Total != 5 || page.Offset != 1 {
+		t.Errorf("Offset page = %s (total %d, offset %d)", got, page.Total, page.Offset)
+	}
+
+	// Walk every page by cursor
+	var names []string
+	opts := ListOptions{Limit: 2}
+	for {
+		page, err := ab.List(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, firstNames(page.Contacts))
+		if page.Next == "" {
+			break
+		}
+		opts.After = page.Next
+	}
+	if got := strings.Join(names, " | "); got != "Eric Zoë | anna Bob | Émile" {
+		t.Errorf("Cursor pages = %s", got)
+	}
+
+	// A cursor survives changes before it: deleting a contact on the first
+	// page does not make the second page skip anyone
+	first, _ := ab.List(ListOptions{Limit: 2})
+	if err := ab.DeleteContact(first.Contacts[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	second, err := ab.List(ListOptions{Limit: 2, After: first.Next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := firstNames(second.Contacts); got != "anna Bob" {
+		t.Errorf("Expected the second page to be unchanged, got %s", got)
+	}
+
+	if _, err := ab.List(ListOptions{Limit: 2, After: first.Next, Sort: []SortKey{{Field: "first"}}}); err == nil {
+		t.Error("Expected a cursor from another sort order to fail")
+	}
+	if _, err := ab.List(ListOptions{After: "garbage"}); err == nil {
+		t.Error("Expected an invalid cursor to fail")
+	}
+	if page, _ := ab.List(ListOptions{Offset: 10}); len(page.Contacts) != 0 || page.Next != "" {
+		t.Error("Expected an offset past the end to give an empty last page")
+	}
+}
+
+func TestPaginateKeepsOrder(t *testing.T) {
+	ab := sortFixture(t)
+	contacts := ab.GetAllContacts()
+	page, err := Paginate(contacts, ListOptions{Limit: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := Paginate(contacts, ListOptions{Limit: 3, After: page.Next}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := firstNames(page.Contacts) + " | " + firstNames(next.Contacts); got != "Zoë anna Émile | Bob Eric" {
+		t.Errorf("Expected pages in the given order, got %s", got)
+	}
+}
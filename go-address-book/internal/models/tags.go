@@ -0,0 +1,74 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ParseTags splits a comma-separated list into lowercase tags, dropping
+// blanks and repeats
+func ParseTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		tags = append(tags, strings.ToLower(strings.TrimSpace(tag)))
+	}
+	tags = distinct(tags)
+	sort.Strings(tags)
+	return tags
+}
+
+// HasTag reports whether the contact carries the tag, ignoring case
+func (c *Contact) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds the tags the contact doesn't carry yet, reporting whether
+// any were added
+func (c *Contact) AddTags(tags ...string) bool {
+	added := false
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !c.HasTag(tag) {
+			c.Tags = append(c.Tags, tag)
+			added = true
+		}
+	}
+	if added {
+		sort.Strings(c.Tags)
+	}
+	return added
+}
+
+// RemoveTags removes the tags, ignoring case, reporting whether the
+// contact carried any of them
+func (c *Contact) RemoveTags(tags ...string) bool {
+	var kept []string
+	for _, t := range c.Tags {
+		remove := false
+		for _, tag := range tags {
+			remove = remove || strings.EqualFold(t, strings.TrimSpace(tag))
+		}
+		if !remove {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(c.Tags) {
+		return false
+	}
+	c.Tags = kept
+	return true
+}
+
+// City returns the city from an address written as "street, city, state zip",
+// or "" when the address has no city part
+func (c *Contact) City() string {
+	parts := strings.Split(c.Address, ",")
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.TrimSpace(parts[len(parts)-2])
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OpKind is the kind of change an Op makes
+type OpKind string
+
+const (
+	OpAdd    OpKind = "add"
+	OpUpdate OpKind = "update"
+	OpDelete OpKind = "delete"
+)
+
+// Op is a single change applied as part of a batch
+type Op struct {
+	Kind    OpKind
+	Contact *Contact // for OpAdd and OpUpdate
+	ID      string   // for OpDelete
+}
+
+// AddOp adds contact
+func AddOp(contact *Contact) Op {
+	return Op{Kind: OpAdd, Contact: contact}
+}
+
+// UpdateOp replaces the stored contact with contact, subject to its version
+func UpdateOp(contact *Contact) Op {
+	return Op{Kind: OpUpdate, Contact: contact}
+}
+
+// DeleteOp deletes the contact with the given ID
+func DeleteOp(id string) Op {
+	return Op{Kind: OpDelete, ID: id}
+}
+
+// target returns the ID of the contact the op changes
+func (op Op) target() string {
+	if op.Contact != nil {
+		return op.Contact.ID
+	}
+	return op.ID
+}
+
+// Apply runs ops as one transaction: either every op succeeds and all of
+// them take effect together, or the address book is left unchanged and the
+// error of the first failing op is returned. Contacts passed to add and
+// update ops get their Version and UpdatedAt set only if the batch commits.
+func (ab *AddressBook) Apply(ops []Op) error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	tx := ab.begin()
+	if err := tx.applyAll(ops); err != nil {
+		return err
+	}
+	tx.commit()
+	return nil
+}
+
+// begin starts a transaction. It must be called with ab.mu held.
+func (ab *AddressBook) begin() *transaction {
+	return &transaction{
+		ab:      ab,
+		staged:  make(map[string]*Contact),
+		deleted: make(map[string]bool),
+		now:     time.Now(),
+	}
+}
+
+// transaction stages changes on top of the address book until commit
+type transaction struct {
+	ab      *AddressBook
+	staged  map[string]*Contact // nil marks a contact deleted in this transaction
+	deleted map[string]bool
+	touched []touchedContact
+	events  []Event
+	now     time.Time
+}
+
+// touchedContact remembers a caller's contact so its bookkeeping fields can
+// be restored when the transaction rolls back
+type touchedContact struct {
+	contact   *Contact
+	version   int
+	updatedAt time.Time
+}
+
+// lookup returns the contact as seen from inside the transaction
+func (tx *transaction) lookup(id string) (*Contact, bool) {
+	if contact, staged := tx.staged[id]; staged {
+		return contact, contact != nil
+	}
+	contact, exists := tx.ab.contacts[id]
+	return contact, exists
+}
+
+// applyAll stages ops in order, rolling back on the first failure
+func (tx *transaction) applyAll(ops []Op) error {
+	for i, op := range ops {
+		if err := tx.apply(op); err != nil {
+			tx.rollback()
+			if len(ops) == 1 {
+				return err
+			}
+			return fmt.Errorf("operation %d of %d (%s %s): %w", i+1, len(ops), op.Kind, op.target(), err)
+		}
+	}
+	return nil
+}
+
+func (tx *transaction) apply(op Op) error {
+	switch op.Kind {
+	case OpAdd:
+		if op.Contact == nil {
+			return errors.New("add needs a contact")
+		}
+		if _, exists := tx.lookup(op.Contact.ID); exists {
+			return errors.New("contact with this ID already exists")
+		}
+		if err := tx.ab.validate(op.Contact); err != nil {
+			return err
+		}
+		tx.touch(op.Contact)
+		if op.Contact.Version == 0 {
+			op.Contact.Version = 1
+		}
+		after := op.Contact.Clone()
+		tx.staged[op.Contact.ID] = after
+		tx.events = append(tx.events, Event{Type: EventAdded, ID: after.ID, After: after})
+
+	case OpUpdate:
+		if op.Contact == nil {
+			return errors.New("update needs a contact")
+		}
+		current, exists := tx.lookup(op.Contact.ID)
+		if !exists {
+			return errors.New("contact not found")
+		}
+		if op.Contact.Version != current.Version {
+			return &ConflictError{ID: op.Contact.ID, Expected: op.Contact.Version, Actual: current.Version}
+		}
+		if err := tx.ab.validate(op.Contact); err != nil {
+			return err
+		}
+		tx.touch(op.Contact)
+		op.Contact.UpdatedAt = tx.now
+		op.Contact.Version++
+		after := op.Contact.Clone()
+		tx.staged[op.Contact.ID] = after
+		tx.events = append(tx.events, Event{Type: EventUpdated, ID: after.ID, Before: current, After: after})
+
+	case OpDelete:
+		current, exists := tx.lookup(op.ID)
+		if !exists {
+			return errors.New("contact not found")
+		}
+		tx.staged[op.ID] = nil
+		tx.deleted[op.ID] = true
+		tx.events = append(tx.events, Event{Type: EventDeleted, ID: op.ID, Before: current})
+
+	default:
+		return fmt.Errorf("unknown operation %q", op.Kind)
+	}
+	return nil
+}
+
+// touch records the caller's contact before the transaction changes it
+func (tx *transaction) touch(contact *Contact) {
+	tx.touched = append(tx.touched, touchedContact{
+		contact:   contact,
+		version:   contact.Version,
+		updatedAt: contact.UpdatedAt,
+	})
+}
+
+// rollback undoes changes made to the caller's contacts, newest first
+func (tx *transaction) rollback() {
+	for i := len(tx.touched) - 1; i >= 0; i-- {
+		t := tx.touched[i]
+		t.contact.Version = t.version
+		t.contact.UpdatedAt = t.updatedAt
+	}
+}
+
+// commit makes the staged changes visible and notifies subscribers
+func (tx *transaction) commit() {
+	ab := tx.ab
+	for id := range tx.deleted {
+		if contact, exists := ab.contacts[id]; exists {
+			for _, alias := range contact.Aliases {
+				delete(ab.aliases, alias)
+			}
+		}
+		delete(ab.contacts, id)
+		ab.index.remove(id)
+	}
+	if len(tx.deleted) > 0 {
+		removed := ab.removeLinks(func(l Link) bool {
+			return tx.deleted[l.From] || tx.deleted[l.To]
+		})
+		tx.events = append(tx.events, linkEvents(EventUnlinked, removed)...)
+	}
+	for id, contact := range tx.staged {
+		if contact != nil {
+			ab.contacts[id] = contact
+			ab.index.add(contact)
+			for _, alias := range contact.Aliases {
+				ab.aliases[alias] = id
+			}
+		}
+	}
+	ab.publish(tx.events)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyCommitsAllOps(t *testing.T) {
+	ab := NewAddressBook()
+	keep := NewContact("Keep", "Me", "keep@example.com", "1", "A")
+	gone := NewContact("Delete", "Me", "gone@example.com", "2", "B")
+	if err := ab.Apply([]Op{AddOp(keep), AddOp(gone)}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := ab.AddLink(keep.ID, RelationSpouse, gone.ID); err != nil {
+		t.Fatalf("Failed to link contacts: %v", err)
+	}
+
+	added := NewContact("New", "Person", "new@example.com", "3", "C")
+	keep.Email = "kept@example.com"
+	err := ab.Apply([]Op{AddOp(added), UpdateOp(keep), DeleteOp(gone.ID)})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if len(ab.GetAllContacts()) != 2 {
+		t.Errorf("Expected 2 contacts, got %d", len(ab.GetAllContacts()))
+	}
+	stored, _ := ab.GetContact(keep.ID)
+	if stored.Email != "kept@example.com" || stored.Version != 2 || keep.Version != 2 {
+		t.Errorf("Expected update applied at version 2, got %s at %d", stored.Email, stored.Version)
+	}
+	if links := ab.Links(keep.ID); len(links) != 0 {
+		t.Errorf("Expected links to deleted contact removed, got %v", links)
+	}
+}
+
+func TestApplyRollsBackOnError(t *testing.T) {
+	ab := NewAddressBook()
+	existing := NewContact("Existing", "Person", "e@example.com", "1", "A")
+	if err := ab.AddContact(existing); err != nil {
+		t.Fatalf("Failed to add contact: %v", err)
+	}
+
+	first := NewContact("First", "New", "f@example.com", "2", "B")
+	update, _ := ab.GetContact(existing.ID)
+	update.FirstName = "Changed"
+	stale, _ := ab.GetContact(existing.ID)
+
+	err := ab.Apply([]Op{AddOp(first), UpdateOp(update), UpdateOp(stale), DeleteOp(existing.ID)})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected ConflictError from third op, got %v", err)
+	}
+
+	if len(ab.GetAllContacts()) != 1 {
+		t.Errorf("Expected no contacts added, got %d", len(ab.GetAllContacts()))
+	}
+	stored, _ := ab.GetContact(existing.ID)
+	if stored.FirstName != "Existing" || stored.Version != 1 {
+		t.Errorf("Expected contact unchanged, got %s at version %d", stored.FirstName, stored.Version)
+	}
+	if first.Version != 0 || update.Version != 1 {
+		t.Errorf("Expected caller versions restored, got %d and %d", first.Version, update.Version)
+	}
+
+	// Ops see the effects of earlier ops in the same batch
+	err = ab.Apply([]Op{DeleteOp(existing.ID), DeleteOp(existing.ID)})
+	if err == nil {
+		t.Error("Expected second delete of the same contact to fail")
+	}
+	if _, err := ab.GetContact(existing.ID); err != nil {
+		t.Error("Expected failed batch to leave contact in place")
+	}
+}
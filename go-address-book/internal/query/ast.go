@@ -0,0 +1,236 @@
+package query
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Expr is a parsed query that can be tested against contacts
+type Expr interface {
+	// Match reports whether the contact satisfies the expression
+	Match(c *models.Contact) bool
+	// String returns the expression in query syntax, fully parenthesized
+	String() string
+}
+
+// And matches contacts that match every one of its terms
+type And struct {
+	Terms []Expr
+}
+
+// Or matches contacts that match at least one of its terms
+type Or struct {
+	Terms []Expr
+}
+
+// Not matches contacts that do not match its expression
+type Not struct {
+	Expr Expr
+}
+
+// Term compares one field of a contact with a value. A term without a
+// field is a bare word, matched against every text field.
+type Term struct {
+	Field  string // canonical field name, "" for bare words
+	Op     string // one of : = != > >= < <=, ":" for bare words
+	Value  string
+	Quoted bool // Value was written in double quotes
+	Pos    int  // byte offset of the term in the query
+
+	field  *field
+	number float64 // Value for number fields
+	date   string  // Value for date fields, as YYYY-MM-DD
+}
+
+func (e *And) Match(c *models.Contact) bool {
+	for _, term := range e.Terms {
+		if !term.Match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Or) Match(c *models.Contact) bool {
+	for _, term := range e.Terms {
+		if term.Match(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Not) Match(c *models.Contact) bool {
+	return !e.Expr.Match(c)
+}
+
+func (t *Term) Match(c *models.Contact) bool {
+	if t.Op == "!=" {
+		return !t.compare(c, "=")
+	}
+	return t.compare(c, t.Op)
+}
+
+// compare tests the term's field with op, which is never "!="
+func (t *Term) compare(c *models.Contact, op string) bool {
+	switch t.field.kind {
+	case kindNumber:
+		for _, text := range t.field.values(c) {
+			if n, err := strconv.ParseFloat(text, 64); err == nil && compareOrdered(n, t.number, op) {
+				return true
+			}
+		}
+		return false
+	case kindDate:
+		for _, text := range t.field.values(c) {
+			if compareOrdered(text, t.date, op) {
+				return true
+			}
+		}
+		return false
+	}
+
+	value := strings.ToLower(t.Value)
+	wildcard := strings.ContainsAny(value, "*?")
+	if t.isWord() {
+		return matchWords(models.Tokenize(value), t.field.values(c))
+	}
+	for _, text := range t.field.values(c) {
+		text = strings.ToLower(text)
+		switch {
+		case wildcard && glob(value, text):
+			return true
+		case !wildcard && op == "=" && text == value:
+			return true
+		case !wildcard && op == ":" && strings.Contains(text, value):
+			return true
+		}
+	}
+	return false
+}
+
+// isWord reports whether the term is a bare word, which like full-text
+// search matches the start of a word rather than any part of a value
+func (t *Term) isWord() bool {
+	return t.Field == "" && !t.Quoted && !strings.ContainsAny(t.Value, "*?") &&
+		!strings.ContainsFunc(t.Value, unicode.IsSpace) && len(models.Tokenize(t.Value)) > 0
+}
+
+// matchWords reports whether each word starts a word of one of the values
+func matchWords(words, values []string) bool {
+	var have []string
+	for _, value := range values {
+		have = append(have, models.Tokenize(value)...)
+	}
+	for _, word := range words {
+		if !slices.ContainsFunc(have, func(w string) bool { return strings.HasPrefix(w, word) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareOrdered applies a comparison operator; ":" means equal
+func compareOrdered[T int | float64 | string](a, b T, op string) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	default:
+		return a == b
+	}
+}
+
+// glob matches text against a pattern where * is any run of characters
+// and ? is any single character
+func glob(pattern, text string) bool {
+	p, t := []rune(pattern), []rune(text)
+	star, mark := -1, 0
+	i, j := 0, 0
+	for j < len(t) {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == t[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, mark = i, j
+			i++
+		case star >= 0:
+			i = star + 1
+			mark++
+			j = mark
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+func (e *And) String() string { return joinTerms(e.Terms, " AND ") }
+
+func (e *Or) String() string { return joinTerms(e.Terms, " OR ") }
+
+func (e *Not) String() string { return "-" + e.Expr.String() }
+
+func (t *Term) String() string {
+	value := t.Value
+	if value == "" || t.Quoted || strings.ContainsAny(value, " \t()\"") {
+		value = strconv.Quote(value)
+	}
+	if t.Field == "" {
+		return value
+	}
+	field := t.Field
+	if strings.ContainsAny(field, " \t") {
+		field = strconv.Quote(field)
+	}
+	return field + t.Op + value
+}
+
+func joinTerms(terms []Expr, sep string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// dayOf formats a time as a local calendar date
+func dayOf(t time.Time) string {
+	return t.Local().Format(models.DateLayout)
+}
+
+// PlainWords returns the words of a query made only of bare words, such
+// as "john smith". It reports false for queries that use fields,
+// operators, wildcards or quoted text, which need the evaluator rather
+// than full-text search.
+func PlainWords(e Expr) ([]string, bool) {
+	switch e := e.(type) {
+	case *Term:
+		return []string{e.Value}, e.isWord()
+	case *And:
+		var words []string
+		for _, term := range e.Terms {
+			w, ok := PlainWords(term)
+			if !ok {
+				return nil, false
+			}
+			words = append(words, w...)
+		}
+		return words, true
+	}
+	return nil, false
+}
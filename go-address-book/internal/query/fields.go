@@ -0,0 +1,169 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/match"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// valueKind decides how a field's values are compared
+type valueKind int
+
+const (
+	kindText valueKind = iota
+	kindNumber
+	kindDate // values are YYYY-MM-DD
+)
+
+// field is something a query term can look at. Fields with several values,
+// such as tags or additional emails, match when any value does.
+type field struct {
+	name   string
+	kind   valueKind
+	values func(c *models.Contact) []string
+}
+
+// one wraps a single value
+func one(value string) []string {
+	return []string{value}
+}
+
+// builtinFields are the fields every contact has
+var builtinFields = []*field{
+	{"first", kindText, func(c *models.Contact) []string { return one(c.FirstName) }},
+	{"last", kindText, func(c *models.Contact) []string { return one(c.LastName) }},
+	{"name", kindText, func(c *models.Contact) []string { return one(c.FirstName + " " + c.LastName) }},
+	{"email", kindText, func(c *models.Contact) []string { return append(one(c.Email), c.OtherEmails...) }},
+	{"phone", kindText, phoneValues},
+	{"address", kindText, func(c *models.Contact) []string { return append(one(c.Address), c.OtherAddresses...) }},
+	{"city", kindText, func(c *models.Contact) []string { return one(c.City()) }},
+	{"org", kindText, func(c *models.Contact) []string { return one(c.Organization) }},
+	{"company", kindText, func(c *models.Contact) []string { return one(c.Company()) }},
+	{"title", kindText, func(c *models.Contact) []string { return one(c.Title) }},
+	{"dept", kindText, func(c *models.Contact) []string { return one(c.Department) }},
+	{"tag", kindText, func(c *models.Contact) []string { return c.Tags }},
+	{"note", kindText, noteValues},
+	{"id", kindText, func(c *models.Contact) []string { return append(one(c.ID), c.Aliases...) }},
+	{"created", kindDate, func(c *models.Contact) []string { return one(dayOf(c.CreatedAt)) }},
+	{"updated", kindDate, func(c *models.Contact) []string { return one(dayOf(c.UpdatedAt)) }},
+	{"contacted", kindDate, contactedValues},
+}
+
+// fieldAliases are other accepted spellings of builtin field names
+var fieldAliases = map[string]string{
+	"firstname": "first", "lastname": "last", "emails": "email", "phones": "phone",
+	"organization": "org", "department": "dept", "tags": "tag", "notes": "note",
+}
+
+// phoneValues returns each phone number as written and as bare digits, so
+// "phone:5550102000" finds "(555) 010-2000"
+func phoneValues(c *models.Contact) []string {
+	var values []string
+	for _, phone := range append(one(c.Phone), c.OtherPhones...) {
+		values = append(values, phone)
+		if digits := match.NormalizePhone(phone); digits != "" {
+			values = append(values, digits)
+		}
+	}
+	return values
+}
+
+func noteValues(c *models.Contact) []string {
+	values := make([]string, len(c.Interactions))
+	for i, interaction := range c.Interactions {
+		values[i] = interaction.Summary
+	}
+	return values
+}
+
+func contactedValues(c *models.Contact) []string {
+	if at, ok := c.LastContacted(); ok {
+		return one(dayOf(at))
+	}
+	return nil
+}
+
+// fieldSet resolves field names for one schema
+type fieldSet struct {
+	byName map[string]*field
+	text   []*field // the text fields bare words are matched against
+}
+
+func newFieldSet(schema models.Schema) *fieldSet {
+	fs := &fieldSet{byName: make(map[string]*field)}
+	for _, f := range builtinFields {
+		fs.byName[f.name] = f
+		if f.kind == kindText && f.name != "id" {
+			fs.text = append(fs.text, f)
+		}
+	}
+	for _, def := range schema {
+		name := def.Name
+		f := &field{name: name, kind: kindText, values: func(c *models.Contact) []string { return one(c.Custom[name]) }}
+		switch def.Type {
+		case models.FieldNumber:
+			f.kind = kindNumber
+		case models.FieldDate:
+			f.kind = kindDate
+		default:
+			fs.text = append(fs.text, f)
+		}
+		if _, builtin := fs.byName[strings.ToLower(name)]; !builtin {
+			fs.byName[strings.ToLower(name)] = f
+		}
+	}
+	return fs
+}
+
+// lookup finds a field by name, ignoring case
+func (fs *fieldSet) lookup(name string) (*field, bool) {
+	name = strings.ToLower(name)
+	if canonical, ok := fieldAliases[name]; ok {
+		name = canonical
+	}
+	f, ok := fs.byName[name]
+	return f, ok
+}
+
+// suggest returns the known field name closest to a misspelled one, or ""
+func (fs *fieldSet) suggest(name string) string {
+	names := make([]string, 0, len(fs.byName)+len(fieldAliases))
+	for known := range fs.byName {
+		names = append(names, known)
+	}
+	for alias := range fieldAliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	name = strings.ToLower(name)
+	best, bestDistance := "", 3
+	for _, known := range names {
+		if d := match.Levenshtein(name, known); d < bestDistance && d <= max(1, len(name)/2) {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// anyText returns a field holding the values of every text field
+func (fs *fieldSet) anyText() *field {
+	return &field{kind: kindText, values: func(c *models.Contact) []string {
+		var values []string
+		for _, f := range fs.text {
+			values = append(values, f.values(c)...)
+		}
+		return values
+	}}
+}
+
+// Fields lists the builtin field names, for help text
+func Fields() []string {
+	names := make([]string, len(builtinFields))
+	for i, f := range builtinFields {
+		names[i] = f.name
+	}
+	return names
+}
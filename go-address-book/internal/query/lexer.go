@@ -0,0 +1,190 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString // double-quoted text
+	tokOp     // one of : = != > >= < <=
+	tokMinus
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokWord:
+		return "word"
+	case tokString:
+		return "quoted text"
+	case tokOp:
+		return "operator"
+	case tokMinus:
+		return "'-'"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	default:
+		return "NOT"
+	}
+}
+
+// token is a lexical token and the byte offset it starts at
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// describe names a token for error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokWord, tokOp:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return t.kind.String()
+	}
+}
+
+// lexer splits a query into tokens. Right after an operator it reads a
+// value, which may contain characters that otherwise end a word, so
+// "url:https://x" and "score>-5" lex as expected.
+type lexer struct {
+	input  string
+	pos    int
+	tokens []token
+}
+
+// lex tokenizes the whole input
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+// afterOp reports whether the previous token was an operator
+func (l *lexer) afterOp() bool {
+	return len(l.tokens) > 0 && l.tokens[len(l.tokens)-1].kind == tokOp
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	start := l.pos
+	if start == len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.input[start]
+	switch {
+	case c == '"':
+		return l.quoted()
+	case l.afterOp():
+		return l.word(func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ')' }), nil
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case c == '-':
+		l.pos++
+		return token{kind: tokMinus, text: "-", pos: start}, nil
+	case strings.ContainsRune(":=!<>", rune(c)):
+		return l.operator()
+	}
+
+	tok := l.word(isWordEnd)
+	switch tok.text {
+	case "AND":
+		tok.kind = tokAnd
+	case "OR":
+		tok.kind = tokOr
+	case "NOT":
+		tok.kind = tokNot
+	}
+	return tok, nil
+}
+
+// isWordEnd reports whether r ends a word outside of a value
+func isWordEnd(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("():=!<>\"", r)
+}
+
+func (l *lexer) word(end func(r rune) bool) token {
+	start := l.pos
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if end(r) {
+			break
+		}
+		l.pos += size
+	}
+	return token{kind: tokWord, text: l.input[start:l.pos], pos: start}
+}
+
+func (l *lexer) operator() (token, error) {
+	start := l.pos
+	for _, op := range []string{">=", "<=", "!=", ":", "=", ">", "<"} {
+		if strings.HasPrefix(l.input[start:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	return token{}, &Error{Input: l.input, Pos: start, Msg: `unexpected "!"; did you mean "!=" or "-" to exclude?`}
+}
+
+// quoted reads double-quoted text, where \" and \\ stand for " and \
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	var b strings.Builder
+	for i := start + 1; i < len(l.input); i++ {
+		switch c := l.input[i]; c {
+		case '\\':
+			if i+1 < len(l.input) {
+				i++
+				b.WriteByte(l.input[i])
+			}
+		case '"':
+			l.pos = i + 1
+			return token{kind: tokString, text: b.String(), pos: start}, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return token{}, &Error{Input: l.input, Pos: start, Msg: "unterminated quoted text"}
+}
@@ -0,0 +1,229 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Error is a query that failed to parse. Pos is the byte offset of the
+// token at fault.
+type Error struct {
+	Input string
+	Pos   int
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column(), e.Msg)
+}
+
+// Column returns the 1-based column of the token at fault, in characters
+func (e *Error) Column() int {
+	return utf8.RuneCountInString(e.Input[:e.Pos]) + 1
+}
+
+// Context returns the query with a caret under the token at fault
+func (e *Error) Context() string {
+	return e.Input + "\n" + strings.Repeat(" ", e.Column()-1) + "^"
+}
+
+// parser is a recursive-descent parser for the grammar
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { [ "AND" ] unary }
+//	unary   = ( "-" | "NOT" ) unary | primary
+//	primary = "(" or ")" | term
+//	term    = [ name op ] value
+//
+// where juxtaposed terms are joined with AND, which binds tighter than OR.
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+	fields *fieldSet
+}
+
+// Parse parses a query such as
+//
+//	last:smith email:*@gmail.com tag:vendor -city:dallas created>2024-01-01 OR phone:555*
+//
+// Fields are the builtin ones listed by Fields plus the custom fields in
+// schema. Values may use * and ? wildcards; without them ":" matches part of
+// a value and "=" the whole value, ignoring case. Dates and numbers can also
+// be compared with > >= < <= and !=. A word without a field matches any
+// text field.
+func Parse(input string, schema models.Schema) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, tokens: tokens, fields: newFieldSet(schema)}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "query is empty")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorf(tok, "unmatched ')'")
+		}
+		return nil, p.errorf(tok, "unexpected %s", tok.describe())
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) *Error {
+	return &Error{Input: p.input, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := []Expr{first}
+	for p.peek().kind == tokOr {
+		p.advance()
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &Or{Terms: terms}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := []Expr{first}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.advance()
+		case tokWord, tokString, tokMinus, tokNot, tokLParen:
+		default:
+			if len(terms) == 1 {
+				return first, nil
+			}
+			return &And{Terms: terms}, nil
+		}
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch tok := p.peek(); tok.kind {
+	case tokMinus, tokNot:
+		p.advance()
+		if next := p.peek(); tok.kind == tokMinus && next.pos != tok.pos+1 {
+			return nil, p.errorf(tok, "'-' must come right before the term it excludes, as in -city:dallas")
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokLParen:
+		p.advance()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(tok, "missing ')' to close this '('")
+		}
+		p.advance()
+		return expr, nil
+	case tokWord, tokString:
+		p.advance()
+		if p.peek().kind == tokOp {
+			return p.parseTerm(tok)
+		}
+		return &Term{Op: ":", Value: tok.text, Quoted: tok.kind == tokString, Pos: tok.pos, field: p.fields.anyText()}, nil
+	case tokEOF:
+		return nil, p.errorf(tok, "query ends where a search term was expected")
+	case tokOp:
+		return nil, p.errorf(tok, "%s needs a field name before it, as in last%ssmith", tok.describe(), tok.text)
+	default:
+		return nil, p.errorf(tok, "unexpected %s", tok.describe())
+	}
+}
+
+// parseTerm parses the operator and value following a field name
+func (p *parser) parseTerm(name token) (Expr, error) {
+	op := p.advance()
+	value := p.peek()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, p.errorf(value, "expected a value after %s", op.describe())
+	}
+	p.advance()
+
+	f, ok := p.fields.lookup(name.text)
+	if !ok {
+		if suggestion := p.fields.suggest(name.text); suggestion != "" {
+			return nil, p.errorf(name, "unknown field %q; did you mean %q?", name.text, suggestion)
+		}
+		return nil, p.errorf(name, "unknown field %q; fields are %s and custom fields",
+			name.text, strings.Join(Fields(), ", "))
+	}
+
+	term := &Term{Field: f.name, Op: op.text, Value: value.text, Quoted: value.kind == tokString, Pos: name.pos, field: f}
+	switch f.kind {
+	case kindNumber:
+		n, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, p.errorf(value, "%s is a number field, but %q is not a number", f.name, value.text)
+		}
+		term.number = n
+	case kindDate:
+		d, err := time.Parse(models.DateLayout, value.text)
+		if err != nil {
+			return nil, p.errorf(value, "%s is a date field, but %q is not a date (use YYYY-MM-DD)", f.name, value.text)
+		}
+		term.date = d.Format(models.DateLayout)
+	default:
+		switch op.text {
+		case ">", ">=", "<", "<=":
+			return nil, p.errorf(op, "%s only works on date and number fields, and %s is text", op.describe(), f.name)
+		}
+	}
+	return term, nil
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+var testSchema = models.Schema{
+	{Name: "Score", Type: models.FieldNumber},
+	{Name: "Account Manager", Type: models.FieldString},
+}
+
+func testContacts() []*models.Contact {
+	john := models.NewContact("John", "Smith", "john.smith@gmail.com", "(555) 010-2000", "1 Main St, Dallas, TX 75001")
+	john.Tags = []string{"vendor"}
+	john.CreatedAt = time.Date(2023, 6, 1, 12, 0, 0, 0, time.Local)
+	john.Custom = map[string]string{"Score": "7"}
+
+	jane := models.NewContact("Jane", "Smith", "jane@acme.com", "555-777-8888", "9 Elm Rd, Austin, TX 73301")
+	jane.Tags = []string{"vendor", "vip"}
+	jane.CreatedAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	jane.Custom = map[string]string{"Score": "12", "Account Manager": "Pat Lee"}
+
+	bob := models.NewContact("Bob", "Jones", "bob@gmail.com", "212-555-0100", "5 Oak Ave, Dallas, TX 75002")
+	bob.CreatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	bob.Interactions = []models.Interaction{{At: bob.CreatedAt, Kind: models.InteractionCall, Summary: "Renewal call"}}
+	return []*models.Contact{john, jane, bob}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // first names of the matching contacts
+	}{
+		{"last:smith", "John Jane"},
+		{"smith", "John Jane"},
+		{"email:*@gmail.com", "John Bob"},
+		{"tag:vendor -city:dallas", "Jane"},
+		{"last:smith email:*@gmail.com tag:vendor -city:dallas created>2024-01-01 OR phone:555*", "John Jane"},
+		{"created>2024-01-01 OR phone:555*", "John Jane Bob"},
+		{"created>=2024-03-01 created<2024-04-01", "Jane"},
+		{"created:2023-06-01", "John"},
+		{"phone:5550102000", "John"},
+		{"first=jo", ""},
+		{"first=JOHN", "John"},
+		{"first!=john last:smith", "Jane"},
+		{"NOT (tag:vendor OR tag:vip)", "Bob"},
+		{"tag:vendor AND NOT tag:vip", "John"},
+		{"score>10", "Jane"},
+		{"score<=7", "John"},
+		{`"account manager":pat`, "Jane"},
+		{"note:renewal", "Bob"},
+		{"contacted>2024-01-01", "Bob"},
+		{"company:acme", "Jane"},
+		{"city:dal?as", "John Bob"},
+		{`"Main St"`, "John"},
+		{"mit", ""},            // bare words match the start of a word
+		{"ja sm", "Jane"},      // in any field
+		{`"mit"`, "John Jane"}, // quoted text matches any part of a value
+		{`"smith john"`, ""},   // in order
+		{"j?hn", "John"},       // a wildcard matches a whole value
+		{"sm -jane", "John"},   // words mixed with operators still match word starts
+	}
+
+	contacts := testContacts()
+	for _, tt := range tests {
+		expr, err := Parse(tt.query, testSchema)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, c := range contacts {
+			if expr.Match(c) {
+				got = append(got, c.FirstName)
+			}
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q matched %v, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseStructure(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"a b OR c", "((a AND b) OR c)"},
+		{"a (b OR c)", "(a AND (b OR c))"},
+		{"-last:smith", "-last:smith"},
+		{"NOT NOT a", "--a"},
+		{"Last:Smith", "last:Smith"},
+		{"lastname=x", "last=x"},
+		{`first:"mary ann"`, `first:"mary ann"`},
+		{"url:https://x.com/a", "url:https://x.com/a"},
+		{"score>-5", "Score>-5"},
+	}
+	schema := append(models.Schema{{Name: "url", Type: models.FieldURL}}, testSchema...)
+	for _, tt := range tests {
+		expr, err := Parse(tt.query, schema)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		column int
+		msg    string
+	}{
+		{"", 1, "query is empty"},
+		{"cty:dallas", 1, `did you mean "city"?`},
+		{"bogus:1", 1, "unknown field"},
+		{"last:smith (tag:vip", 12, "missing ')'"},
+		{"last:smith)", 11, "unmatched ')'"},
+		{"last:", 6, "expected a value"},
+		{"created>yesterday", 9, "not a date"},
+		{"last>smith", 5, "only works on date and number fields"},
+		{"score:high", 7, "not a number"},
+		{"a OR", 5, "query ends"},
+		{"a - b", 3, "must come right before"},
+		{`first:"open`, 7, "unterminated"},
+		{"a ! b", 3, `did you mean "!="`},
+		{":x", 1, "needs a field name"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query, testSchema)
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Errorf("Parse(%q): expected a query error, got %v", tt.query, err)
+			continue
+		}
+		if qerr.Column() != tt.column || !strings.Contains(qerr.Msg, tt.msg) {
+			t.Errorf("Parse(%q) = %v, want column %d and %q", tt.query, err, tt.column, tt.msg)
+		}
+	}
+
+	_, err := Parse("last:smith (tag:vip", testSchema)
+	if want := "last:smith (tag:vip\n           ^"; err.(*Error).Context() != want {
+		t.Errorf("Expected caret under the '(', got\n%s", err.(*Error).Context())
+	}
+}
+
+func TestPlainWords(t *testing.T) {
+	expr, _ := Parse("john smith", nil)
+	if words, ok := PlainWords(expr); !ok || strings.Join(words, " ") != "john smith" {
+		t.Errorf("Expected plain words, got %v %v", words, ok)
+	}
+	for _, query := range []string{"last:smith", "a OR b", "-a", "a (b OR c)", "j?hnny", "smi*", `"smith john"`, `"john"`} {
+		expr, _ := Parse(query, nil)
+		if _, ok := PlainWords(expr); ok {
+			t.Errorf("Expected %q not to be plain words", query)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"*@gmail.com", "a@gmail.com", true},
+		{"*@gmail.com", "a@gmail.co", false},
+		{"555*", "555-1234", true},
+		{"a?c", "abc", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"*", "", true},
+	}
+	for _, tt := range tests {
+		if got := glob(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("glob(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ab := models.NewAddressBook()
+	if err := ab.SetSchema(testSchema); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range testContacts() {
+		if err := ab.AddContact(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"smith", "Jane John"},                        // index, ties by name
+		{"tag:vendor OR last:jones", "Bob Jane John"}, // evaluated, by last then first name
+		{"~jnoes", "Bob"},                             // fuzzy
+		{"j?hn", "John"},                              // wildcard, evaluated
+		{`"smith john"`, ""},                          // phrase, evaluated
+		{"*mit*", "Jane John"},                        // inside a word, through a wildcard
+		{`"john smith"`, "John"},                      // phrase across first and last name
+	}
+	for _, tt := range tests {
+		contacts, err := Search(ab, tt.text)
+		if err != nil {
+			t.Errorf("Search(%q) failed: %v", tt.text, err)
+			continue
+		}
+		var got []string
+		for _, c := range contacts {
+			got = append(got, c.FirstName)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("Search(%q) = %v, want %s", tt.text, got, tt.want)
+		}
+	}
+	if _, err := Search(ab, "last:"); err == nil {
+		t.Error("Expected an invalid query to fail")
+	}
+}
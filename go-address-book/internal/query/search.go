@@ -0,0 +1,34 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Search finds contacts for text typed into a search box. Plain words use
+// the ranked full-text index, fuzzily when the text starts with "~", while
+// queries with fields or operators are evaluated contact by contact and
+// returned in name order.
+func Search(ab *models.AddressBook, text string) ([]*models.Contact, error) {
+	if words, fuzzy := strings.CutPrefix(strings.TrimSpace(text), "~"); fuzzy {
+		var contacts []*models.Contact
+		for _, result := range ab.FuzzySearch(words, 0) {
+			contacts = append(contacts, result.Contact)
+		}
+		return contacts, nil
+	}
+
+	expr, err := Parse(text, ab.Schema())
+	if err != nil {
+		return nil, err
+	}
+	if words, ok := PlainWords(expr); ok {
+		return ab.SearchContacts(strings.Join(words, " ")), nil
+	}
+	contacts := ab.Find(expr.Match)
+	if err := models.SortContacts(contacts, models.DefaultSort, "", nil); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
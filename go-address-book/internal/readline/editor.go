@@ -0,0 +1,245 @@
+package readline
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+)
+
+// maxListed is how many completions are listed when Tab is ambiguous
+const maxListed = 30
+
+// editor is the state of the line being edited, kept apart from the
+// terminal so it can be driven by tests
+type editor struct {
+	r      *Reader
+	out    io.Writer
+	prompt string // the prompt's last line, redrawn with the text
+	width  int
+
+	buf []rune
+	pos int // cursor position in buf
+
+	hist  int    // index into the history while browsing it
+	saved []rune // the line being typed before browsing the history
+}
+
+func newEditor(r *Reader, out io.Writer, prompt string, width int) *editor {
+	if i := strings.LastIndex(prompt, "\n"); i >= 0 {
+		prompt = prompt[i+1:]
+	}
+	return &editor{r: r, out: out, prompt: prompt, width: width, hist: len(r.history)}
+}
+
+// handle applies one key press, reporting when the line is finished.
+// Ctrl-D on an empty line returns io.EOF.
+func (e *editor) handle(k keys.Key) (bool, error) {
+	switch k.Name {
+	case keys.Enter:
+		io.WriteString(e.out, "\r\n")
+		return true, nil
+	case keys.CtrlC:
+		e.buf, e.pos = nil, 0
+		io.WriteString(e.out, "^C\r\n")
+		return true, nil
+	case keys.CtrlD:
+		if len(e.buf) == 0 {
+			io.WriteString(e.out, "\r\n")
+			return true, io.EOF
+		}
+		e.deleteRange(e.pos, e.pos+1)
+	case keys.Left:
+		e.pos = max(0, e.pos-1)
+	case keys.Right:
+		e.pos = min(len(e.buf), e.pos+1)
+	case keys.Home, keys.CtrlA:
+		e.pos = 0
+	case keys.End, keys.CtrlE:
+		e.pos = len(e.buf)
+	case keys.Backspace:
+		e.deleteRange(e.pos-1, e.pos)
+	case keys.Delete:
+		e.deleteRange(e.pos, e.pos+1)
+	case keys.CtrlK:
+		e.deleteRange(e.pos, len(e.buf))
+	case keys.CtrlU:
+		e.deleteRange(0, e.pos)
+	case keys.CtrlW:
+		start := e.pos
+		for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		e.deleteRange(start, e.pos)
+	case keys.Up:
+		e.browse(-1)
+	case keys.Down:
+		e.browse(1)
+	case keys.Tab:
+		e.complete()
+	case keys.CtrlL:
+		io.WriteString(e.out, "\x1b[H\x1b[2J")
+	case "":
+		e.insert(k.Rune)
+	default:
+		return false, nil
+	}
+	e.refresh()
+	return false, nil
+}
+
+func (e *editor) insert(runes ...rune) {
+	e.buf = slices.Insert(e.buf, e.pos, runes...)
+	e.pos += len(runes)
+}
+
+// deleteRange removes buf[from:to], clamped to the line
+func (e *editor) deleteRange(from, to int) {
+	from, to = max(0, from), min(len(e.buf), to)
+	if from >= to {
+		return
+	}
+	e.buf = slices.Delete(e.buf, from, to)
+	if e.pos > to {
+		e.pos -= to - from
+	} else if e.pos > from {
+		e.pos = from
+	}
+}
+
+// browse moves through the history, keeping the unfinished line to come
+// back to
+func (e *editor) browse(delta int) {
+	history := e.r.history
+	next := e.hist + delta
+	if next < 0 || next > len(history) {
+		return
+	}
+	if e.hist == len(history) {
+		e.saved = slices.Clone(e.buf)
+	}
+	e.hist = next
+	if next == len(history) {
+		e.buf = e.saved
+	} else {
+		e.buf = []rune(history[next])
+	}
+	e.pos = len(e.buf)
+}
+
+// complete completes the text before the cursor. It first tries the whole
+// text, so "John Sm" can complete to a full name, then the text after each
+// space or comma in turn, so "vendor, v" can complete a tag. A unique
+// match is filled in; otherwise the common prefix is, or the matches are
+// listed.
+func (e *editor) complete() {
+	if e.r.Complete == nil {
+		return
+	}
+	words := e.r.Complete()
+	before := e.buf[:e.pos]
+	for _, start := range segmentStarts(before) {
+		prefix := string(before[start:])
+		matches := matching(words, prefix)
+		if len(matches) == 0 {
+			continue
+		}
+		common := []rune(commonPrefix(matches))
+		if len(common) > e.pos-start {
+			e.deleteRange(start, e.pos)
+			e.insert(common...)
+			return
+		}
+		if len(matches) > 1 {
+			e.list(matches)
+		}
+		return
+	}
+	io.WriteString(e.out, "\a")
+}
+
+// segmentStarts returns where completion may start in text: its start and
+// after each space or comma, longest segment first
+func segmentStarts(text []rune) []int {
+	var starts []int
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != ' ' && text[i] != ',' {
+			continue
+		}
+		for start < len(text) && text[start] == ' ' {
+			start++
+		}
+		if start < len(text) && (len(starts) == 0 || starts[len(starts)-1] != start) {
+			starts = append(starts, start)
+		}
+		start = i + 1
+	}
+	return starts
+}
+
+// matching returns the distinct words starting with prefix, ignoring case
+func matching(words []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, word := range words {
+		if strings.HasPrefix(strings.ToLower(word), prefix) && !slices.Contains(matches, word) {
+			matches = append(matches, word)
+		}
+	}
+	slices.Sort(matches)
+	return matches
+}
+
+// commonPrefix returns the longest prefix of the first word shared by all
+// the others, ignoring case
+func commonPrefix(words []string) string {
+	prefix := []rune(words[0])
+	for _, word := range words[1:] {
+		r := []rune(word)
+		n := 0
+		for n < len(prefix) && n < len(r) && unicode.ToLower(prefix[n]) == unicode.ToLower(r[n]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+// list shows the matches below the line, then redraws the prompt
+func (e *editor) list(matches []string) {
+	io.WriteString(e.out, "\r\n")
+	for i, match := range matches {
+		if i == maxListed {
+			fmt.Fprintf(e.out, "... and %d more", len(matches)-maxListed)
+			break
+		}
+		if i > 0 {
+			io.WriteString(e.out, "  ")
+		}
+		io.WriteString(e.out, match)
+	}
+	io.WriteString(e.out, "\r\n")
+}
+
+// refresh redraws the prompt and the line. A line too long for the
+// terminal scrolls sideways to keep the cursor in view.
+func (e *editor) refresh() {
+	promptWidth := len([]rune(e.prompt))
+	room := e.width - promptWidth - 1
+	start, end := 0, len(e.buf)
+	if room > 0 {
+		start = max(0, e.pos-room)
+		end = min(len(e.buf), start+room)
+	}
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", e.prompt, string(e.buf[start:end]))
+	if column := promptWidth + e.pos - start; column > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", column)
+	}
+}
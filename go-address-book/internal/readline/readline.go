@@ -0,0 +1,189 @@
+// Package readline reads lines for the interactive menu, with cursor
+// movement and editing keys, a persistent history and Tab completion. When
+// stdin or stdout is not a terminal, as with piped input or Docker without
+// -t, it reads plain lines instead.
+package readline
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+)
+
+// maxHistory is how many lines of history are kept
+const maxHistory = 1000
+
+// Completer returns the words Tab can complete to, such as contact names
+// and IDs. Only those starting with the text being completed are offered.
+type Completer func() []string
+
+// Reader reads lines from the terminal. It is used like a bufio.Scanner,
+// with Prompt in place of Scan.
+type Reader struct {
+	in  *os.File
+	out *os.File
+	tty bool
+
+	lines   *bufio.Scanner // reads stdin when it is not a terminal
+	pending []keys.Key     // keys read after the end of the last line
+
+	text string
+	eof  bool
+	err  error
+
+	history     []string
+	historyFile string
+
+	// Complete supplies the words for Tab completion; nil turns it off
+	Complete Completer
+}
+
+// New returns a reader for in, echoing to out. Line editing is used only
+// when both are terminals.
+func New(in, out *os.File) *Reader {
+	r := &Reader{in: in, out: out}
+	r.tty = term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd())) && os.Getenv("TERM") != "dumb"
+	if !r.tty {
+		r.lines = bufio.NewScanner(in)
+	}
+	return r
+}
+
+// Interactive reports whether lines are edited on a terminal
+func (r *Reader) Interactive() bool {
+	return r.tty
+}
+
+// LoadHistory reads the history saved in path, which need not exist yet,
+// and appends each new line to it
+func (r *Reader) LoadHistory(path string) error {
+	r.historyFile = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			r.history = append(r.history, line)
+		}
+	}
+	if len(r.history) > maxHistory {
+		r.history = r.history[len(r.history)-maxHistory:]
+		// Rewrite the file so it doesn't grow without bound
+		return os.WriteFile(path, []byte(strings.Join(r.history, "\n")+"\n"), 0o600)
+	}
+	return nil
+}
+
+// addHistory records a line, skipping blanks and repeats
+func (r *Reader) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(r.history) > 0 && r.history[len(r.history)-1] == line) {
+		return
+	}
+	r.history = append(r.history, line)
+	if len(r.history) > maxHistory {
+		r.history = r.history[1:]
+	}
+	if r.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(r.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return // history is a convenience; never fail the prompt over it
+	}
+	defer f.Close()
+	f.WriteString(line + "\n")
+}
+
+// Prompt shows prompt and reads a line, reporting false at the end of the
+// input. Ctrl-D on an empty line ends the input and Ctrl-C abandons the
+// line, leaving it empty.
+func (r *Reader) Prompt(prompt string) bool {
+	r.text = ""
+	if r.eof || r.err != nil {
+		return false
+	}
+	if !r.tty {
+		io.WriteString(r.out, prompt)
+		if !r.lines.Scan() {
+			r.eof, r.err = true, r.lines.Err()
+			return false
+		}
+		r.text = r.lines.Text()
+		return true
+	}
+
+	line, err := r.readLine(prompt)
+	if err == io.EOF {
+		r.eof = true
+		return false
+	}
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.text = line
+	r.addHistory(line)
+	return true
+}
+
+// Text returns the line read by the last call to Prompt
+func (r *Reader) Text() string {
+	return r.text
+}
+
+// Err returns the first error reading input, other than the end of it
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// readLine edits a line on the terminal in raw mode
+func (r *Reader) readLine(prompt string) (string, error) {
+	// Write the prompt before raw mode turns off newline translation
+	io.WriteString(r.out, prompt)
+	fd := int(r.in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, state)
+
+	w := bufio.NewWriter(r.out)
+	defer w.Flush()
+	e := newEditor(r, w, prompt, r.width())
+	buf := make([]byte, 256)
+	for {
+		for len(r.pending) > 0 {
+			k := r.pending[0]
+			r.pending = r.pending[1:]
+			if done, err := e.handle(k); done || err != nil {
+				return string(e.buf), err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+		n, err := r.in.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		r.pending = keys.Parse(buf[:n])
+	}
+}
+
+// width returns the terminal's width in columns
+func (r *Reader) width() int {
+	if width, _, err := term.GetSize(int(r.out.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
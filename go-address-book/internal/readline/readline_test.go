@@ -0,0 +1,163 @@
+package readline
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+)
+
+// typeLine feeds raw terminal input to an editor, returning the line and
+// whether it was finished
+func typeLine(r *Reader, input string) (string, bool, error) {
+	e := newEditor(r, io.Discard, "> ", 80)
+	for _, k := range keys.Parse([]byte(input)) {
+		if done, err := e.handle(k); done || err != nil {
+			return string(e.buf), done, err
+		}
+	}
+	return string(e.buf), false, nil
+}
+
+func TestEditing(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"typing", "héllo\r", "héllo"},
+		{"insert in the middle", "helo\x1b[D\x1b[Dl\r", "hello"},
+		{"home and end", "ello\x01h\x05!\r", "hello!"},
+		{"backspace and delete", "hxello\x1b[D\x1b[D\x1b[D\x1b[D\x7f\x1b[3~\r", "hllo"},
+		{"kill to end", "hello world\x01\x1b[C\x1b[C\x0b\r", "he"},
+		{"kill to start", "hello world\x1b[D\x15\r", "d"},
+		{"delete word", "John Smith  \x17\r", "John "},
+		{"ctrl-c abandons the line", "oops\x03", ""},
+	}
+	for _, tt := range tests {
+		got, done, err := typeLine(&Reader{}, tt.input)
+		if got != tt.want || !done || err != nil {
+			t.Errorf("%s: got %q (done %v, %v), want %q", tt.name, got, done, err, tt.want)
+		}
+	}
+
+	if _, _, err := typeLine(&Reader{}, "\x04"); err != io.EOF {
+		t.Errorf("Expected Ctrl-D on an empty line to end input, got %v", err)
+	}
+	if got, _, err := typeLine(&Reader{}, "ab\x1b[D\x04\r"); got != "a" || err != nil {
+		t.Errorf("Expected Ctrl-D to delete under the cursor, got %q, %v", got, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	r := &Reader{}
+	if err := r.LoadHistory(path); err != nil {
+		t.Fatalf("Expected a missing history file to be fine, got %v", err)
+	}
+	for _, line := range []string{"2", "search", "search", " ", "3"} {
+		r.addHistory(line)
+	}
+
+	tests := []struct {
+		input, want string
+	}{
+		{"\x1b[A\r", "3"},
+		{"\x1b[A\x1b[A\r", "search"},
+		{"\x1b[A\x1b[A\x1b[A\x1b[A\x1b[A\r", "2"},    // stops at the oldest line
+		{"draft\x1b[A\x1b[A\x1b[B\x1b[B\r", "draft"}, // comes back to the unfinished line
+		{"\x1b[A\x1b[A\x1b[A!\r", "2!"},              // a history line can be edited
+	}
+	for _, tt := range tests {
+		if got, _, _ := typeLine(r, tt.input); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// The history survives in the file, without blanks or repeats
+	loaded := &Reader{}
+	if err := loaded.LoadHistory(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(loaded.history, ","); got != "2,search,3" {
+		t.Errorf("Expected the saved history, got %s", got)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	r := &Reader{Complete: func() []string {
+		return []string{"John Smith", "Johnny Appleseed", "Jane Doe", "vendor", "vip", "01HX7Z3K9M"}
+	}}
+	tests := []struct {
+		name, input, want string
+	}{
+		{"unique", "jane\t\r", "Jane Doe"},
+		{"common prefix", "jo\t\r", "John"},
+		{"after a space", "John Sm\t\r", "John Smith"},
+		{"after a comma", "vip, ve\t\r", "vip, vendor"},
+		{"ID", "01h\t\r", "01HX7Z3K9M"},
+		{"cursor in the middle", "vexxx\x01\x1b[C\x1b[C\t\r", "vendorxxx"},
+		{"no match", "zz\t\r", "zz"},
+	}
+	for _, tt := range tests {
+		if got, _, _ := typeLine(r, tt.input); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Ambiguous matches are listed
+	var out bytes.Buffer
+	e := newEditor(r, &out, "Search: ", 80)
+	for _, k := range keys.Parse([]byte("v\t")) {
+		e.handle(k)
+	}
+	if !strings.Contains(out.String(), "\r\nvendor  vip\r\n") {
+		t.Errorf("Expected the matches listed, got %q", out.String())
+	}
+}
+
+func TestRefreshScrolls(t *testing.T) {
+	var out bytes.Buffer
+	e := newEditor(&Reader{}, &out, "a\nlong> ", 20)
+	for _, k := range keys.Parse([]byte(strings.Repeat("x", 30) + "y")) {
+		e.handle(k)
+	}
+	last := out.String()[strings.LastIndex(out.String(), "\r"+"long> "):]
+	if !strings.Contains(last, "long> "+strings.Repeat("x", 12)+"y\x1b[K") {
+		t.Errorf("Expected the line to scroll to the cursor, got %q", last)
+	}
+}
+
+func TestPlainInput(t *testing.T) {
+	in, err := os.CreateTemp(t.TempDir(), "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.WriteString("1\nJohn\n")
+	in.Seek(0, io.SeekStart)
+	out, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(in, out)
+	if r.Interactive() {
+		t.Fatal("Expected files not to be edited as a terminal")
+	}
+	var got []string
+	for r.Prompt("? ") {
+		got = append(got, r.Text())
+	}
+	if strings.Join(got, ",") != "1,John" || r.Err() != nil {
+		t.Errorf("Expected the plain lines, got %v (%v)", got, r.Err())
+	}
+	if r.Prompt("? ") {
+		t.Error("Expected the input to stay finished")
+	}
+	written, _ := os.ReadFile(out.Name())
+	if string(written) != "? ? ? " {
+		t.Errorf("Expected each prompt written, got %q", written)
+	}
+}
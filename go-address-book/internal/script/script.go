@@ -0,0 +1,106 @@
+// Package script reads batch files of address-book commands, one per line,
+// written as they would be typed in a shell:
+//
+//	# seed data for the test environment
+//	add --first Ada --last Lovelace --tags "math, vip"
+//	update 01HX7Z --email ''
+//
+// Words are split on spaces; single and double quotes group words, and a
+// backslash escapes the next character outside single quotes. A # starting
+// a word comments out the rest of the line. A leading "address-book" is
+// ignored, so lines can be pasted from a shell.
+package script
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Line is one command of a script
+type Line struct {
+	Number int      // 1-based line number in the file
+	Args   []string // the command name and its arguments
+}
+
+// Error is a line that could not be split into words
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse reads the commands of a script, skipping blank lines and comments
+func Parse(r io.Reader) ([]Line, error) {
+	var lines []Line
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		words, err := Split(scanner.Text())
+		if err != nil {
+			return nil, &Error{Line: n, Msg: err.Error()}
+		}
+		if len(words) > 0 && words[0] == "address-book" {
+			words = words[1:]
+		}
+		if len(words) > 0 {
+			lines = append(lines, Line{Number: n, Args: words})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// Split splits a line into words the way a shell would
+func Split(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false // distinguishes an empty quoted word from no word
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '#' && !inWord:
+			return words, nil
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("backslash at end of line")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '\'' || r == '"':
+			end := i + 1
+			for ; end < len(runes) && runes[end] != r; end++ {
+				if r == '"' && runes[end] == '\\' && end+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[end+1]) {
+					end++
+				}
+				word.WriteRune(runes[end])
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated %c quote", r)
+			}
+			i = end
+			inWord = true
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
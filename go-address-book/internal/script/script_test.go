@@ -0,0 +1,59 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		err  bool
+	}{
+		{"add --first Ada --last Lovelace", []string{"add", "--first", "Ada", "--last", "Lovelace"}, false},
+		{`add --tags "math, vip"  --notes 'say "hi"'`, []string{"add", "--tags", "math, vip", "--notes", `say "hi"`}, false},
+		{`update 01HX --email ''`, []string{"update", "01HX", "--email", ""}, false},
+		{`add --first Jean\ Luc --last "O\"Brien"`, []string{"add", "--first", "Jean Luc", "--last", `O"Brien`}, false},
+		{`add --first=A"d"a`, []string{"add", "--first=Ada"}, false},
+		{"list # everything", []string{"list"}, false},
+		{"search tag#1", []string{"search", "tag#1"}, false},
+		{"   ", nil, false},
+		{`add --first "Ada`, nil, true},
+		{`add \`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.line)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Split(%q): expected an error", tt.line)
+			}
+			continue
+		}
+		if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := "# seed data\n\nadd --first Ada\naddress-book delete 01HX\n  # indented comment\nlist\n"
+	lines, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range lines {
+		got = append(got, strings.Join(line.Args, " ")+"@"+string(rune('0'+line.Number)))
+	}
+	if want := "add --first Ada@3,delete 01HX@4,list@6"; strings.Join(got, ",") != want {
+		t.Errorf("Parse = %v, want %s", got, want)
+	}
+
+	_, err = Parse(strings.NewReader("list\nadd --first 'Ada\n"))
+	var scriptErr *Error
+	if !errors.As(err, &scriptErr) || scriptErr.Line != 2 {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// column maps a CSV header to a core contact field
+type column struct {
+	name string
+	get  func(c *models.Contact) (string, error)
+	set  func(c *models.Contact, value string) error
+}
+
+// columns lists the core contact fields in the order they are written.
+// Any other header in the file is treated as a custom field.
+var columns = []column{
+	stringColumn("ID", func(c *models.Contact) *string { return &c.ID }),
+	stringColumn("FirstName", func(c *models.Contact) *string { return &c.FirstName }),
+	stringColumn("LastName", func(c *models.Contact) *string { return &c.LastName }),
+	stringColumn("Email", func(c *models.Contact) *string { return &c.Email }),
+	stringColumn("Phone", func(c *models.Contact) *string { return &c.Phone }),
+	stringColumn("Address", func(c *models.Contact) *string { return &c.Address }),
+	stringColumn("Organization", func(c *models.Contact) *string { return &c.Organization }),
+	stringColumn("Title", func(c *models.Contact) *string { return &c.Title }),
+	stringColumn("Department", func(c *models.Contact) *string { return &c.Department }),
+	listColumn("Tags", func(c *models.Contact) *[]string { return &c.Tags }),
+	listColumn("OtherEmails", func(c *models.Contact) *[]string { return &c.OtherEmails }),
+	listColumn("OtherPhones", func(c *models.Contact) *[]string { return &c.OtherPhones }),
+	listColumn("OtherAddresses", func(c *models.Contact) *[]string { return &c.OtherAddresses }),
+	listColumn("Aliases", func(c *models.Contact) *[]string { return &c.Aliases }),
+	{
+		name: "Dates",
+		get:  func(c *models.Contact) (string, error) { return models.FormatDates(c.Dates), nil },
+		set: func(c *models.Contact, v string) error {
+			dates, err := models.ParseDates(v)
+			if err != nil {
+				return fmt.Errorf("failed to parse Dates: %w", err)
+			}
+			c.Dates = dates
+			return nil
+		},
+	},
+	{
+		name: "Interactions",
+		get:  func(c *models.Contact) (string, error) { return models.FormatInteractions(c.Interactions) },
+		set: func(c *models.Contact, v string) error {
+			interactions, err := models.ParseInteractions(v)
+			if err != nil {
+				return fmt.Errorf("failed to parse Interactions: %w", err)
+			}
+			c.Interactions = interactions
+			return nil
+		},
+	},
+	{
+		name: "Version",
+		get:  func(c *models.Contact) (string, error) { return strconv.Itoa(c.Version), nil },
+		set: func(c *models.Contact, v string) error {
+			if v == "" {
+				return nil
+			}
+			version, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("failed to parse Version: %w", err)
+			}
+			c.Version = version
+			return nil
+		},
+	},
+	timeColumn("CreatedAt", func(c *models.Contact) *time.Time { return &c.CreatedAt }),
+	timeColumn("UpdatedAt", func(c *models.Contact) *time.Time { return &c.UpdatedAt }),
+}
+
+// stringColumn maps a header to a plain string field
+func stringColumn(name string, field func(c *models.Contact) *string) column {
+	return column{
+		name: name,
+		get:  func(c *models.Contact) (string, error) { return *field(c), nil },
+		set:  func(c *models.Contact, v string) error { *field(c) = v; return nil },
+	}
+}
+
+// listColumn maps a header to a string slice, stored as a JSON array so
+// items may contain any character. Files from older versions separate
+// items with ';', which is still read.
+func listColumn(name string, field func(c *models.Contact) *[]string) column {
+	return column{
+		name: name,
+		get: func(c *models.Contact) (string, error) {
+			if len(*field(c)) == 0 {
+				return "", nil
+			}
+			bytes, err := json.Marshal(*field(c))
+			return string(bytes), err
+		},
+		set: func(c *models.Contact, v string) error {
+			*field(c) = nil
+			var items []string
+			if !strings.HasPrefix(v, "[") || json.Unmarshal([]byte(v), &items) != nil {
+				items = strings.Split(v, ";")
+			}
+			for _, item := range items {
+				if item = strings.TrimSpace(item); item != "" {
+					*field(c) = append(*field(c), item)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// timeColumn maps a header to an RFC3339 timestamp field
+func timeColumn(name string, field func(c *models.Contact) *time.Time) column {
+	return column{
+		name: name,
+		get:  func(c *models.Contact) (string, error) { return field(c).Format(time.RFC3339), nil },
+		set: func(c *models.Contact, v string) error {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			*field(c) = t
+			return nil
+		},
+	}
+}
+
+// lookupColumn returns the core column with the given header name
+func lookupColumn(name string) (column, bool) {
+	for _, col := range columns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
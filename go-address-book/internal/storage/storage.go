@@ -4,10 +4,11 @@ import (
 	"bufio"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
-	"time"
 
 	"github.com/rushi/address-book-cli/internal/models" //local path of my macos machine
 )
@@ -20,6 +21,9 @@ type Storage interface {
 type CSVStorage struct {
 	filepath string
 	cache    *models.AddressBook
+	schema   models.Schema
+	unknown  []string            // custom columns of the last Load the schema doesn't define
+	kept     map[string][]string // contact ID -> its values in the unknown columns
 	mu       sync.RWMutex
 }
 
@@ -30,30 +34,120 @@ func NewCSVStorage(filepath string) *CSVStorage {
 	}
 }
 
+// SetSchema sets the custom fields applied to address books loaded from disk
+func (s *CSVStorage) SetSchema(schema models.Schema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.schema = schema
+}
+
+// MissingIDError is returned by Load for a CSV file without an ID column,
+// such as one exported by another address book
+type MissingIDError struct {
+	Header []string
+}
+
+func (e *MissingIDError) Error() string {
+	return "CSV header is missing the ID column"
+}
+
+// relationshipsColumn holds each contact's outgoing links. It is restored
+// after all rows are read, since links may point at later rows.
+const relationshipsColumn = "Relationships"
+
+// UnknownColumns returns the columns of the last loaded file that are
+// neither contact fields nor custom fields in the schema, such as a field
+// removed from the config. Their values are not loaded into contacts, but
+// are kept aside and written back by Save, so no data is lost.
+func (s *CSVStorage) UnknownColumns() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.unknown)
+}
+
+// Save writes the address book to a temporary file next to the CSV file and
+// renames it into place once it is complete and synced to disk, so a failed
+// or interrupted save leaves the previous file intact.
 func (s *CSVStorage) Save(addressBook *models.AddressBook) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if err := os.MkdirAll(filepath.Dir(s.filepath), 0755); err != nil {
+	dir := filepath.Dir(s.filepath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	file, err := os.Create(s.filepath)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(s.filepath)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create CSV file: %w", err)
 	}
+	defer os.Remove(file.Name()) // fails harmlessly once renamed
 	defer file.Close()
 
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(s.filepath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := file.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to set CSV file mode: %w", err)
+	}
+
 	buffered := bufio.NewWriter(file)
-	writer := csv.NewWriter(buffered)
-	defer writer.Flush()
-	defer buffered.Flush()
+	if err := writeCSV(buffered, addressBook, s.unknown, s.kept); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync CSV file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close CSV file: %w", err)
+	}
+	if err := os.Rename(file.Name(), s.filepath); err != nil {
+		return fmt.Errorf("failed to replace CSV file: %w", err)
+	}
+	syncDir(dir)
 
-	if err := writer.Write([]string{"ID", "FirstName", "LastName", "Email", "Phone", "Address", "CreatedAt", "UpdatedAt"}); err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
+	s.cache = addressBook
+	return nil
+}
+
+// syncDir flushes a directory entry change such as a rename to disk, where
+// the platform supports it
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
 	}
+}
+
+// writeCSV writes the contacts of an address book, their links and their
+// custom fields as CSV, followed by the unknown columns with the values kept
+// for each contact
+func writeCSV(w io.Writer, addressBook *models.AddressBook, unknown []string, kept map[string][]string) error {
+	writer := csv.NewWriter(w)
 
 	contacts := addressBook.GetAllContacts()
+	var custom []string
+	for _, field := range addressBook.Schema() {
+		custom = append(custom, field.Name)
+	}
+
+	header := make([]string, 0, len(columns)+len(custom))
+	for _, col := range columns {
+		header = append(header, col.name)
+	}
+	header = append(header, relationshipsColumn)
+	header = append(header, custom...)
+	header = append(header, unknown...)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
 	batchSize := 100
 	for i := 0; i < len(contacts); i += batchSize {
 		end := i + batchSize
@@ -62,15 +156,20 @@ func (s *CSVStorage) Save(addressBook *models.AddressBook) error {
 		}
 
 		for _, contact := range contacts[i:end] {
-			record := []string{
-				contact.ID,
-				contact.FirstName,
-				contact.LastName,
-				contact.Email,
-				contact.Phone,
-				contact.Address,
-				contact.CreatedAt.Format(time.RFC3339),
-				contact.UpdatedAt.Format(time.RFC3339),
+			record := make([]string, 0, len(header))
+			for _, col := range columns {
+				value, err := col.get(contact)
+				if err != nil {
+					return fmt.Errorf("failed to encode %s of contact %s: %w", col.name, contact.ID, err)
+				}
+				record = append(record, value)
+			}
+			record = append(record, models.FormatLinks(addressBook.Links(contact.ID)))
+			for _, name := range custom {
+				record = append(record, contact.Custom[name])
+			}
+			if len(unknown) > 0 {
+				record = append(record, keptValues(contact, unknown, kept)...)
 			}
 			if err := writer.Write(record); err != nil {
 				return fmt.Errorf("failed to write contact to CSV: %w", err)
@@ -81,9 +180,8 @@ func (s *CSVStorage) Save(addressBook *models.AddressBook) error {
 			return fmt.Errorf("failed to flush CSV writer: %w", err)
 		}
 	}
-
-	s.cache = addressBook
-	return nil
+	writer.Flush()
+	return writer.Error()
 }
 
 func (s *CSVStorage) Load() (*models.AddressBook, error) {
@@ -101,6 +199,9 @@ func (s *CSVStorage) Load() (*models.AddressBook, error) {
 	if err != nil {
 		if os.IsNotExist(err) {
 			s.cache = models.NewAddressBook()
+			if err := s.cache.SetSchema(s.schema); err != nil {
+				return nil, err
+			}
 			return s.cache, nil
 		}
 		return nil, fmt.Errorf("failed to open CSV file: %w", err)
@@ -110,66 +211,134 @@ func (s *CSVStorage) Load() (*models.AddressBook, error) {
 	buffered := bufio.NewReader(file)
 	reader := csv.NewReader(buffered)
 
-	_, err = reader.Read()
+	header, err := reader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read CSV header: %w", err)
 	}
+	if indexOf(header, "ID") < 0 {
+		return nil, &MissingIDError{Header: header}
+	}
 
 	addressBook := models.NewAddressBook()
+	if err := addressBook.SetSchema(s.schema); err != nil {
+		return nil, err
+	}
+	s.unknown = nil
+	s.kept = make(map[string][]string)
+	for _, name := range header {
+		if _, core := lookupColumn(name); !core && name != relationshipsColumn {
+			if _, ok := s.schema.Lookup(name); !ok {
+				s.unknown = append(s.unknown, name)
+			}
+		}
+	}
 	batchSize := 100
 	records := make([][]string, 0, batchSize)
+	var links []models.Link
 
 	for {
 		record, err := reader.Read()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV record: %w", err)
+		}
 		records = append(records, record)
 
 		if len(records) >= batchSize {
-			if err := processBatch(addressBook, records); err != nil {
+			batchLinks, err := processBatch(addressBook, header, records, s.unknown, s.kept)
+			if err != nil {
 				return nil, err
 			}
+			links = append(links, batchLinks...)
 			records = records[:0]
 		}
 	}
 
 	if len(records) > 0 {
-		if err := processBatch(addressBook, records); err != nil {
+		batchLinks, err := processBatch(addressBook, header, records, s.unknown, s.kept)
+		if err != nil {
 			return nil, err
 		}
+		links = append(links, batchLinks...)
+	}
+
+	for _, link := range links {
+		if err := addressBook.AddLink(link.From, link.Type, link.To); err != nil {
+			return nil, fmt.Errorf("failed to restore %s link of %s: %w", link.Type, link.From, err)
+		}
 	}
 
 	s.cache = addressBook
 	return addressBook, nil
 }
 
-func processBatch(addressBook *models.AddressBook, records [][]string) error {
+// processBatch adds the contacts in records and returns their links. The
+// values of unknown columns are not loaded but stored in kept.
+func processBatch(addressBook *models.AddressBook, header []string, records [][]string, unknown []string, kept map[string][]string) ([]models.Link, error) {
+	var links []models.Link
 	for _, record := range records {
-		createdAt, err := time.Parse(time.RFC3339, record[6])
-		if err != nil {
-			return fmt.Errorf("failed to parse CreatedAt: %w", err)
+		contact := &models.Contact{}
+		relationships := ""
+		var extra []string
+		for i, name := range header {
+			value := record[i]
+			if name == relationshipsColumn {
+				relationships = value
+				continue
+			}
+			if j := slices.Index(unknown, name); j >= 0 {
+				if value != "" {
+					if extra == nil {
+						extra = make([]string, len(unknown))
+					}
+					extra[j] = value
+				}
+				continue
+			}
+			if col, ok := lookupColumn(name); ok {
+				if err := col.set(contact, value); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			contact.SetCustom(name, value)
 		}
 
-		updatedAt, err := time.Parse(time.RFC3339, record[7])
+		if err := addressBook.AddContact(contact); err != nil {
+			return nil, fmt.Errorf("failed to add contact: %w", err)
+		}
+		if extra != nil {
+			kept[contact.ID] = extra
+		}
+
+		parsed, err := models.ParseLinks(contact.ID, relationships)
 		if err != nil {
-			return fmt.Errorf("failed to parse UpdatedAt: %w", err)
+			return nil, fmt.Errorf("failed to parse Relationships: %w", err)
 		}
+		links = append(links, parsed...)
+	}
+	return links, nil
+}
 
-		contact := &models.Contact{
-			ID:        record[0],
-			FirstName: record[1],
-			LastName:  record[2],
-			Email:     record[3],
-			Phone:     record[4],
-			Address:   record[5],
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
+// keptValues returns a contact's values in the unknown columns. A contact
+// merged from others keeps the values of the first that had any.
+func keptValues(contact *models.Contact, unknown []string, kept map[string][]string) []string {
+	for _, id := range append([]string{contact.ID}, contact.Aliases...) {
+		if values, ok := kept[id]; ok {
+			return values
 		}
+	}
+	return make([]string, len(unknown))
+}
 
-		if err := addressBook.AddContact(contact); err != nil {
-			return fmt.Errorf("failed to add contact: %w", err)
+// indexOf returns the position of name in header, or -1
+func indexOf(header []string, name string) int {
+	for i, h := range header {
+		if h == name {
+			return i
 		}
 	}
-	return nil
+	return -1
 }
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+func TestUnknownColumnsSurviveSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.csv")
+	data := "ID,FirstName,LastName,Nickname,Tier\n" +
+		"01JAAAAAAAAAAAAAAAAAAAAAAA,Ada,Lovelace,Countess,gold\n" +
+		"01JBBBBBBBBBBBBBBBBBBBBBBB,Bob,Jones,,silver\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewCSVStorage(path)
+	store.SetSchema(models.Schema{{Name: "Tier", Type: models.FieldString}})
+	ab, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := store.UnknownColumns(); !slices.Equal(got, []string{"Nickname"}) {
+		t.Errorf("UnknownColumns = %q", got)
+	}
+	added := models.NewContact("Cy", "Young", "", "", "")
+	if err := ab.AddContact(added); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ab); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := slices.Index(records[0], "Nickname")
+	if column < 0 {
+		t.Fatalf("Nickname column dropped: %q", records[0])
+	}
+	nicknames := make(map[string]string)
+	for _, record := range records[1:] {
+		nicknames[record[0]] = record[column]
+	}
+	want := map[string]string{"01JAAAAAAAAAAAAAAAAAAAAAAA": "Countess", "01JBBBBBBBBBBBBBBBBBBBBBBB": "", added.ID: ""}
+	if len(nicknames) != len(want) {
+		t.Fatalf("Saved %d contacts, want %d", len(nicknames), len(want))
+	}
+	for id, nickname := range want {
+		if nicknames[id] != nickname {
+			t.Errorf("Nickname of %s = %q, want %q", id, nicknames[id], nickname)
+		}
+	}
+}
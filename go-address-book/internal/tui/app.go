@@ -0,0 +1,346 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+	"github.com/rushi/address-book-cli/internal/models"
+	"github.com/rushi/address-book-cli/internal/query"
+)
+
+// mode is what key presses currently act on
+type mode int
+
+const (
+	modeList   mode = iota
+	modeSearch      // typing into the search box
+	modeForm        // adding or editing a contact
+	modeDelete      // waiting for the delete to be confirmed
+)
+
+// change is an applied edit that can be undone
+type change struct {
+	desc   string
+	revert func(ab *models.AddressBook) error
+}
+
+// app is the state of the TUI, kept apart from the terminal so it can be
+// driven by tests
+type app struct {
+	ab     *models.AddressBook
+	locale string
+
+	all       []*models.Contact // every contact in name order; nil when stale
+	contacts  []*models.Contact // the contacts matching the filter
+	filter    []rune
+	filterErr string
+
+	selected int
+	top      int // index of the first visible contact
+	rows     int // how many contacts fit on screen, set by view
+
+	mode   mode
+	form   *form
+	undo   []change
+	status string
+	quit   bool
+}
+
+func newApp(ab *models.AddressBook, locale string) *app {
+	a := &app{ab: ab, locale: locale, rows: 20}
+	a.refresh()
+	return a
+}
+
+// current returns the selected contact, or nil
+func (a *app) current() *models.Contact {
+	if a.selected < len(a.contacts) {
+		return a.contacts[a.selected]
+	}
+	return nil
+}
+
+// refresh reruns the filter, keeping the same contact selected if it still
+// matches. An unfinished query keeps the previous results on screen.
+func (a *app) refresh() {
+	var keep string
+	if c := a.current(); c != nil {
+		keep = c.ID
+	}
+
+	text := strings.TrimSpace(string(a.filter))
+	a.filterErr = ""
+	if text == "" {
+		if a.all == nil {
+			page, err := a.ab.List(models.ListOptions{Locale: a.locale})
+			if err != nil {
+				page, _ = a.ab.List(models.ListOptions{})
+			}
+			a.all = page.Contacts
+		}
+		a.contacts = a.all
+	} else {
+		contacts, err := query.Search(a.ab, text)
+		if err != nil {
+			a.filterErr = err.Error()
+			return
+		}
+		a.contacts = contacts
+	}
+
+	a.selected = 0
+	for i, c := range a.contacts {
+		if c.ID == keep {
+			a.selected = i
+			break
+		}
+	}
+	a.scroll()
+}
+
+// changed refreshes the list after the address book was modified
+func (a *app) changed(selectID string) {
+	a.all = nil
+	a.refresh()
+	for i, c := range a.contacts {
+		if c.ID == selectID {
+			a.selected = i
+			a.scroll()
+		}
+	}
+}
+
+// move moves the selection by delta rows
+func (a *app) move(delta int) {
+	a.selected = max(0, min(a.selected+delta, len(a.contacts)-1))
+	a.scroll()
+}
+
+// scroll keeps the selected contact visible
+func (a *app) scroll() {
+	if a.selected < a.top {
+		a.top = a.selected
+	}
+	if a.rows > 0 && a.selected >= a.top+a.rows {
+		a.top = a.selected - a.rows + 1
+	}
+	a.top = max(0, min(a.top, len(a.contacts)-a.rows))
+}
+
+// handle applies one key press
+func (a *app) handle(k keys.Key) {
+	if k.Name == keys.CtrlC {
+		a.quit = true
+		return
+	}
+	switch a.mode {
+	case modeForm:
+		a.handleForm(k)
+	case modeDelete:
+		a.mode = modeList
+		if k.Name == "" && (k.Rune == 'y' || k.Rune == 'Y') {
+			a.deleteSelected()
+		} else {
+			a.status = "Delete cancelled"
+		}
+	case modeSearch:
+		a.handleSearch(k)
+	default:
+		a.handleList(k)
+	}
+}
+
+func (a *app) handleList(k keys.Key) {
+	a.status = ""
+	switch k.Name {
+	case keys.Up:
+		a.move(-1)
+	case keys.Down:
+		a.move(1)
+	case keys.PageUp:
+		a.move(-a.rows)
+	case keys.PageDown:
+		a.move(a.rows)
+	case keys.Home:
+		a.move(-len(a.contacts))
+	case keys.End:
+		a.move(len(a.contacts))
+	case keys.Enter:
+		a.edit()
+	case keys.Esc:
+		a.filter = nil
+		a.refresh()
+	case "":
+		switch k.Rune {
+		case 'k':
+			a.move(-1)
+		case 'j':
+			a.move(1)
+		case 'g':
+			a.move(-len(a.contacts))
+		case 'G':
+			a.move(len(a.contacts))
+		case '/':
+			a.mode = modeSearch
+		case 'a':
+			a.form = newForm(nil, a.ab.Schema())
+			a.mode = modeForm
+		case 'e':
+			a.edit()
+		case 'd':
+			if c := a.current(); c != nil {
+				a.mode = modeDelete
+				a.status = fmt.Sprintf("Delete %s? (y/n)", name(c))
+			}
+		case 'u':
+			a.undoLast()
+		case 'q':
+			a.quit = true
+		}
+	}
+}
+
+func (a *app) handleSearch(k keys.Key) {
+	switch k.Name {
+	case keys.Enter:
+		a.mode = modeList
+	case keys.Esc:
+		a.filter = nil
+		a.mode = modeList
+		a.refresh()
+	case keys.Up:
+		a.move(-1)
+	case keys.Down:
+		a.move(1)
+	case keys.PageUp:
+		a.move(-a.rows)
+	case keys.PageDown:
+		a.move(a.rows)
+	case keys.Backspace:
+		if len(a.filter) > 0 {
+			a.filter = a.filter[:len(a.filter)-1]
+			a.refresh()
+		}
+	case keys.CtrlU:
+		a.filter = nil
+		a.refresh()
+	case "":
+		a.filter = append(a.filter, k.Rune)
+		a.refresh()
+	}
+}
+
+func (a *app) edit() {
+	if c := a.current(); c != nil {
+		a.form = newForm(c, a.ab.Schema())
+		a.mode = modeForm
+	}
+}
+
+func (a *app) handleForm(k keys.Key) {
+	switch a.form.handle(k) {
+	case formCancel:
+		a.form, a.mode = nil, modeList
+	case formSave:
+		contact, ok := a.form.build()
+		if !ok {
+			return
+		}
+		if a.form.original == nil {
+			a.add(contact)
+		} else {
+			a.update(a.form.original, contact)
+		}
+	}
+}
+
+func (a *app) add(contact *models.Contact) {
+	if err := a.ab.AddContact(contact); err != nil {
+		a.form.reject(err)
+		return
+	}
+	id := contact.ID
+	a.push("add of "+name(contact), func(ab *models.AddressBook) error {
+		return ab.DeleteContact(id)
+	})
+	a.done("Added "+name(contact), id)
+}
+
+func (a *app) update(before, after *models.Contact) {
+	if err := a.ab.UpdateContact(after); err != nil {
+		a.form.reject(err)
+		return
+	}
+	a.push("edit of "+name(after), func(ab *models.AddressBook) error {
+		current, err := ab.GetContact(before.ID)
+		if err != nil {
+			return err
+		}
+		restored := before.Clone()
+		restored.Version = current.Version
+		return ab.UpdateContact(restored)
+	})
+	a.done("Saved "+name(after), after.ID)
+}
+
+func (a *app) deleteSelected() {
+	c := a.current()
+	if c == nil {
+		return
+	}
+	// Relationships go with the contact, so remember them for undo
+	links := append(a.ab.Links(c.ID), a.ab.LinksTo(c.ID)...)
+	if err := a.ab.DeleteContact(c.ID); err != nil {
+		a.status = "Error deleting contact: " + err.Error()
+		return
+	}
+	before := c.Clone()
+	a.push("delete of "+name(c), func(ab *models.AddressBook) error {
+		if err := ab.AddContact(before.Clone()); err != nil {
+			return err
+		}
+		for _, link := range links {
+			if err := ab.AddLink(link.From, link.Type, link.To); err != nil {
+				return fmt.Errorf("restoring relationships: %w", err)
+			}
+		}
+		return nil
+	})
+	a.changed("")
+	a.status = "Deleted " + name(c) + " (u to undo)"
+}
+
+// push records a change for undo
+func (a *app) push(desc string, revert func(ab *models.AddressBook) error) {
+	a.undo = append(a.undo, change{desc: desc, revert: revert})
+}
+
+// done leaves the form after a successful save
+func (a *app) done(status, id string) {
+	a.form, a.mode = nil, modeList
+	a.changed(id)
+	a.status = status + " (u to undo)"
+}
+
+func (a *app) undoLast() {
+	if len(a.undo) == 0 {
+		a.status = "Nothing to undo"
+		return
+	}
+	last := a.undo[len(a.undo)-1]
+	if err := last.revert(a.ab); err != nil {
+		a.status = fmt.Sprintf("Cannot undo %s: %v", last.desc, err)
+		return
+	}
+	a.undo = a.undo[:len(a.undo)-1]
+	a.changed("")
+	a.status = "Undid " + last.desc
+}
+
+func name(c *models.Contact) string {
+	if n := strings.TrimSpace(c.FirstName + " " + c.LastName); n != "" {
+		return n
+	}
+	return c.ID
+}
@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// formField is one line of the add and edit form
+type formField struct {
+	label string
+	value []rune
+	pos   int    // cursor position in value
+	err   string // why the value was rejected, shown beside it
+	set   func(c *models.Contact, value string) error
+}
+
+// form edits a new or existing contact. Unlike the menu's prompts, an empty
+// value clears a field.
+type form struct {
+	original *models.Contact // nil when adding
+	fields   []*formField
+	focus    int
+	err      string // an error not tied to one field, such as a conflict
+}
+
+// formAction is what a key press asks the form's owner to do
+type formAction int
+
+const (
+	formNone formAction = iota
+	formSave
+	formCancel
+)
+
+func textField(label, value string, set func(c *models.Contact, value string)) *formField {
+	return &formField{label: label, value: []rune(value), set: func(c *models.Contact, value string) error {
+		set(c, strings.TrimSpace(value))
+		return nil
+	}}
+}
+
+func dateField(label, dateLabel string, c *models.Contact) *formField {
+	value := ""
+	if date, ok := c.Date(dateLabel); ok {
+		value = date.String()
+	}
+	return &formField{label: label, value: []rune(value), set: func(c *models.Contact, value string) error {
+		if strings.TrimSpace(value) == "" {
+			c.RemoveDate(dateLabel)
+			return nil
+		}
+		date, err := models.ParseImportantDate(dateLabel, value)
+		if err != nil {
+			return err
+		}
+		c.SetDate(date)
+		return nil
+	}}
+}
+
+// isYearly reports whether a date has its own form field
+func isYearly(label string) bool {
+	return strings.EqualFold(label, models.LabelBirthday) || strings.EqualFold(label, models.LabelAnniversary)
+}
+
+// newForm builds a form for contact, or for a new contact when it is nil
+func newForm(contact *models.Contact, schema models.Schema) *form {
+	f := &form{original: contact}
+	c := contact
+	if c == nil {
+		c = &models.Contact{}
+	}
+
+	var others []models.ImportantDate
+	for _, date := range c.Dates {
+		if !isYearly(date.Label) {
+			others = append(others, date)
+		}
+	}
+
+	f.fields = []*formField{
+		textField("First name", c.FirstName, func(c *models.Contact, v string) { c.FirstName = v }),
+		textField("Last name", c.LastName, func(c *models.Contact, v string) { c.LastName = v }),
+		textField("Email", c.Email, func(c *models.Contact, v string) { c.Email = v }),
+		textField("Phone", c.Phone, func(c *models.Contact, v string) { c.Phone = v }),
+		textField("Address", c.Address, func(c *models.Contact, v string) { c.Address = v }),
+		textField("Organization", c.Organization, func(c *models.Contact, v string) { c.Organization = v }),
+		textField("Title", c.Title, func(c *models.Contact, v string) { c.Title = v }),
+		textField("Department", c.Department, func(c *models.Contact, v string) { c.Department = v }),
+		textField("Tags", strings.Join(c.Tags, ", "), func(c *models.Contact, v string) { c.Tags = models.ParseTags(v) }),
+		dateField("Birthday", models.LabelBirthday, c),
+		dateField("Anniversary", models.LabelAnniversary, c),
+		{label: "Other dates", value: []rune(models.FormatDates(others)), set: func(c *models.Contact, value string) error {
+			dates, err := models.ParseDates(value)
+			if err != nil {
+				return err
+			}
+			kept := c.Dates[:0:0]
+			for _, date := range c.Dates {
+				if isYearly(date.Label) {
+					kept = append(kept, date)
+				}
+			}
+			c.Dates = append(kept, dates...)
+			return nil
+		}},
+	}
+	for _, def := range schema {
+		def := def
+		f.fields = append(f.fields, &formField{
+			label: fmt.Sprintf("%s (%s)", def.Name, def.Hint()),
+			value: []rune(c.Custom[def.Name]),
+			set: func(c *models.Contact, value string) error {
+				value = strings.TrimSpace(value)
+				if value != "" {
+					if err := def.Check(value); err != nil {
+						return err
+					}
+				}
+				c.SetCustom(def.Name, value)
+				return nil
+			},
+		})
+	}
+	for _, field := range f.fields {
+		field.pos = len(field.value)
+	}
+	return f
+}
+
+func (f *form) title() string {
+	if f.original == nil {
+		return "New contact"
+	}
+	return "Edit " + strings.TrimSpace(f.original.FirstName+" "+f.original.LastName)
+}
+
+// handle applies a key press to the focused field
+func (f *form) handle(k keys.Key) formAction {
+	field := f.fields[f.focus]
+	switch k.Name {
+	case keys.Esc:
+		return formCancel
+	case keys.CtrlS:
+		return formSave
+	case keys.Enter:
+		if f.focus == len(f.fields)-1 {
+			return formSave
+		}
+		f.focus++
+	case keys.Down, keys.Tab:
+		f.focus = (f.focus + 1) % len(f.fields)
+	case keys.Up, keys.ShiftTab:
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+	case keys.Left:
+		field.pos = max(field.pos-1, 0)
+	case keys.Right:
+		field.pos = min(field.pos+1, len(field.value))
+	case keys.Home:
+		field.pos = 0
+	case keys.End:
+		field.pos = len(field.value)
+	case keys.Backspace:
+		if field.pos > 0 {
+			field.value = append(field.value[:field.pos-1], field.value[field.pos:]...)
+			field.pos--
+		}
+	case keys.Delete:
+		if field.pos < len(field.value) {
+			field.value = append(field.value[:field.pos], field.value[field.pos+1:]...)
+		}
+	case keys.CtrlU:
+		field.value, field.pos = nil, 0
+	case "":
+		field.value = append(field.value[:field.pos], append([]rune{k.Rune}, field.value[field.pos:]...)...)
+		field.pos++
+	}
+	return formNone
+}
+
+// build applies every field to a copy of the original contact. It returns
+// false, with the reasons next to the fields, when a value is invalid.
+func (f *form) build() (*models.Contact, bool) {
+	var c *models.Contact
+	if f.original == nil {
+		c = models.NewContact("", "", "", "", "")
+	} else {
+		c = f.original.Clone()
+	}
+
+	valid := true
+	for _, field := range f.fields {
+		field.err = ""
+		if err := field.set(c, string(field.value)); err != nil {
+			field.err, valid = err.Error(), false
+		}
+	}
+	if c.FirstName == "" && c.LastName == "" {
+		f.fields[0].err, valid = "a first or last name is required", false
+	}
+	if !valid {
+		for i, field := range f.fields {
+			if field.err != "" {
+				f.focus = i
+				break
+			}
+		}
+	}
+	return c, valid
+}
+
+// reject shows an error the address book returned when saving
+func (f *form) reject(err error) {
+	var conflict *models.ConflictError
+	if errors.As(err, &conflict) {
+		f.err = "the contact was changed elsewhere; press Esc and edit it again"
+		return
+	}
+	f.err = err.Error()
+}
@@ -0,0 +1,73 @@
+// Package tui is a full-screen terminal interface for browsing and editing
+// an address book: a scrollable contact list with a live search box, a
+// detail pane, inline add and edit forms, delete and undo.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Run shows the TUI on the terminal until the user quits. Text is collated
+// for locale when sorting names. The caller saves the address book
+// afterwards.
+func Run(ab *models.AddressBook, locale string, in, out *os.File) error {
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return errors.New("the TUI needs an interactive terminal (with Docker, run with -it)")
+	}
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(inFd, state)
+
+	// Use the alternate screen, so the shell's scrollback is left as it was
+	out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer out.WriteString("\x1b[?25h\x1b[?1049l")
+
+	a := newApp(ab, locale)
+	w := bufio.NewWriter(out)
+	buf := make([]byte, 256)
+	for !a.quit {
+		width, height, err := term.GetSize(outFd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		draw(w, a.view(width, height))
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys.Parse(buf[:n]) {
+			a.handle(k)
+			if a.quit {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// draw repaints the screen from the top, clearing what each line leaves
+func draw(w *bufio.Writer, lines []string) {
+	w.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			w.WriteString("\r\n")
+		}
+		w.WriteString(line)
+		w.WriteString("\x1b[K")
+	}
+	w.WriteString("\x1b[J")
+}
@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rushi/address-book-cli/internal/keys"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+func testApp(t *testing.T) *app {
+	t.Helper()
+	ab := models.NewAddressBook()
+	if err := ab.SetSchema(models.Schema{{Name: "Score", Type: models.FieldNumber}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, names := range [][2]string{{"John", "Smith"}, {"Jane", "Doe"}, {"Bob", "Jones"}} {
+		c := models.NewContact(names[0], names[1], strings.ToLower(names[0])+"@example.com", "", "")
+		if err := ab.AddContact(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return newApp(ab, "")
+}
+
+// typeKeys feeds raw terminal input to the app
+func typeKeys(a *app, input string) {
+	for _, k := range keys.Parse([]byte(input)) {
+		a.handle(k)
+	}
+}
+
+func listed(a *app) string {
+	names := make([]string, len(a.contacts))
+	for i, c := range a.contacts {
+		names[i] = name(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestListAndLiveSearch(t *testing.T) {
+	a := testApp(t)
+	if got := listed(a); got != "Jane Doe, Bob Jones, John Smith" {
+		t.Fatalf("Expected contacts by last name, got %s", got)
+	}
+
+	typeKeys(a, "j") // down
+	if name(a.current()) != "Bob Jones" {
+		t.Errorf("Expected j to move down, got %s", name(a.current()))
+	}
+
+	typeKeys(a, "/jo")
+	if got := listed(a); got != "John Smith, Bob Jones" {
+		t.Errorf("Expected the list to filter while typing, got %s", got)
+	}
+	if name(a.current()) != "Bob Jones" {
+		t.Error("Expected the selection to stay on the same contact")
+	}
+
+	// An unfinished query keeps the last results and reports the problem
+	typeKeys(a, ":")
+	if a.filterErr == "" || listed(a) != "John Smith, Bob Jones" {
+		t.Errorf("Expected an error and the previous list, got %q and %s", a.filterErr, listed(a))
+	}
+	typeKeys(a, "\x15last:smith\r")
+	if a.mode != modeList || listed(a) != "John Smith" {
+		t.Errorf("Expected a field query to filter, got %s", listed(a))
+	}
+
+	typeKeys(a, "\x1b")
+	if len(a.contacts) != 3 {
+		t.Error("Expected Esc to clear the search")
+	}
+}
+
+func TestAddWithValidation(t *testing.T) {
+	a := testApp(t)
+	typeKeys(a, "a")
+	if a.mode != modeForm {
+		t.Fatal("Expected a to open the form")
+	}
+
+	// Save an empty form: the name is required
+	typeKeys(a, "\x13")
+	if a.mode != modeForm || a.form.fields[0].err == "" {
+		t.Fatal("Expected the form to stay open with a name error")
+	}
+
+	typeKeys(a, "Ada\tLovelace")
+	for a.form.fields[a.form.focus].label != "Birthday" {
+		typeKeys(a, "\t")
+	}
+	typeKeys(a, "13-45")
+	for !strings.HasPrefix(a.form.fields[a.form.focus].label, "Score") {
+		typeKeys(a, "\t")
+	}
+	typeKeys(a, "high\x13")
+	var errs []string
+	for _, field := range a.form.fields {
+		if field.err != "" {
+			errs = append(errs, field.label)
+		}
+	}
+	if strings.Join(errs, ",") != "Birthday,Score (number)" {
+		t.Fatalf("Expected errors next to the bad fields, got %v", errs)
+	}
+	if a.form.fields[a.form.focus].label != "Birthday" {
+		t.Error("Expected the focus to move to the first bad field")
+	}
+
+	typeKeys(a, "\x15") // clear the birthday
+	for !strings.HasPrefix(a.form.fields[a.form.focus].label, "Score") {
+		typeKeys(a, "\t")
+	}
+	typeKeys(a, "\x15"+"42\x13")
+	if a.mode != modeList {
+		t.Fatalf("Expected the contact to be saved, form errors: %v", a.form.err)
+	}
+	c := a.current()
+	if name(c) != "Ada Lovelace" || c.Custom["Score"] != "42" {
+		t.Errorf("Expected the new contact to be selected, got %s %v", name(c), c.Custom)
+	}
+
+	typeKeys(a, "u")
+	if len(a.contacts) != 3 || !strings.HasPrefix(a.status, "Undid add") {
+		t.Errorf("Expected undo to remove the new contact, got %s (%s)", listed(a), a.status)
+	}
+}
+
+func TestEditClearsFieldAndUndo(t *testing.T) {
+	a := testApp(t)
+	typeKeys(a, "G") // John Smith
+	id := a.current().ID
+
+	typeKeys(a, "e\t\t")
+	if a.form.fields[a.form.focus].label != "Email" {
+		t.Fatalf("Expected the email field, got %s", a.form.fields[a.form.focus].label)
+	}
+	typeKeys(a, "\x15\x1b[Atitle\x13") // clearing a field is allowed; Up goes back to the last name
+	stored, _ := a.ab.GetContact(id)
+	if stored.Email != "" || stored.LastName != "Smithtitle" {
+		t.Errorf("Expected the email cleared and last name changed, got %q %q", stored.Email, stored.LastName)
+	}
+
+	typeKeys(a, "u")
+	stored, _ = a.ab.GetContact(id)
+	if stored.Email != "john@example.com" || stored.LastName != "Smith" {
+		t.Errorf("Expected undo to restore the contact, got %q %q", stored.Email, stored.LastName)
+	}
+}
+
+func TestDeleteAndUndo(t *testing.T) {
+	a := testApp(t)
+	jane, bob := a.contacts[0], a.contacts[1]
+	if err := a.ab.AddLink(jane.ID, models.RelationSpouse, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	typeKeys(a, "dn")
+	if len(a.contacts) != 3 || a.status != "Delete cancelled" {
+		t.Fatal("Expected anything but y to cancel the delete")
+	}
+	typeKeys(a, "dy")
+	if got := listed(a); got != "Bob Jones, John Smith" {
+		t.Fatalf("Expected Jane to be deleted, got %s", got)
+	}
+	if len(a.ab.Links(bob.ID)) != 0 {
+		t.Error("Expected the relationship to go with the contact")
+	}
+
+	typeKeys(a, "u")
+	if got := listed(a); got != "Jane Doe, Bob Jones, John Smith" {
+		t.Errorf("Expected undo to restore Jane, got %s", got)
+	}
+	if len(a.ab.Links(jane.ID)) != 1 {
+		t.Error("Expected undo to restore the relationship")
+	}
+	typeKeys(a, "u")
+	if a.status != "Nothing to undo" {
+		t.Errorf("Expected nothing left to undo, got %q", a.status)
+	}
+}
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]")
+
+func TestView(t *testing.T) {
+	a := testApp(t)
+	for i := 0; i < 40; i++ {
+		if err := a.ab.AddContact(models.NewContact("Extra", "Person", "", "", "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.changed("")
+
+	lines := a.view(80, 12)
+	if len(lines) != 12 {
+		t.Fatalf("Expected 12 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := len([]rune(ansi.ReplaceAllString(line, ""))); n > 80 {
+			t.Errorf("Line %d is %d characters wide: %q", i, n, line)
+		}
+	}
+	if !strings.Contains(lines[2], "Jane Doe") || !strings.Contains(lines[2], "Name: Jane Doe") {
+		t.Errorf("Expected the list and the detail pane, got %q", lines[2])
+	}
+
+	typeKeys(a, "\x1b[6~\x1b[6~")
+	a.view(80, 12)
+	if a.selected != 16 || a.top != 9 {
+		t.Errorf("Expected two pages down to scroll, got selected %d top %d", a.selected, a.top)
+	}
+	typeKeys(a, "G")
+	a.view(80, 12)
+	if a.top != len(a.contacts)-8 {
+		t.Errorf("Expected the end of the list to fill the screen, got top %d", a.top)
+	}
+}
@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rushi/address-book-cli/internal/format"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// ANSI styles
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleReverse = "\x1b[7m"
+	styleDim     = "\x1b[2m"
+	styleRed     = "\x1b[31m"
+)
+
+// fit pads or cuts s to exactly width characters
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
+
+// view renders the screen as lines of exactly width characters, not
+// counting style escapes
+func (a *app) view(width, height int) []string {
+	width, height = max(width, 20), max(height, 6)
+	a.rows = height - 4
+	a.scroll()
+
+	lines := make([]string, 0, height)
+	lines = append(lines, a.header(width), styleDim+strings.Repeat("─", width)+styleReset)
+
+	listWidth := min(max(width/3, 16), 40)
+	detailWidth := width - listWidth - 3
+	detail := a.detail(detailWidth)
+	for row := 0; row < a.rows; row++ {
+		left := fit("", listWidth)
+		if i := a.top + row; i < len(a.contacts) {
+			left = fit(" "+name(a.contacts[i]), listWidth)
+			if i == a.selected {
+				left = styleReverse + left + styleReset
+			}
+		}
+		right := ""
+		if row < len(detail) {
+			right = detail[row]
+		}
+		lines = append(lines, left+styleDim+" │ "+styleReset+right)
+	}
+
+	lines = append(lines, styleDim+strings.Repeat("─", width)+styleReset, a.footer(width))
+	return lines
+}
+
+func (a *app) header(width int) string {
+	title := " Address Book  Search: "
+	count := fmt.Sprintf(" %d contacts ", len(a.contacts))
+	box := string(a.filter)
+	if a.mode == modeSearch {
+		box += "▏"
+	}
+	rest := width - len(title) - len(count)
+	middle := fit(box, rest)
+	if room := rest - len([]rune(box)); a.filterErr != "" && room > 0 {
+		middle = box + styleRed + fit("  "+a.filterErr, room) + styleReset
+	}
+	return styleBold + title + styleReset + middle + count
+}
+
+func (a *app) footer(width int) string {
+	if a.status != "" {
+		return fit(" "+a.status, width)
+	}
+	var help string
+	switch a.mode {
+	case modeForm:
+		help = "↑↓/Tab field  ←→ move  Ctrl-U clear field  Enter next  Ctrl-S save  Esc cancel"
+	case modeSearch:
+		help = "Type words, ~fuzzy words or a query like tag:vip  ↑↓ move  Enter done  Esc clear"
+	default:
+		help = "↑↓ move  / search  Enter/e edit  a add  d delete  u undo  q quit"
+	}
+	return styleDim + fit(" "+help, width) + styleReset
+}
+
+// detailColumns are the fields the detail pane shows when they have a value
+const detailColumns = "name,email,phone,address,other-emails,other-phones,other-addresses," +
+	"org,title,dept,tags,dates,contacted,aliases,id,created,updated"
+
+// detail returns the right-hand pane: the form when editing, otherwise the
+// selected contact's fields
+func (a *app) detail(width int) []string {
+	if a.mode == modeForm {
+		lines, focus := a.formLines(width)
+		// Scroll long forms so the focused field stays in view
+		if skip := focus - a.rows + 2; skip > 0 {
+			lines = lines[skip:]
+		}
+		return lines
+	}
+	c := a.current()
+	if c == nil {
+		if len(a.filter) > 0 {
+			return []string{"No contacts match the search."}
+		}
+		return []string{"No contacts yet. Press a to add one."}
+	}
+
+	columns, _ := format.ParseColumns(detailColumns, nil)
+	for _, def := range a.ab.Schema() {
+		col, _ := format.ParseColumns(def.Name, a.ab.Schema())
+		columns = append(columns, col...)
+	}
+	var lines []string
+	for _, col := range columns {
+		if value := col.Text(c); value != "" {
+			lines = append(lines, fit(col.Header+": "+value, width))
+		}
+	}
+	for _, link := range a.ab.Links(c.ID) {
+		lines = append(lines, fit(link.Type.Label()+": "+a.nameOf(link.To), width))
+	}
+	for _, link := range a.ab.LinksTo(c.ID) {
+		if link.Type != models.RelationSpouse {
+			lines = append(lines, fit(link.Type.InverseLabel()+": "+a.nameOf(link.From), width))
+		}
+	}
+	return lines
+}
+
+func (a *app) nameOf(id string) string {
+	c, err := a.ab.GetContact(id)
+	if err != nil {
+		return id
+	}
+	return name(c)
+}
+
+// formLines renders the form, returning the lines and the index of the
+// focused field's line
+func (a *app) formLines(width int) ([]string, int) {
+	f := a.form
+	labelWidth := 0
+	for _, field := range f.fields {
+		labelWidth = max(labelWidth, len([]rune(field.label)))
+	}
+	lines := []string{styleBold + fit(f.title(), width) + styleReset, ""}
+	valueWidth := width - labelWidth - 2
+	focus := 0
+	for i, field := range f.fields {
+		label := fit(field.label, labelWidth) + ": "
+		value := string(field.value)
+		if i == f.focus {
+			// Draw the cursor as a reversed character
+			r := append([]rune(nil), field.value...)
+			if field.pos >= len(r) {
+				r = append(r, ' ')
+			}
+			start := max(0, field.pos-valueWidth+1)
+			end := min(len(r), start+valueWidth)
+			var b strings.Builder
+			for j := start; j < end; j++ {
+				if j == field.pos {
+					b.WriteString(styleReverse + string(r[j]) + styleReset)
+				} else {
+					b.WriteRune(r[j])
+				}
+			}
+			b.WriteString(strings.Repeat(" ", max(0, valueWidth-(end-start))))
+			focus = len(lines)
+			lines = append(lines, styleBold+label+styleReset+b.String())
+		} else {
+			lines = append(lines, label+fit(value, valueWidth))
+		}
+		if field.err != "" {
+			lines = append(lines, styleRed+fit(strings.Repeat(" ", labelWidth+2)+"↳ "+field.err, width)+styleReset)
+		}
+	}
+	if f.err != "" {
+		lines = append(lines, "", styleRed+fit(f.err, width)+styleReset)
+	}
+	return lines, focus
+}
@@ -0,0 +1,391 @@
+package vcard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/ianaindex"
+
+	"github.com/rushi/address-book-cli/internal/importer"
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Warning is a problem with part of a file. Decode skips the property or
+// card and carries on.
+type Warning struct {
+	Line int
+	Msg  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Msg)
+}
+
+// Result is what Decode found in a file
+type Result struct {
+	Contacts []*models.Contact
+	Warnings []Warning
+	Ignored  []string // properties no contact field holds, such as PHOTO, sorted
+}
+
+// structural are the properties that describe the card rather than the
+// contact, and are never reported as ignored. REV is replaced by the time
+// of the import.
+var structural = map[string]bool{"BEGIN": true, "END": true, "VERSION": true, "PRODID": true, "REV": true, "X-ABLABEL": true}
+
+// Decode reads every vCard in r. Cards and properties that can't be read
+// are skipped with a warning; it is an error only when r can't be read or
+// holds no cards at all.
+func Decode(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = importer.DecodeText(data); err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	d := &decoder{ignored: make(map[string]bool)}
+	var current *card
+	for _, line := range unfold(text) {
+		p, err := parseLine(line.text, line.number)
+		if err != nil {
+			d.warn(line.number, "%v", err)
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(strings.TrimSpace(p.value), "VCARD"):
+			if current != nil {
+				d.warn(current.line, "card has no END:VCARD")
+				d.finish(current)
+			}
+			current = &card{line: p.line}
+		case p.name == "END" && strings.EqualFold(strings.TrimSpace(p.value), "VCARD"):
+			if current == nil {
+				d.warn(p.line, "END:VCARD without BEGIN:VCARD")
+				continue
+			}
+			d.finish(current)
+			current = nil
+		case current == nil:
+			d.warn(p.line, "%s is outside a card", p.name)
+		default:
+			current.props = append(current.props, p)
+		}
+	}
+	if current != nil {
+		d.warn(current.line, "card has no END:VCARD")
+		d.finish(current)
+	}
+
+	if d.cards == 0 {
+		return nil, errors.New("no vCards found")
+	}
+	sort.SliceStable(d.result.Warnings, func(i, j int) bool { return d.result.Warnings[i].Line < d.result.Warnings[j].Line })
+	for name := range d.ignored {
+		d.result.Ignored = append(d.result.Ignored, name)
+	}
+	sort.Strings(d.result.Ignored)
+	return &d.result, nil
+}
+
+// logicalLine is a content line after unfolding
+type logicalLine struct {
+	number int
+	text   string
+}
+
+// unfold joins folded lines, which continue on lines starting with a space
+// or tab, and quoted-printable values, which continue after a line ending
+// in "="
+func unfold(text string) []logicalLine {
+	var lines []logicalLine
+	for i, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSuffix(raw, "\r")
+		last := len(lines) - 1
+		switch {
+		case last >= 0 && softBreak(lines[last].text):
+			lines[last].text = strings.TrimSuffix(lines[last].text, "=") + raw
+		case last >= 0 && raw != "" && (raw[0] == ' ' || raw[0] == '\t'):
+			lines[last].text += raw[1:]
+		case strings.TrimSpace(raw) != "":
+			lines = append(lines, logicalLine{number: i + 1, text: raw})
+		}
+	}
+	return lines
+}
+
+// softBreak reports whether a quoted-printable value continues on the next
+// line
+func softBreak(line string) bool {
+	if !strings.HasSuffix(line, "=") {
+		return false
+	}
+	head, _, ok := strings.Cut(line, ":")
+	return ok && strings.Contains(strings.ToUpper(head), "QUOTED-PRINTABLE")
+}
+
+// decodeValue undoes a value's transfer encoding and character set,
+// leaving its backslash escapes alone. Text that still isn't UTF-8 is read
+// as Windows-1252, which is what files that get this wrong usually hold.
+func decodeValue(p *property) (string, error) {
+	value := p.value
+	switch encoding := strings.ToUpper(p.param("ENCODING")); encoding {
+	case "QUOTED-PRINTABLE":
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted-printable value: %w", err)
+		}
+		value = string(decoded)
+	case "B", "BASE64":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 value: %w", err)
+		}
+		value = string(decoded)
+	}
+	if charset := p.param("CHARSET"); charset != "" {
+		enc, err := ianaindex.MIME.Encoding(charset)
+		if err != nil || enc == nil {
+			return "", fmt.Errorf("unsupported charset %q", charset)
+		}
+		if value, err = enc.NewDecoder().String(value); err != nil {
+			return "", fmt.Errorf("invalid %s text: %w", charset, err)
+		}
+	}
+	if !utf8.ValidString(value) {
+		value, _ = charmap.Windows1252.NewDecoder().String(value)
+	}
+	return value, nil
+}
+
+// card is the properties of one vCard
+type card struct {
+	line  int
+	props []*property
+}
+
+type decoder struct {
+	result  Result
+	cards   int
+	ignored map[string]bool
+}
+
+func (d *decoder) warn(line int, format string, args ...any) {
+	d.result.Warnings = append(d.result.Warnings, Warning{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// rank returns the preference of a property: its PREF parameter (4.0), 1
+// for TYPE=pref (2.1 and 3.0), and after every preferred value otherwise
+func rank(p *property) int {
+	if n, err := strconv.Atoi(p.param("PREF")); err == nil {
+		return n
+	}
+	if p.hasType("pref") {
+		return 1
+	}
+	return 101
+}
+
+// finish turns a card into a contact
+func (d *decoder) finish(c *card) {
+	d.cards++
+	contact := models.NewContact("", "", "", "", "")
+	labels := make(map[string]string) // X-ABLabel by group, naming an X-ABDate
+	for _, p := range c.props {
+		if p.name == "X-ABLABEL" && p.group != "" {
+			if value, err := decodeValue(p); err == nil {
+				labels[p.group] = unescape(value)
+			}
+		}
+	}
+
+	var formattedName string
+	var emails, phones, addresses []importer.Ranked
+	for _, p := range c.props {
+		value, err := decodeValue(p)
+		if err != nil {
+			d.warn(p.line, "%s: %v", p.name, err)
+			continue
+		}
+		switch p.name {
+		case "VERSION":
+			if v := strings.TrimSpace(value); v != "2.1" && !slices.Contains(Versions, v) {
+				d.warn(p.line, "unknown vCard version %q", v)
+			}
+		case "FN":
+			formattedName = strings.TrimSpace(unescape(value))
+		case "N":
+			parts := splitValue(value, ';')
+			contact.LastName = component(parts[0])
+			if len(parts) > 1 {
+				given := []string{component(parts[1])}
+				if len(parts) > 2 {
+					given = append(given, component(parts[2])) // middle names
+				}
+				contact.FirstName = strings.TrimSpace(strings.Join(given, " "))
+			}
+		case "EMAIL":
+			emails = append(emails, importer.Ranked{Value: strings.TrimSpace(unescape(value)), Rank: rank(p)})
+		case "TEL":
+			phone := strings.TrimSpace(unescape(value))
+			if len(phone) > 4 && strings.EqualFold(phone[:4], "tel:") {
+				phone = phone[4:]
+			}
+			phones = append(phones, importer.Ranked{Value: phone, Rank: rank(p)})
+		case "ADR":
+			addresses = append(addresses, importer.Ranked{Value: address(value), Rank: rank(p)})
+		case "ORG":
+			parts := splitValue(value, ';')
+			contact.Organization = strings.TrimSpace(unescape(parts[0]))
+			if len(parts) > 1 {
+				contact.Department = strings.TrimSpace(unescape(parts[1]))
+			}
+		case "TITLE":
+			contact.Title = strings.TrimSpace(unescape(value))
+		case "CATEGORIES":
+			var tags []string
+			for _, tag := range splitValue(value, ',') {
+				tags = append(tags, unescape(tag))
+			}
+			contact.Tags = models.ParseTags(strings.Join(append(contact.Tags, tags...), ","))
+		case "BDAY":
+			d.date(contact, p, models.LabelBirthday, value)
+		case "ANNIVERSARY", "X-ANNIVERSARY", "X-MS-ANNIVERSARY", "X-EVOLUTION-ANNIVERSARY":
+			d.date(contact, p, models.LabelAnniversary, value)
+		case "X-ABDATE":
+			d.date(contact, p, appleLabel(labels[p.group]), value)
+		case "NOTE":
+			if note := strings.TrimSpace(unescape(value)); note != "" {
+				contact.Interactions = append(contact.Interactions, models.Interaction{
+					At: time.Now(), Kind: models.InteractionNote, Summary: note,
+				})
+			}
+		case "UID":
+			if id := strings.TrimSpace(value); models.IsID(id) || models.IsLegacyID(id) {
+				contact.ID = id
+			}
+		case customProperty:
+			if name := p.param(customParam); name != "" {
+				contact.SetCustom(name, unescape(value))
+			}
+		default:
+			if !structural[p.name] {
+				d.ignored[p.name] = true
+			}
+		}
+	}
+	contact.Email, contact.OtherEmails = importer.Primary(emails)
+	contact.Phone, contact.OtherPhones = importer.Primary(phones)
+	contact.Address, contact.OtherAddresses = importer.Primary(addresses)
+
+	if contact.FirstName == "" && contact.LastName == "" {
+		name := formattedName
+		if name == "" {
+			name = contact.Organization
+		}
+		if name == "" {
+			name = contact.Email
+		}
+		if name == "" {
+			d.warn(c.line, "card has no name, email or organization; skipped")
+			return
+		}
+		// A name without its parts: the last word is taken as the family name
+		if i := strings.LastIndexByte(name, ' '); i > 0 && name == formattedName {
+			contact.FirstName, contact.LastName = name[:i], name[i+1:]
+		} else {
+			contact.FirstName = name
+		}
+	}
+	d.result.Contacts = append(d.result.Contacts, contact)
+}
+
+// component reads one component of N, whose values may be comma-separated
+// lists such as several middle names
+func component(part string) string {
+	var words []string
+	for _, word := range splitValue(part, ',') {
+		if word = strings.TrimSpace(unescape(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// address joins the components of ADR, post office box, extended address,
+// street, locality, region, postal code and country, into one line such as
+// "1 Main St, Dallas, TX 75001, USA"
+func address(value string) string {
+	parts := splitValue(value, ';')
+	for len(parts) < 7 {
+		parts = append(parts, "")
+	}
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(strings.ReplaceAll(unescape(part), "\n", ", "))
+	}
+	var lines []string
+	for _, line := range []string{parts[0], parts[1], parts[2], parts[3], strings.TrimSpace(parts[4] + " " + parts[5]), parts[6]} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, ", ")
+}
+
+// appleLabel reads the label Apple gives a date, such as "_$!<Anniversary>!$_"
+// for one of its own labels
+func appleLabel(label string) string {
+	if strings.HasPrefix(label, "_$!<") && strings.HasSuffix(label, ">!$_") {
+		return strings.ToLower(label[4 : len(label)-4])
+	}
+	if label == "" {
+		return "other"
+	}
+	return label
+}
+
+// date reads a yearly date in any of the forms vCards use: 1985-04-12,
+// 19850412, --04-12, --0412 or a date-time, with Apple's year 1604 standing
+// for an unknown year
+func (d *decoder) date(contact *models.Contact, p *property, label, value string) {
+	value = strings.TrimSpace(unescape(value))
+	if strings.EqualFold(p.param("VALUE"), "text") {
+		d.warn(p.line, "%s: %q is not a date", p.name, value)
+		return
+	}
+	if i := strings.IndexByte(value, 'T'); i >= 0 {
+		value = value[:i]
+	}
+	if year := p.param("X-APPLE-OMIT-YEAR"); year != "" && strings.HasPrefix(value, year+"-") {
+		value = value[len(year)+1:]
+	} else if strings.HasPrefix(value, "1604-") {
+		value = value[5:]
+	}
+	value = strings.TrimPrefix(value, "--")
+	if _, err := strconv.Atoi(value); err == nil {
+		switch len(value) {
+		case 8:
+			value = value[:4] + "-" + value[4:6] + "-" + value[6:]
+		case 4:
+			value = value[:2] + "-" + value[2:]
+		}
+	}
+	date, err := models.ParseImportantDate(label, value)
+	if err != nil {
+		d.warn(p.line, "%s: %v", p.name, err)
+		return
+	}
+	contact.SetDate(date)
+}
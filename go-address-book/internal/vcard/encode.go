@@ -0,0 +1,217 @@
+package vcard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+// Custom fields are written as X-CUSTOM properties named by an X-NAME
+// parameter, as in "X-CUSTOM;X-NAME=Account Manager:Dana"
+const (
+	customProperty = "X-CUSTOM"
+	customParam    = "X-NAME"
+)
+
+// maxLine is the longest line, in octets, Encode writes before folding
+const maxLine = 75
+
+// Writer writes contacts as vCards. Close must be called after the last
+// contact to flush the output.
+type Writer struct {
+	w       *bufio.Writer
+	version string
+}
+
+// NewWriter returns a Writer for one of Versions; "" means DefaultVersion
+func NewWriter(w io.Writer, version string) (*Writer, error) {
+	if version == "" {
+		version = DefaultVersion
+	}
+	if !slices.Contains(Versions, version) {
+		return nil, fmt.Errorf("unknown vCard version %q; versions are %s", version, strings.Join(Versions, ", "))
+	}
+	return &Writer{w: bufio.NewWriter(w), version: version}, nil
+}
+
+// Encode writes contacts as vCards of the given version
+func Encode(w io.Writer, contacts []*models.Contact, version string) error {
+	out, err := NewWriter(w, version)
+	if err != nil {
+		return err
+	}
+	for _, c := range contacts {
+		if err := out.Write(c); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// Write writes one contact as a vCard
+func (w *Writer) Write(c *models.Contact) error {
+	v4 := w.version == "4.0"
+	w.line("BEGIN", "", "VCARD")
+	w.line("VERSION", "", w.version)
+	w.line("UID", "", c.ID)
+	w.line("FN", "", escape(fullName(c)))
+	w.line("N", "", escape(c.LastName)+";"+escape(c.FirstName)+";;;")
+
+	typed := func(name, v3Type, value string, others []string) {
+		if value == "" {
+			return
+		}
+		pref := ";PREF=1"
+		if !v4 {
+			pref = ";TYPE=" + strings.TrimPrefix(v3Type+",PREF", ",")
+		}
+		w.line(name, pref, value)
+		for _, other := range others {
+			params := ""
+			if !v4 && v3Type != "" {
+				params = ";TYPE=" + v3Type
+			}
+			w.line(name, params, other)
+		}
+	}
+	typed("EMAIL", "INTERNET", escape(c.Email), escapeAll(c.OtherEmails))
+	typed("TEL", "VOICE", escape(c.Phone), escapeAll(c.OtherPhones))
+	typed("ADR", "", adr(c.Address), adrAll(c.OtherAddresses))
+
+	if c.Organization != "" || c.Department != "" {
+		org := escape(c.Organization)
+		if c.Department != "" {
+			org += ";" + escape(c.Department)
+		}
+		w.line("ORG", "", org)
+	}
+	if c.Title != "" {
+		w.line("TITLE", "", escape(c.Title))
+	}
+	if len(c.Tags) > 0 {
+		w.line("CATEGORIES", "", strings.Join(escapeAll(c.Tags), ","))
+	}
+
+	item := 0
+	for _, d := range c.Dates {
+		switch {
+		case strings.EqualFold(d.Label, models.LabelBirthday):
+			w.line("BDAY", "", w.date(d))
+		case strings.EqualFold(d.Label, models.LabelAnniversary) && v4:
+			w.line("ANNIVERSARY", "", w.date(d))
+		case strings.EqualFold(d.Label, models.LabelAnniversary):
+			w.line("X-ANNIVERSARY", "", w.date(d))
+		default:
+			// Other dates are labeled the way Apple labels them
+			item++
+			w.line(fmt.Sprintf("item%d.X-ABDATE", item), "", w.date(d))
+			w.line(fmt.Sprintf("item%d.X-ABLABEL", item), "", escape(d.Label))
+		}
+	}
+	for _, in := range c.Interactions {
+		if in.Kind == models.InteractionNote {
+			w.line("NOTE", "", escape(in.Summary))
+		}
+	}
+
+	names := make([]string, 0, len(c.Custom))
+	for name := range c.Custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := c.Custom[name]; value != "" {
+			w.line(customProperty, ";"+customParam+"="+quoteParam(name, w.version), escape(value))
+		}
+	}
+	if !c.UpdatedAt.IsZero() {
+		layout := "2006-01-02T15:04:05Z"
+		if v4 {
+			layout = "20060102T150405Z"
+		}
+		w.line("REV", "", c.UpdatedAt.UTC().Format(layout))
+	}
+	w.line("END", "", "VCARD")
+	return nil
+}
+
+// Close flushes the output
+func (w *Writer) Close() error {
+	return w.w.Flush()
+}
+
+// line writes a property, folded at maxLine octets without splitting a
+// UTF-8 sequence
+func (w *Writer) line(name, params, value string) {
+	line := name + params + ":" + value
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.w.WriteString(line[:cut])
+		w.w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLine - 1 // the leading space counts
+	}
+	w.w.WriteString(line)
+	w.w.WriteString("\r\n")
+}
+
+// date writes a yearly date the way the version expects: 1985-04-12 or
+// --04-12 in 3.0, 19850412 or --0412 in 4.0
+func (w *Writer) date(d models.ImportantDate) string {
+	sep := "-"
+	if w.version == "4.0" {
+		sep = ""
+	}
+	monthDay := fmt.Sprintf("%02d%s%02d", int(d.Month), sep, d.Day)
+	if d.Year == 0 {
+		return "--" + monthDay
+	}
+	return fmt.Sprintf("%04d%s%s", d.Year, sep, monthDay)
+}
+
+// fullName returns the name a card is shown under
+func fullName(c *models.Contact) string {
+	switch name := strings.TrimSpace(c.FirstName + " " + c.LastName); {
+	case name != "":
+		return name
+	case c.Organization != "":
+		return c.Organization
+	default:
+		return c.Email
+	}
+}
+
+// adr writes an address as the street component of ADR. The address book
+// keeps addresses as single lines, so they are not split into components.
+func adr(address string) string {
+	if address == "" {
+		return ""
+	}
+	return ";;" + escape(address) + ";;;;"
+}
+
+func adrAll(addresses []string) []string {
+	out := make([]string, len(addresses))
+	for i, a := range addresses {
+		out[i] = adr(a)
+	}
+	return out
+}
+
+func escapeAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = escape(v)
+	}
+	return out
+}
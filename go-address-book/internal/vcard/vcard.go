@@ -0,0 +1,196 @@
+// Package vcard reads and writes contacts as vCards, the format phones and
+// mail clients exchange contacts in.
+//
+// Encode writes vCard 3.0 (RFC 2426) or 4.0 (RFC 6350). Decode reads 3.0,
+// 4.0 and, leniently, the older 2.1 that some phones still export, along
+// with the quirks real files have: folded lines, quoted-printable values
+// with soft line breaks, CHARSET parameters, UTF-16 files and byte-order
+// marks, and Latin-1 text in files that claim to be UTF-8.
+package vcard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Versions lists the vCard versions Encode writes
+var Versions = []string{"3.0", "4.0"}
+
+// DefaultVersion is the version written unless another is chosen. Version
+// 3.0 is the one every client reads.
+const DefaultVersion = "3.0"
+
+// property is one content line, such as "item1.TEL;TYPE=cell:555-0100"
+type property struct {
+	line   int    // line number where the property starts
+	group  string // "item1", or ""
+	name   string // upper case
+	params map[string][]string
+	value  string // still escaped
+}
+
+// param returns the first value of a parameter, or ""
+func (p *property) param(name string) string {
+	if values := p.params[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// hasType reports whether the TYPE parameter includes t, ignoring case
+func (p *property) hasType(t string) bool {
+	for _, value := range p.params["TYPE"] {
+		if strings.EqualFold(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// bareParams are the parameters vCard 2.1 writes without a name, as in
+// "TEL;CELL;QUOTED-PRINTABLE:", and the names they belong to
+var bareParams = map[string]string{
+	"QUOTED-PRINTABLE": "ENCODING",
+	"BASE64":           "ENCODING",
+	"8BIT":             "ENCODING",
+	"7BIT":             "ENCODING",
+}
+
+// parseLine splits an unfolded content line into a property
+func parseLine(line string, number int) (*property, error) {
+	colon := -1
+	quoted := false
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("%q is not a property: missing ':'", abbreviate(line))
+	}
+
+	parts := splitQuoted(line[:colon], ';')
+	p := &property{line: number, name: strings.ToUpper(strings.TrimSpace(parts[0])), value: line[colon+1:]}
+	if group, name, ok := strings.Cut(p.name, "."); ok {
+		p.group, p.name = group, name
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("%q is not a property: missing name", abbreviate(line))
+	}
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !ok {
+			// A bare 2.1 parameter: an encoding, or else a type
+			value = name
+			if name, ok = bareParams[value]; !ok {
+				name = "TYPE"
+			}
+		}
+		if p.params == nil {
+			p.params = make(map[string][]string)
+		}
+		for _, v := range splitQuoted(value, ',') {
+			p.params[name] = append(p.params[name], unquoteParam(v))
+		}
+	}
+	return p, nil
+}
+
+// splitQuoted splits s at sep, except inside double quotes
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquoteParam removes the quotes around a parameter value and decodes the
+// ^n, ^' and ^^ escapes of RFC 6868
+func unquoteParam(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		v = v[1 : len(v)-1]
+	}
+	return strings.NewReplacer("^n", "\n", "^N", "\n", "^'", `"`, "^^", "^").Replace(v)
+}
+
+// quoteParam writes a parameter value, quoted when it contains characters
+// that would end it early
+func quoteParam(v, version string) string {
+	if version == "4.0" {
+		v = strings.NewReplacer("^", "^^", "\n", "^n", `"`, "^'").Replace(v)
+	} else {
+		v = strings.NewReplacer("\n", " ", `"`, "'").Replace(v)
+	}
+	if strings.ContainsAny(v, ",;: ") {
+		return `"` + v + `"`
+	}
+	return v
+}
+
+// splitValue splits an escaped value at each sep that is not escaped with a
+// backslash, as the components of N and ADR and the items of CATEGORIES are
+func splitValue(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// unescape decodes the backslash escapes of a text value. Escapes the
+// standards don't define, such as "\:", keep the escaped character.
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) {
+			i++
+			c = value[i]
+			if c == 'n' || c == 'N' {
+				c = '\n'
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// escape encodes a text value, escaping the characters that separate
+// components and list items
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`, ",", `\,`, ";", `\;`).Replace(value)
+}
+
+// abbreviate shortens a line for an error message
+func abbreviate(line string) string {
+	if runes := []rune(line); len(runes) > 40 {
+		return string(runes[:37]) + "..."
+	}
+	return line
+}
@@ -0,0 +1,311 @@
+package vcard
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/rushi/address-book-cli/internal/models"
+)
+
+func sampleContact() *models.Contact {
+	c := models.NewContact("Zoë", "O'Brien; Jr.", "zoe@example.com", "+1 555 0100", "1 Main St, Dallas, TX 75001")
+	c.OtherEmails = []string{"zoe@home.example", "z@old.example"}
+	c.OtherPhones = []string{"555-0199"}
+	c.OtherAddresses = []string{"PO Box 7\nAustin"}
+	c.Organization = "Acme, Inc."
+	c.Department = "R&D"
+	c.Title = "Engineer"
+	c.Tags = []string{"vendor", "vip"}
+	c.Dates = []models.ImportantDate{
+		{Label: models.LabelBirthday, Month: time.April, Day: 12},
+		{Label: models.LabelAnniversary, Year: 2010, Month: time.June, Day: 5},
+		{Label: "first met", Year: 2001, Month: time.January, Day: 31},
+	}
+	c.Interactions = []models.Interaction{
+		{At: time.Now(), Kind: models.InteractionNote, Summary: "Prefers email.\nUsually replies " + strings.Repeat("quickly ", 12) + "."},
+		{At: time.Now(), Kind: models.InteractionCall, Summary: "Called about renewal"},
+	}
+	c.Custom = map[string]string{"Account Manager": "Dana 東京 " + strings.Repeat("é", 40)}
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, version := range Versions {
+		want := sampleContact()
+		var buf bytes.Buffer
+		if err := Encode(&buf, []*models.Contact{want}, version); err != nil {
+			t.Fatalf("%s: Encode: %v", version, err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+			if len(line) > maxLine {
+				t.Errorf("%s: line of %d octets: %q", version, len(line), line)
+			}
+		}
+
+		result, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", version, err)
+		}
+		if len(result.Warnings) > 0 || len(result.Ignored) > 0 {
+			t.Errorf("%s: warnings %v, ignored %v", version, result.Warnings, result.Ignored)
+		}
+		if len(result.Contacts) != 1 {
+			t.Fatalf("%s: decoded %d contacts", version, len(result.Contacts))
+		}
+		got := result.Contacts[0]
+
+		if got.ID != want.ID || got.FirstName != want.FirstName || got.LastName != want.LastName ||
+			got.Email != want.Email || got.Phone != want.Phone || got.Address != want.Address ||
+			got.Organization != want.Organization || got.Department != want.Department || got.Title != want.Title {
+			t.Errorf("%s: got %+v, want %+v", version, got, want)
+		}
+		if !slices.Equal(got.OtherEmails, want.OtherEmails) || !slices.Equal(got.OtherPhones, want.OtherPhones) {
+			t.Errorf("%s: other emails %q, phones %q", version, got.OtherEmails, got.OtherPhones)
+		}
+		if !slices.Equal(got.OtherAddresses, []string{"PO Box 7, Austin"}) {
+			t.Errorf("%s: other addresses %q", version, got.OtherAddresses)
+		}
+		if !slices.Equal(got.Tags, want.Tags) || !slices.Equal(got.Dates, want.Dates) {
+			t.Errorf("%s: tags %q, dates %v", version, got.Tags, got.Dates)
+		}
+		if len(got.Interactions) != 1 || got.Interactions[0].Summary != want.Interactions[0].Summary {
+			t.Errorf("%s: interactions %+v", version, got.Interactions)
+		}
+		if got.Custom["Account Manager"] != want.Custom["Account Manager"] {
+			t.Errorf("%s: custom %q", version, got.Custom)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	c := models.NewContact("Ada", "Lovelace", "ada@example.com", "555-0100", "")
+	c.OtherPhones = []string{"555-0101"}
+	c.Dates = []models.ImportantDate{{Label: models.LabelBirthday, Year: 1815, Month: time.December, Day: 10}}
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{"3.0", []string{"VERSION:3.0", "EMAIL;TYPE=INTERNET,PREF:ada@example.com", "TEL;TYPE=VOICE,PREF:555-0100", "TEL;TYPE=VOICE:555-0101", "BDAY:1815-12-10"}},
+		{"4.0", []string{"VERSION:4.0", "EMAIL;PREF=1:ada@example.com", "TEL;PREF=1:555-0100", "TEL:555-0101", "BDAY:18151210"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Encode(&buf, []*models.Contact{c}, tt.version); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(buf.String(), "\r\n")
+		for _, want := range append(tt.want, "BEGIN:VCARD", "FN:Ada Lovelace", "N:Lovelace;Ada;;;", "END:VCARD") {
+			if !slices.Contains(lines, want) {
+				t.Errorf("%s: no line %q in\n%s", tt.version, want, buf.String())
+			}
+		}
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, "2.1"); err == nil {
+		t.Error("NewWriter accepted version 2.1")
+	}
+}
+
+func TestFoldKeepsCharacters(t *testing.T) {
+	c := models.NewContact(strings.Repeat("日本", 40), "", "", "", "")
+	var buf bytes.Buffer
+	if err := Encode(&buf, []*models.Contact{c}, "4.0"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if !strings.HasPrefix(line, " ") && !strings.Contains(line, ":") && line != "" {
+			t.Errorf("unexpected line %q", line)
+		}
+		if strings.ContainsRune(line, '�') || len(line) > maxLine {
+			t.Errorf("badly folded line %q", line)
+		}
+	}
+	result, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Contacts[0].FirstName; got != c.FirstName {
+		t.Errorf("name = %q", got)
+	}
+}
+
+func TestDecodeQuirks(t *testing.T) {
+	latin1 := func(s string) string {
+		encoded, _ := charmap.ISO8859_1.NewEncoder().String(s)
+		return encoded
+	}
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, c *models.Contact)
+	}{
+		{
+			name: "2.1 quoted-printable with soft line breaks",
+			input: "BEGIN:VCARD\r\nVERSION:2.1\r\nN;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:M=C3=BCller;J=\r\n=C3=BCrgen\r\n" +
+				"TEL;CELL:555-0100\r\nTEL;WORK;PREF:555-0200\r\nEND:VCARD\r\n",
+			check: func(t *testing.T, c *models.Contact) {
+				if c.FirstName != "Jürgen" || c.LastName != "Müller" {
+					t.Errorf("name = %q %q", c.FirstName, c.LastName)
+				}
+				if c.Phone != "555-0200" || !slices.Equal(c.OtherPhones, []string{"555-0100"}) {
+					t.Errorf("phones = %q, %q", c.Phone, c.OtherPhones)
+				}
+			},
+		},
+		{
+			name:  "charset parameter",
+			input: "BEGIN:VCARD\nVERSION:2.1\nN;CHARSET=ISO-8859-1:" + latin1("Pérez;José") + "\nEND:VCARD\n",
+			check: func(t *testing.T, c *models.Contact) {
+				if c.FirstName != "José" || c.LastName != "Pérez" {
+					t.Errorf("name = %q %q", c.FirstName, c.LastName)
+				}
+			},
+		},
+		{
+			name:  "Latin-1 without a charset",
+			input: "BEGIN:VCARD\nVERSION:3.0\nFN:" + latin1("José Díaz") + "\nN:;;;;\nEND:VCARD\n",
+			check: func(t *testing.T, c *models.Contact) {
+				if c.FirstName+" "+c.LastName != "José Díaz" {
+					t.Errorf("name = %q %q", c.FirstName, c.LastName)
+				}
+			},
+		},
+		{
+			name: "folding, escapes and 4.0 preferences",
+			input: "BEGIN:VCARD\nVERSION:4.0\nFN:Ann Lee\nN:Lee;Ann;Marie,Jo;;\nEMAIL;PREF=2:b@example.com\nEMAIL:c@example.com\n" +
+				"EMAIL;PREF=1:a@exam\n\tple.com\nTEL;VALUE=uri:tel:+1-555-0100\nADR;TYPE=home:;Apt 2;1 Main St;Dallas;TX;75001;USA\n" +
+				"NOTE:one\\, two\\;\\nthree\\:\nCATEGORIES:Friends,Work\nEND:VCARD\n",
+			check: func(t *testing.T, c *models.Contact) {
+				if c.FirstName != "Ann Marie Jo" || c.LastName != "Lee" {
+					t.Errorf("name = %q %q", c.FirstName, c.LastName)
+				}
+				if c.Email != "a@example.com" || !slices.Equal(c.OtherEmails, []string{"b@example.com", "c@example.com"}) {
+					t.Errorf("emails = %q, %q", c.Email, c.OtherEmails)
+				}
+				if c.Phone != "+1-555-0100" || c.Address != "Apt 2, 1 Main St, Dallas, TX 75001, USA" {
+					t.Errorf("phone %q, address %q", c.Phone, c.Address)
+				}
+				if len(c.Interactions) != 1 || c.Interactions[0].Summary != "one, two;\nthree:" {
+					t.Errorf("interactions = %+v", c.Interactions)
+				}
+				if !slices.Equal(c.Tags, []string{"friends", "work"}) {
+					t.Errorf("tags = %q", c.Tags)
+				}
+			},
+		},
+		{
+			name: "Apple dates and labels",
+			input: "BEGIN:VCARD\nVERSION:3.0\nN:Doe;Jan;;;\nBDAY;X-APPLE-OMIT-YEAR=1604:1604-03-09\n" +
+				"item1.X-ABDATE:2015-07-04\nitem1.X-ABLabel:_$!<Anniversary>!$_\n" +
+				"item2.X-ABDATE;type=pref:--1225\nitem2.X-ABLabel:Name day\nEND:VCARD\n",
+			check: func(t *testing.T, c *models.Contact) {
+				want := []models.ImportantDate{
+					{Label: models.LabelBirthday, Month: time.March, Day: 9},
+					{Label: models.LabelAnniversary, Year: 2015, Month: time.July, Day: 4},
+					{Label: "Name day", Month: time.December, Day: 25},
+				}
+				if !slices.Equal(c.Dates, want) {
+					t.Errorf("dates = %v", c.Dates)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		result, err := Decode(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(result.Warnings) > 0 {
+			t.Errorf("%s: warnings %v", tt.name, result.Warnings)
+		}
+		if len(result.Contacts) != 1 {
+			t.Errorf("%s: %d contacts", tt.name, len(result.Contacts))
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) { tt.check(t, result.Contacts[0]) })
+	}
+}
+
+func TestDecodeUTF16(t *testing.T) {
+	text := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Ng;Thảo;;;\r\nEND:VCARD\r\n"
+	for _, endian := range []unicode.Endianness{unicode.LittleEndian, unicode.BigEndian} {
+		data, err := unicode.UTF16(endian, unicode.UseBOM).NewEncoder().String(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Decode(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := result.Contacts[0]; c.FirstName != "Thảo" || c.LastName != "Ng" {
+			t.Errorf("name = %q %q", c.FirstName, c.LastName)
+		}
+	}
+	result, err := Decode(strings.NewReader("\uFEFF" + text))
+	if err != nil || result.Contacts[0].LastName != "Ng" {
+		t.Errorf("with UTF-8 BOM: %v", err)
+	}
+}
+
+func TestDecodeUID(t *testing.T) {
+	input := "BEGIN:VCARD\nVERSION:3.0\nFN:New\nUID:01HF7YAT00ABCDEFGHJKMNPQRS\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Legacy\nUID:20240101120000-0a1b2c3d4e5f6071\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Foreign\nUID:urn:uuid:4fbe8971-0bc3-424c-9c26-36c3e1eff6b1\nEND:VCARD\n"
+	result, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Contacts) != 3 {
+		t.Fatalf("%d contacts", len(result.Contacts))
+	}
+	// IDs from this address book, old and new, are kept so re-imports match
+	if id := result.Contacts[0].ID; id != "01HF7YAT00ABCDEFGHJKMNPQRS" {
+		t.Errorf("ID = %q", id)
+	}
+	if id := result.Contacts[1].ID; id != "20240101120000-0a1b2c3d4e5f6071" {
+		t.Errorf("legacy ID = %q", id)
+	}
+	if id := result.Contacts[2].ID; !models.IsID(id) {
+		t.Errorf("foreign UID kept as ID %q", id)
+	}
+}
+
+func TestDecodeProblems(t *testing.T) {
+	input := "PRODID:stray\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nN:One;Card;;;\nBDAY:someday\nPHOTO;ENCODING=b:AAAA\nno colon here\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Two Cards\nURL:https://example.com\nEND:VCARD\n" +
+		"BEGIN:VCARD\nVERSION:3.0\nTEL:555\nEND:VCARD\n" +
+		"BEGIN:VCARD\nFN:Three\n"
+	result, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range result.Contacts {
+		names = append(names, c.FirstName+"/"+c.LastName)
+	}
+	if want := []string{"Card/One", "Two/Cards", "Three/"}; !slices.Equal(names, want) {
+		t.Errorf("contacts = %q, want %q", names, want)
+	}
+	var lines []int
+	for _, w := range result.Warnings {
+		lines = append(lines, w.Line)
+	}
+	// stray property, missing END, bad BDAY, bad line, nameless card, missing END
+	if want := []int{1, 2, 5, 7, 13, 17}; !slices.Equal(lines, want) {
+		t.Errorf("warnings on lines %v, want %v: %v", lines, want, result.Warnings)
+	}
+	if want := []string{"PHOTO", "URL"}; !slices.Equal(result.Ignored, want) {
+		t.Errorf("ignored = %q, want %q", result.Ignored, want)
+	}
+
+	if _, err := Decode(strings.NewReader("name,email\n")); err == nil {
+		t.Error("Decode accepted a file without cards")
+	}
+}